@@ -0,0 +1,57 @@
+package automapper
+
+import "reflect"
+
+// Slabs start small and double up to maxSlab elements, so mappings with
+// few nested values do not reserve much memory.
+const (
+	minSlab = 8
+	maxSlab = 1024
+)
+
+// WithArena is an experimental mode for MapSlice over large datasets with
+// short-lived destinations, such as ETL batches. Nested destination structs
+// reached through pointers are carved out of slabs shared by the call
+// instead of being allocated one by one, cutting the number of objects the
+// garbage collector tracks. A slab is freed only once none of its values is
+// referenced, so keeping a single destination alive retains its whole slab.
+func WithArena() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.arena = true
+	}
+}
+
+// slabArena hands out values of each type from preallocated slabs.
+type slabArena struct {
+	slabs map[reflect.Type]*slab
+}
+
+// slab is a backing array of values of one type and the next free index.
+type slab struct {
+	values reflect.Value
+	next   int
+}
+
+// newValue returns a pointer to a new zero value of type t, taken from the
+// call's arena when WithArena is set.
+func (m *Mapper) newValue(ctx *MappingContext, t reflect.Type) reflect.Value {
+	if !m.config.arena || ctx == nil {
+		return reflect.New(t)
+	}
+	if ctx.arena == nil {
+		ctx.arena = &slabArena{slabs: make(map[reflect.Type]*slab)}
+	}
+
+	s := ctx.arena.slabs[t]
+	if s == nil || s.next == s.values.Len() {
+		size := minSlab
+		if s != nil {
+			size = min(2*s.values.Len(), maxSlab)
+		}
+		s = &slab{values: reflect.MakeSlice(reflect.SliceOf(t), size, size)}
+		ctx.arena.slabs[t] = s
+	}
+	v := s.values.Index(s.next).Addr()
+	s.next++
+	return v
+}
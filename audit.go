@@ -0,0 +1,108 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// AuditFields is the conventional set of audit timestamps. Entities embed it
+// (or declare the same fields, as gorm.Model does) and DTOs declare
+// equivalents of any supported shape: time.Time, *time.Time, which stays nil
+// for zero or soft-delete-free timestamps, or string, formatted with the
+// layout set by WithTimeFormat.
+type AuditFields struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+}
+
+// WithTimeFormat sets the layout used when mapping time.Time to and from
+// strings. The default is time.RFC3339.
+func WithTimeFormat(layout string) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.timeFormat = layout
+	}
+}
+
+// timeType is the reflect.Type of time.Time.
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeLayout returns the configured time layout.
+func (c *MapperConfiguration) timeLayout() string {
+	if c.timeFormat == "" {
+		return time.RFC3339
+	}
+	return c.timeFormat
+}
+
+var (
+	auditFieldsType = reflect.TypeOf(AuditFields{})
+	auditTimeType   = reflect.TypeOf((*time.Time)(nil))
+)
+
+// markAuditTimes flags the *time.Time members of tm named like the fields
+// of AuditFields, which stay nil for zero source timestamps.
+func (tm *TypeMap) markAuditTimes() {
+	for _, mm := range tm.memberMaps {
+		if _, audit := auditFieldsType.FieldByName(mm.destField); !audit {
+			continue
+		}
+		if tm.destType.FieldByIndex(mm.destFieldIdx).Type == auditTimeType {
+			mm.zeroTimeNil = true
+		}
+	}
+}
+
+// isZeroTime reports whether v is a zero time.Time, which audit timestamp
+// members map to a nil pointer rather than a pointer to the zero time.
+func isZeroTime(v reflect.Value) bool {
+	return v.Type() == timeType && v.Interface().(time.Time).IsZero()
+}
+
+// assignTime maps between times, including nullable times such as
+// gorm.DeletedAt, and strings using the configured layout, and reports
+// whether it did. Zero and invalid times map to the empty string and the
+// empty string maps to the zero time.
+func (m *Mapper) assignTime(srcVal, destVal reflect.Value) (bool, error) {
+	srcType, destType := srcVal.Type(), destVal.Type()
+
+	switch {
+	case destType.Kind() == reflect.String:
+		if isNullableType(srcType) && srcVal.Field(0).Type() == timeType {
+			if !srcVal.Field(1).Bool() {
+				destVal.SetString("")
+				return true, nil
+			}
+			srcVal = srcVal.Field(0)
+		} else if srcType != timeType {
+			return false, nil
+		}
+		t := srcVal.Interface().(time.Time)
+		if t.IsZero() {
+			destVal.SetString("")
+		} else {
+			destVal.SetString(t.Format(m.config.timeLayout()))
+		}
+		return true, nil
+
+	case destType == timeType && srcType.Kind() == reflect.String:
+		if srcVal.String() == "" {
+			destVal.Set(reflect.Zero(timeType))
+			return true, nil
+		}
+		t, err := time.Parse(m.config.timeLayout(), srcVal.String())
+		if err != nil {
+			return true, &MappingError{
+				Message:    fmt.Sprintf("cannot parse %q as time", srcVal.String()),
+				Code:       CodeConversion,
+				SrcType:    srcType,
+				DestType:   destType,
+				InnerError: err,
+			}
+		}
+		destVal.Set(reflect.ValueOf(t))
+		return true, nil
+	}
+	return false, nil
+}
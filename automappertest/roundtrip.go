@@ -0,0 +1,123 @@
+// Package automappertest provides test helpers for automapper
+// configurations, such as round-trip checks of forward and reverse maps:
+//
+//	func TestUserRoundTrip(t *testing.T) {
+//	    automappertest.RoundTrip[User, UserDTO](t, mapper, func() User {
+//	        return User{ID: rand.Int(), Name: randomName()}
+//	    })
+//	}
+package automappertest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	automapper "github.com/csmart-libs/go-automapper"
+)
+
+// samples is the number of generated values RoundTrip checks.
+const samples = 100
+
+// RoundTrip maps values produced by gen from TA to TB and back to TA using
+// the registered forward and reverse maps, and fails t if a member comes
+// back different. Every member of TA the forward map reads must survive the
+// round trip, which catches configuration changed on one side only, such
+// as a member renamed forward but not mapped back. Members ignored on
+// either leg are not compared. Members are compared whole with
+// reflect.DeepEqual.
+func RoundTrip[TA, TB any](t testing.TB, mapper *automapper.Mapper, gen func() TA) {
+	t.Helper()
+
+	members, err := roundTripMembers[TA, TB](mapper)
+	if err != nil {
+		t.Fatalf("round trip: %v", err)
+		return
+	}
+
+	for i := 0; i < samples; i++ {
+		original := gen()
+		b, err := automapper.Map[TB](mapper, original)
+		if err != nil {
+			t.Fatalf("round trip: mapping %T to %T: %v", original, b, err)
+			return
+		}
+		back, err := automapper.Map[TA](mapper, b)
+		if err != nil {
+			t.Fatalf("round trip: mapping %T back to %T: %v", b, back, err)
+			return
+		}
+
+		if diffs := compareMembers(reflect.ValueOf(original), reflect.ValueOf(back), members); len(diffs) > 0 {
+			t.Errorf("round trip of %+v through %T changed:\n%s", original, b, strings.Join(diffs, "\n"))
+			return
+		}
+	}
+}
+
+// roundTripMembers returns the members of TA that the forward map reads,
+// and those the reverse map sets from a member the forward map sets or
+// through a resolver, leaving out members ignored on either leg.
+func roundTripMembers[TA, TB any](mapper *automapper.Mapper) ([]string, error) {
+	forward, err := automapper.PlanOf[TA, TB](mapper)
+	if err != nil {
+		return nil, err
+	}
+	reverse, err := automapper.PlanOf[TB, TA](mapper)
+	if err != nil {
+		return nil, err
+	}
+
+	ignored := make(map[string]bool, len(forward.Ignored)+len(reverse.Ignored))
+	for _, name := range append(forward.Ignored, reverse.Ignored...) {
+		ignored[name] = true
+	}
+
+	var members []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !ignored[name] && !seen[name] {
+			seen[name] = true
+			members = append(members, name)
+		}
+	}
+
+	set := make(map[string]bool, len(forward.Instructions))
+	for _, ins := range forward.Instructions {
+		set[ins.Member] = true
+		if source, _, _ := strings.Cut(ins.Source, "."); source != "" {
+			add(source)
+		}
+	}
+	for _, ins := range reverse.Instructions {
+		source, _, _ := strings.Cut(ins.Source, ".")
+		if ins.Source == "" || set[source] {
+			add(ins.Member)
+		}
+	}
+	return members, nil
+}
+
+// compareMembers describes the named members that differ between want and
+// got, which may be pointers.
+func compareMembers(want, got reflect.Value, members []string) []string {
+	for want.Kind() == reflect.Ptr && !want.IsNil() && !got.IsNil() {
+		want, got = want.Elem(), got.Elem()
+	}
+	if want.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(want.Interface(), got.Interface()) {
+			return []string{fmt.Sprintf("\tgot %#v, want %#v", got.Interface(), want.Interface())}
+		}
+		return nil
+	}
+
+	var diffs []string
+	for _, name := range members {
+		w, g := want.FieldByName(name), got.FieldByName(name)
+		if !reflect.DeepEqual(w.Interface(), g.Interface()) {
+			diffs = append(diffs, fmt.Sprintf("\t%s: got %#v, want %#v", name, g.Interface(), w.Interface()))
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,96 @@
+package automappertest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	automapper "github.com/csmart-libs/go-automapper"
+)
+
+type user struct {
+	ID       int
+	Name     string
+	Password string
+}
+
+type userDTO struct {
+	ID   int
+	Name string
+}
+
+type profileDTO struct {
+	ID       int
+	FullName string
+}
+
+// recorder captures failures reported by RoundTrip.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func TestRoundTrip(t *testing.T) {
+	n := 0
+	gen := func() user {
+		n++
+		return user{ID: n, Name: fmt.Sprintf("user-%d", n), Password: "secret"}
+	}
+
+	// Password has no counterpart in userDTO and is not compared
+	mapper := automapper.New()
+	automapper.CreateMap[user, userDTO](mapper).ReverseMap()
+	RoundTrip[user, userDTO](t, mapper, gen)
+
+	// A member transformed on one side only is caught
+	asymmetric := automapper.New()
+	automapper.CreateMap[user, userDTO](asymmetric).
+		ForMemberByName("Name", automapper.MapFromFunc(func(src any, _ any) (any, error) {
+			return strings.ToUpper(src.(user).Name), nil
+		})).
+		ReverseMap()
+	rec := &recorder{TB: t}
+	RoundTrip[user, userDTO](rec, asymmetric, gen)
+	if len(rec.failures) != 1 || !strings.Contains(rec.failures[0], "Name:") {
+		t.Errorf("expected a Name failure, got %q", rec.failures)
+	}
+
+	// A member renamed forward but not mapped back is caught
+	renamed := automapper.New()
+	automapper.CreateMap[user, profileDTO](renamed).
+		ForMemberByName("FullName", automapper.MapFrom("Name"))
+	automapper.CreateMap[profileDTO, user](renamed)
+	rec = &recorder{TB: t}
+	RoundTrip[user, profileDTO](rec, renamed, gen)
+	if len(rec.failures) != 1 || !strings.Contains(rec.failures[0], "Name:") {
+		t.Errorf("expected a Name failure, got %q", rec.failures)
+	}
+
+	// Members ignored on the reverse leg are not compared
+	automapper.CreateMap[profileDTO, user](renamed).
+		ForMemberByName("Name", automapper.Ignore())
+	RoundTrip[user, profileDTO](t, renamed, gen)
+
+	// Pointer values are compared through the pointer
+	RoundTrip[*user, userDTO](t, mapper, func() *user {
+		u := gen()
+		return &u
+	})
+
+	// Both maps must be registered
+	rec = &recorder{TB: t}
+	RoundTrip[user, userDTO](rec, automapper.New(), gen)
+	if len(rec.failures) != 1 {
+		t.Errorf("expected a failure for missing maps, got %q", rec.failures)
+	}
+}
@@ -0,0 +1,100 @@
+package automapper
+
+import "reflect"
+
+// WithBackReference configures member as a back-reference to the parent
+// object, as in tree nodes with a Parent pointer. The member is not mapped
+// from the source, which stops the mapping from walking back up the tree;
+// instead, whenever a destination struct is mapped, its children of this
+// destination type are wired to point at it
+// (dest.Children[i].Parent = dest). Map into a pointer destination
+// (Map[*Node]) so the root's children reference the returned value.
+func (b *TypeMapBuilder[TSrc, TDest]) WithBackReference(member string) *TypeMapBuilder[TSrc, TDest] {
+	b.update(func(tm *TypeMap) {
+		tm.backRef = member
+		b.configureMember(tm, member, []MemberOption{Ignore()})
+	})
+	b.mapper.config.hasBackRefs.Store(true)
+	return b
+}
+
+// backRefIndex maps destination types to their back-reference member, as
+// configured at one configuration generation.
+type backRefIndex struct {
+	generation uint64
+	members    map[reflect.Type]string
+}
+
+// backReferenceFor returns the back-reference member configured for maps
+// producing destType, if any. The index is rebuilt once per configuration
+// generation rather than scanning the type maps for every child.
+func (m *Mapper) backReferenceFor(destType reflect.Type) string {
+	gen := m.config.generation.Load()
+	index := m.config.backRefs.Load()
+	if index == nil || index.generation != gen {
+		index = m.config.indexBackReferences(gen)
+	}
+	return index.members[destType]
+}
+
+// indexBackReferences builds and caches the back-reference index for gen.
+func (c *MapperConfiguration) indexBackReferences(gen uint64) *backRefIndex {
+	c.mu.RLock()
+	index := &backRefIndex{generation: gen, members: make(map[reflect.Type]string)}
+	for key, tm := range c.typeMaps {
+		if tm.backRef != "" {
+			index.members[key.destType] = tm.backRef
+		}
+	}
+	c.mu.RUnlock()
+
+	c.backRefs.Store(index)
+	return index
+}
+
+// wireBackReferences points the back-reference members of destVal's
+// direct children at destVal.
+func (m *Mapper) wireBackReferences(destVal reflect.Value) {
+	if !m.config.hasBackRefs.Load() || !destVal.CanAddr() {
+		return
+	}
+	parent := destVal.Addr()
+
+	for i := 0; i < destVal.NumField(); i++ {
+		if !destVal.Type().Field(i).IsExported() {
+			continue
+		}
+		field := destVal.Field(i)
+		switch field.Kind() {
+		case reflect.Struct, reflect.Ptr:
+			m.wireChild(field, parent)
+		case reflect.Slice, reflect.Array:
+			for j := 0; j < field.Len(); j++ {
+				m.wireChild(field.Index(j), parent)
+			}
+		}
+	}
+}
+
+// wireChild sets child's back-reference member to parent if the child's
+// type has one of parent's type.
+func (m *Mapper) wireChild(child, parent reflect.Value) {
+	if child.Kind() == reflect.Ptr {
+		if child.IsNil() {
+			return
+		}
+		child = child.Elem()
+	}
+	if child.Kind() != reflect.Struct {
+		return
+	}
+
+	name := m.backReferenceFor(child.Type())
+	if name == "" {
+		return
+	}
+	ref := child.FieldByName(name)
+	if ref.IsValid() && ref.CanSet() && ref.Type() == parent.Type() {
+		ref.Set(parent)
+	}
+}
@@ -0,0 +1,170 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// IncludeBase makes a derived map inherit the member options configured on
+// the map between base types, typically structs embedded in the derived
+// source and destination:
+//
+//	automapper.CreateMap[Entity, EntityDTO](mapper).
+//	    ForMemberByName("ID", automapper.MapFrom("Key"))
+//	automapper.IncludeBase[Entity, EntityDTO](automapper.CreateMap[User, UserDTO](mapper))
+//
+// Inherited resolvers and conditions receive the embedded base source, and
+// the embedded base destination, as they would when mapping the base pair.
+// Options configured on the derived map afterwards take precedence; use
+// ResetMember to drop an inherited member entirely. The base map's
+// configuration is copied when IncludeBase is called, so the base map must
+// be registered first; otherwise nothing is inherited, the call is
+// reported by ValidateConfiguration and Err returns an error.
+func IncludeBase[TBaseSrc, TBaseDest, TSrc, TDest any](b *TypeMapBuilder[TSrc, TDest]) *TypeMapBuilder[TSrc, TDest] {
+	baseKey := typeMapKey{
+		srcType:  derefType(reflect.TypeOf((*TBaseSrc)(nil)).Elem()),
+		destType: derefType(reflect.TypeOf((*TBaseDest)(nil)).Elem()),
+	}
+
+	cfg := b.mapper.config
+	cfg.mu.Lock()
+	base, ok := cfg.typeMaps[baseKey]
+	if !ok {
+		cfg.violations = append(cfg.violations, ValidationIssue{
+			Severity: SeverityError,
+			SrcType:  b.typeMap.srcType,
+			DestType: b.typeMap.destType,
+			Message:  fmt.Sprintf("IncludeBase names base map %v -> %v, which is not registered; nothing is inherited", baseKey.srcType, baseKey.destType),
+		})
+		if b.err == nil {
+			b.err = &MappingError{
+				Message:  "base type map is not registered",
+				Code:     CodeNoTypeMap,
+				SrcType:  baseKey.srcType,
+				DestType: baseKey.destType,
+			}
+		}
+	}
+	cfg.mu.Unlock()
+	if !ok {
+		return b
+	}
+
+	b.update(func(tm *TypeMap) {
+		cache := b.mapper.config.typeCache
+		srcIdx := embeddedIndex(tm.srcType, baseKey.srcType)
+		destIdx := embeddedIndex(tm.destType, baseKey.destType)
+
+		for _, baseMember := range base.memberMaps {
+			destField, ok := cache.getTypeInfo(tm.destType).member(baseMember.destField)
+			if !ok {
+				continue
+			}
+			mm := inheritMember(baseMember, srcIdx, destIdx)
+			mm.destFieldIdx = destField.index
+			if len(baseMember.srcFieldIdx) > 0 && srcIdx != nil {
+				mm.srcFieldIdx = append(append([]int(nil), srcIdx...), baseMember.srcFieldIdx...)
+			} else if srcField, ok := cache.getTypeInfo(tm.srcType).member(mm.srcField); ok {
+				mm.srcFieldIdx = srcField.index
+			} else {
+				mm.srcFieldIdx = nil
+			}
+			tm.replaceMember(mm)
+		}
+		tm.orderMemberDependencies()
+	})
+	return b
+}
+
+// embeddedIndex returns the index path of the struct embedded in t, at any
+// depth, with type base or a pointer to it, or nil if t does not embed
+// base. Embedded fields are searched breadth-first, so the shallowest one
+// wins, and compared by type since the field name of an embedded generic
+// instantiation omits its type arguments.
+func embeddedIndex(t, base reflect.Type) []int {
+	type level struct {
+		t     reflect.Type
+		index []int
+	}
+	queue := []level{{t: derefType(t)}}
+	seen := map[reflect.Type]bool{}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if next.t.Kind() != reflect.Struct || seen[next.t] {
+			continue
+		}
+		seen[next.t] = true
+
+		for i := 0; i < next.t.NumField(); i++ {
+			field := next.t.Field(i)
+			if !field.Anonymous {
+				continue
+			}
+			index := append(append([]int(nil), next.index...), i)
+			if derefType(field.Type) == base {
+				return index
+			}
+			queue = append(queue, level{t: derefType(field.Type), index: index})
+		}
+	}
+	return nil
+}
+
+// inheritMember copies a base member map, adapting its resolver and
+// condition to receive the embedded base values of the derived types.
+func inheritMember(base *MemberMap, srcIdx, destIdx []int) *MemberMap {
+	mm := *base
+	if base.resolver != nil {
+		resolver := base.resolver
+		mm.resolver = func(src, dest any) (any, error) {
+			return resolver(embeddedValue(src, srcIdx), embeddedValue(dest, destIdx))
+		}
+	}
+	if base.condition != nil {
+		condition := base.condition
+		mm.condition = func(src any) bool {
+			return condition(embeddedValue(src, srcIdx))
+		}
+	}
+	if base.ptrCond != nil {
+		ptrCond := base.ptrCond
+		mm.ptrCond = func(src reflect.Value) bool {
+			embedded, err := src.FieldByIndexErr(srcIdx)
+			if err != nil || !derefValue(embedded).IsValid() {
+				return ptrCond(src)
+			}
+			return ptrCond(derefValue(embedded))
+		}
+	}
+	return &mm
+}
+
+// embeddedValue returns the embedded struct at index within v, or v itself
+// if index is nil or the embedded pointer is nil.
+func embeddedValue(v any, index []int) any {
+	if index == nil {
+		return v
+	}
+	val := derefValue(reflect.ValueOf(v))
+	if val.Kind() != reflect.Struct {
+		return v
+	}
+	embedded, err := val.FieldByIndexErr(index)
+	if err != nil || !derefValue(embedded).IsValid() {
+		return v
+	}
+	return derefValue(embedded).Interface()
+}
+
+// replaceMember swaps in mm for the member map of the same destination
+// member, or appends it.
+func (tm *TypeMap) replaceMember(mm *MemberMap) {
+	for i, existing := range tm.memberMaps {
+		if existing.destField == mm.destField {
+			tm.memberMaps[i] = mm
+			return
+		}
+	}
+	tm.memberMaps = append(tm.memberMaps, mm)
+}
@@ -0,0 +1,142 @@
+package automapper
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// BatchResolver resolves a member for many sources at once. MapSlice calls
+// ResolveBatch once with every element of the slice, so enrichment lookups
+// run as one query instead of one per element; it must return one value per
+// source, in order. Elsewhere it is called with a single source.
+type BatchResolver interface {
+	ResolveBatch(ctx context.Context, srcs []any) ([]any, error)
+}
+
+// MapFromBatch configures a batch resolver for a destination member.
+func MapFromBatch(resolver BatchResolver) MemberOption {
+	return func(mm *MemberMap) {
+		mm.batch = resolver
+		mm.resolver = nil
+		mm.addSource("MapFromBatch", true)
+	}
+}
+
+// batchResults holds the values batch resolvers produced for the elements
+// of a MapSlice call, by destination member.
+type batchResults struct {
+	key    typeMapKey
+	values map[string][]any
+	index  int
+}
+
+// batchTypeMap returns the registered type map for a slice element pair if
+// any of its members uses a batch resolver, or nil.
+func (m *Mapper) batchTypeMap(srcType, destType reflect.Type) *TypeMap {
+	if !m.config.hasBatches.Load() {
+		return nil
+	}
+
+	m.config.mu.RLock()
+	tm, ok := m.config.typeMaps[typeMapKey{srcType: derefType(srcType), destType: destType}]
+	m.config.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	for _, mm := range tm.memberMaps {
+		if mm.batch != nil && !mm.ignore {
+			return tm
+		}
+	}
+	return nil
+}
+
+// mapSliceBatched maps a slice whose element type map has batch resolvers,
+// calling each of them once for the whole slice.
+func mapSliceBatched[TSrc, TDest any](m *Mapper, tm *TypeMap, src []TSrc) ([]TDest, error) {
+	srcs := make([]any, len(src))
+	for i, s := range src {
+		if v := derefValue(reflect.ValueOf(s)); v.IsValid() {
+			srcs[i] = v.Interface()
+		}
+	}
+
+	ctx, err := m.batchContext(tm, srcs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TDest, len(src))
+	for i, s := range src {
+		ctx.batch.index = i
+		if err := m.mapValue(ctx, reflect.ValueOf(s), reflect.ValueOf(&result[i]).Elem()); err != nil {
+			return nil, &MappingError{
+				Message:    fmt.Sprintf("error mapping element at index %d", i),
+				InnerError: err,
+			}
+		}
+	}
+	return result, nil
+}
+
+// batchContext runs the batch resolvers of tm for all srcs and returns a
+// context serving their results to the elements as they are mapped.
+func (m *Mapper) batchContext(tm *TypeMap, srcs []any) (*MappingContext, error) {
+	ctx := &MappingContext{mapper: m}
+	ctx.batch = &batchResults{
+		key:    typeMapKey{srcType: tm.srcType, destType: tm.destType},
+		values: make(map[string][]any),
+	}
+	for _, mm := range tm.memberMaps {
+		if mm.batch == nil || mm.ignore {
+			continue
+		}
+		values, err := runBatch(ctx, mm, srcs)
+		if err != nil {
+			return nil, &MappingError{
+				Message:    "batch resolver error",
+				Code:       CodeResolver,
+				SrcType:    tm.srcType,
+				DestType:   tm.destType,
+				FieldName:  mm.destField,
+				Path:       mm.destField,
+				InnerError: err,
+			}
+		}
+		ctx.batch.values[mm.destField] = values
+	}
+	return ctx, nil
+}
+
+// resolveBatched returns the value of a batch resolved member: the value
+// computed for the current MapSlice element, or a single-source batch.
+func resolveBatched(ctx *MappingContext, mm *MemberMap, src, dest any) (any, error) {
+	// Only the elements themselves use the slice's results; nested structs
+	// of the same types resolve on their own
+	if ctx != nil && ctx.batch != nil && ctx.depth == 1 &&
+		reflect.TypeOf(src) == ctx.batch.key.srcType && reflect.TypeOf(dest) == ctx.batch.key.destType {
+		if values, ok := ctx.batch.values[mm.destField]; ok {
+			return values[ctx.batch.index], nil
+		}
+	}
+
+	values, err := runBatch(ctx, mm, []any{src})
+	if err != nil {
+		return nil, err
+	}
+	return values[0], nil
+}
+
+// runBatch calls a member's batch resolver and checks it returned one value
+// per source.
+func runBatch(ctx *MappingContext, mm *MemberMap, srcs []any) ([]any, error) {
+	values, err := mm.batch.ResolveBatch(ctx.Context(), srcs)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(srcs) {
+		return nil, fmt.Errorf("batch resolver returned %d values for %d sources", len(values), len(srcs))
+	}
+	return values, nil
+}
@@ -0,0 +1,153 @@
+package automapper
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// benchmarkedLevels are the optimization levels compared by BenchmarkLevels.
+var benchmarkedLevels = []OptimizationLevel{
+	OptimizationNone,
+	OptimizationPooled,
+	OptimizationUnsafe,
+	OptimizationSpecialized,
+}
+
+// String returns the name of the optimization level.
+func (l OptimizationLevel) String() string {
+	switch l {
+	case OptimizationNone:
+		return "none"
+	case OptimizationPooled:
+		return "pooled"
+	case OptimizationUnsafe:
+		return "unsafe"
+	case OptimizationSpecialized:
+		return "specialized"
+	}
+	return "unknown"
+}
+
+// LevelBenchmark is the measured cost of mapping one type pair at one
+// optimization level.
+type LevelBenchmark struct {
+	SrcType     string
+	DestType    string
+	Level       OptimizationLevel
+	NsPerOp     int64
+	AllocsPerOp int64
+}
+
+// BenchmarkLevels maps each sample into every destination registered for
+// its type at each optimization level, for about duration per pair and
+// level, and reports ns/op and allocs/op so a level can be picked
+// empirically. Results are ordered by type pair, then level. The mapper's
+// level is switched while measuring, so run it against a mapper that is not
+// serving other mappings, e.g. from a test or a small command.
+func BenchmarkLevels(m *Mapper, duration time.Duration, samples ...any) ([]LevelBenchmark, error) {
+	var results []LevelBenchmark
+	for _, sample := range samples {
+		if isNilSource(sample) {
+			return nil, &MappingError{Message: "benchmark samples must not be nil", Code: CodeNilSource}
+		}
+		srcType := derefType(reflect.TypeOf(sample))
+
+		for _, tm := range m.sortedTypeMaps() {
+			if tm.srcType != srcType {
+				continue
+			}
+			for _, level := range benchmarkedLevels {
+				result, err := m.benchmarkLevel(level, duration, sample, tm.destType)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, result)
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.SrcType != b.SrcType {
+			return a.SrcType < b.SrcType
+		}
+		return a.DestType < b.DestType
+	})
+	return results, nil
+}
+
+// benchmarkLevel measures mapping sample into destType at level, the same
+// way Map does.
+func (m *Mapper) benchmarkLevel(level OptimizationLevel, duration time.Duration, sample any, destType reflect.Type) (LevelBenchmark, error) {
+	restore := m.setOptimizationLevel(level)
+	defer restore()
+
+	srcVal := reflect.ValueOf(sample)
+	mapOnce := func() error {
+		dest := reflect.New(destType)
+		if m.fastMap(sample, dest.UnsafePointer(), destType) {
+			return nil
+		}
+		return m.mapValue(nil, srcVal, dest.Elem())
+	}
+	if err := mapOnce(); err != nil {
+		return LevelBenchmark{}, err
+	}
+
+	// Grow the iteration count until a run takes the requested duration
+	var stats runtime.MemStats
+	n := 1
+	for {
+		runtime.ReadMemStats(&stats)
+		mallocs := stats.Mallocs
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			_ = mapOnce()
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&stats)
+
+		if elapsed >= duration || n >= 1e9 {
+			return LevelBenchmark{
+				SrcType:     derefType(srcVal.Type()).String(),
+				DestType:    destType.String(),
+				Level:       level,
+				NsPerOp:     elapsed.Nanoseconds() / int64(n),
+				AllocsPerOp: int64(stats.Mallocs-mallocs) / int64(n),
+			}, nil
+		}
+		n *= 2
+	}
+}
+
+// setOptimizationLevel switches the mapper to level, compiling optimized
+// type maps for it, and returns a function restoring the previous level.
+func (m *Mapper) setOptimizationLevel(level OptimizationLevel) (restore func()) {
+	cfg := m.config
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	prevLevel, prevUnsafe, prevMaps := cfg.optLevel, cfg.useUnsafe, cfg.optimizedMaps
+	cfg.optLevel = level
+	cfg.useUnsafe = level >= OptimizationUnsafe && !cfg.unsafeDisabled()
+	cfg.optimizedMaps = make(map[typeMapKey]*TypeMapOptimized, len(cfg.typeMaps))
+	if level > OptimizationNone {
+		for key, tm := range cfg.typeMaps {
+			cfg.optimizedMaps[key] = cfg.compileOptimized(tm, level)
+		}
+	}
+
+	return func() {
+		cfg.mu.Lock()
+		defer cfg.mu.Unlock()
+		cfg.optLevel, cfg.useUnsafe, cfg.optimizedMaps = prevLevel, prevUnsafe, prevMaps
+		// Type maps auto-created while measuring need optimizing too
+		for key, tm := range cfg.typeMaps {
+			if _, ok := prevMaps[key]; !ok && prevLevel > OptimizationNone {
+				prevMaps[key] = cfg.compileOptimized(tm, prevLevel)
+			}
+		}
+	}
+}
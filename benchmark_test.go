@@ -1,6 +1,8 @@
 package automapper
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -319,3 +321,143 @@ func BenchmarkPrimitiveStandard(b *testing.B) {
 		_, _ = Map[BenchPrimitiveDest](mapper, benchPrimitiveSource)
 	}
 }
+
+// BenchmarkAutoMapperFastPath benchmarks the zero-allocation fast path for
+// primitive-only maps when the source is passed by pointer
+func BenchmarkAutoMapperFastPath(b *testing.B) {
+	mapper := NewWithConfig(WithUnsafeOptimizations())
+	CreateMap[BenchPrimitiveSource, BenchPrimitiveDest](mapper)
+	src := &benchPrimitiveSource
+	// Warm up
+	_, _ = Map[BenchPrimitiveDest](mapper, src)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Map[BenchPrimitiveDest](mapper, src)
+	}
+}
+
+// BenchmarkTypeCacheConcurrent benchmarks concurrent type info lookups across
+// many distinct types; run with -cpu=1,2,4,8 to observe scaling
+func BenchmarkTypeCacheConcurrent(b *testing.B) {
+	cache := newTypeCache()
+	types := []reflect.Type{
+		reflect.TypeOf(BenchSource{}), reflect.TypeOf(BenchDest{}),
+		reflect.TypeOf(BenchNestedSource{}), reflect.TypeOf(BenchNestedDest{}),
+		reflect.TypeOf(BenchAddressSource{}), reflect.TypeOf(BenchAddressDest{}),
+		reflect.TypeOf(BenchItemSource{}), reflect.TypeOf(BenchItemDest{}),
+		reflect.TypeOf(BenchPrimitiveSource{}), reflect.TypeOf(BenchPrimitiveDest{}),
+	}
+	for _, t := range types {
+		cache.getTypeInfo(t)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			_ = cache.getTypeInfo(types[i%len(types)])
+			i++
+		}
+	})
+}
+
+// BenchmarkAutoMapperConcurrent benchmarks concurrent mapping of several
+// distinct type pairs sharing one mapper
+func BenchmarkAutoMapperConcurrent(b *testing.B) {
+	mapper := New()
+	CreateMap[BenchSource, BenchDest](mapper)
+	CreateMap[BenchNestedSource, BenchNestedDest](mapper)
+	CreateMap[BenchAddressSource, BenchAddressDest](mapper)
+	CreateMap[BenchItemSource, BenchItemDest](mapper)
+	CreateMap[BenchPrimitiveSource, BenchPrimitiveDest](mapper)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			switch i % 3 {
+			case 0:
+				_, _ = Map[BenchDest](mapper, benchSource)
+			case 1:
+				_, _ = Map[BenchNestedDest](mapper, benchNestedSource)
+			default:
+				_, _ = Map[BenchPrimitiveDest](mapper, benchPrimitiveSource)
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkAutoMapperConcurrentSingleMap benchmarks many goroutines mapping
+// through the same type map
+func BenchmarkAutoMapperConcurrentSingleMap(b *testing.B) {
+	mapper := New()
+	CreateMap[BenchSource, BenchDest](mapper)
+	// Warm up
+	_, _ = Map[BenchDest](mapper, benchSource)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = Map[BenchDest](mapper, benchSource)
+		}
+	})
+}
+
+// =============================================================================
+// Map Shape Benchmarks
+// =============================================================================
+
+type BenchLabelKey string
+type BenchLabelValue string
+
+type BenchLabelsSource struct {
+	Labels map[BenchLabelKey]string
+}
+
+type BenchLabelsDest struct {
+	Labels map[string]BenchLabelValue
+}
+
+func benchLabels() map[string]string {
+	labels := make(map[string]string, 100)
+	for i := 0; i < 100; i++ {
+		labels[fmt.Sprintf("key-%d", i)] = "value"
+	}
+	return labels
+}
+
+// BenchmarkMapMapNamedValues benchmarks MapMap into named string values,
+// which copies with a typed loop
+func BenchmarkMapMapNamedValues(b *testing.B) {
+	mapper := New()
+	labels := benchLabels()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = MapMap[string, string, BenchLabelValue](mapper, labels)
+	}
+}
+
+// BenchmarkNamedKeyMapMember benchmarks a map member whose key and value
+// types differ only by name
+func BenchmarkNamedKeyMapMember(b *testing.B) {
+	mapper := New()
+	CreateMap[BenchLabelsSource, BenchLabelsDest](mapper)
+	src := BenchLabelsSource{Labels: make(map[BenchLabelKey]string)}
+	for k, v := range benchLabels() {
+		src.Labels[BenchLabelKey(k)] = v
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Map[BenchLabelsDest](mapper, src)
+	}
+}
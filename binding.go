@@ -0,0 +1,36 @@
+package automapper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrBind is wrapped by errors returned from BindAndMap when the request
+// could not be bound, so handlers can answer 400 for binding failures and
+// 500 for mapping failures.
+var ErrBind = errors.New("request binding failed")
+
+// Binder binds an HTTP request into a value. It is satisfied by
+// echo.Context and *gin.Context, among others.
+type Binder interface {
+	Bind(obj any) error
+}
+
+// BindAndMap binds the request held by c into a new TReq and maps it to a
+// TCmd, so handlers go from request to command in one call:
+//
+//	cmd, err := automapper.BindAndMap[CreateUserRequest, CreateUserCommand](mapper, c)
+func BindAndMap[TReq, TCmd any](m *Mapper, c Binder) (TCmd, error) {
+	var req TReq
+	if err := c.Bind(&req); err != nil {
+		var zero TCmd
+		return zero, &MappingError{
+			Message:    "failed to bind request",
+			Code:       CodeBind,
+			DestType:   reflect.TypeOf((*TReq)(nil)).Elem(),
+			InnerError: fmt.Errorf("%w: %w", ErrBind, err),
+		}
+	}
+	return Map[TCmd](m, req)
+}
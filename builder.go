@@ -1,7 +1,9 @@
 package automapper
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"unsafe"
 )
 
@@ -9,6 +11,66 @@ import (
 type TypeMapBuilder[TSrc, TDest any] struct {
 	mapper  *Mapper
 	typeMap *TypeMap
+	err     error
+}
+
+// Seal freezes the type map: any further configuration through a builder
+// or CreateMap is ignored, reported by ValidateConfiguration and returned
+// by Err.
+func (b *TypeMapBuilder[TSrc, TDest]) Seal() *TypeMapBuilder[TSrc, TDest] {
+	cfg := b.mapper.config
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	b.current().sealed.Store(true)
+	return b
+}
+
+// current returns the registered version of the builder's type map.
+// The caller must hold config.mu.
+func (b *TypeMapBuilder[TSrc, TDest]) current() *TypeMap {
+	key := typeMapKey{srcType: b.typeMap.srcType, destType: b.typeMap.destType}
+	if registered, ok := b.mapper.config.typeMaps[key]; ok {
+		return registered
+	}
+	return b.typeMap
+}
+
+// Err returns the error of the first configuration call the builder
+// ignored: because its type map is sealed, in which case the error wraps
+// ErrMapSealed, or because IncludeBase named an unregistered base map.
+func (b *TypeMapBuilder[TSrc, TDest]) Err() error {
+	return b.err
+}
+
+// update applies a configuration change copy-on-write: the registered type
+// map is cloned, mutated and swapped into the registry under the write lock,
+// so mappings running concurrently keep using the previous immutable version.
+func (b *TypeMapBuilder[TSrc, TDest]) update(mutate func(tm *TypeMap)) {
+	cfg := b.mapper.config
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	current := b.current()
+	key := typeMapKey{srcType: current.srcType, destType: current.destType}
+	if cfg.rejectSealed(current, key) {
+		if b.err == nil {
+			b.err = sealedError(key)
+		}
+		return
+	}
+	cfg.checkLateConfiguration(current, key)
+
+	next := current.clone()
+	mutate(next)
+	cfg.applyRedactionTag(next)
+
+	cfg.registerTypeMap(key, next)
+	if cfg.optLevel > OptimizationNone {
+		cfg.optimizedMaps[key] = cfg.compileOptimized(next, cfg.optLevel)
+	}
+	cfg.invalidatePlans()
+	b.typeMap = next
 }
 
 // ForMember configures a specific destination member mapping using a field selector.
@@ -39,34 +101,46 @@ func (b *TypeMapBuilder[TSrc, TDest]) ForMember(
 		return b
 	}
 
-	// Find or create member map
-	var mm *MemberMap
-	for _, m := range b.typeMap.memberMaps {
-		if m.destField == memberName {
-			mm = m
-			break
-		}
-	}
+	b.update(func(tm *TypeMap) {
+		b.configureMember(tm, memberName, opts)
+	})
 
-	if mm == nil {
-		// Create new member map
-		destInfo := b.mapper.config.typeCache.getTypeInfo(destType)
-		if fi, ok := destInfo.fieldsByName[memberName]; ok {
-			mm = &MemberMap{
-				destField:    memberName,
-				destFieldIdx: fi.index,
-			}
-			b.typeMap.memberMaps = append(b.typeMap.memberMaps, mm)
-		}
-	}
+	return b
+}
 
-	if mm != nil {
+// configureMember finds or creates the member map for a destination member
+// and applies the member options to it.
+func (b *TypeMapBuilder[TSrc, TDest]) configureMember(tm *TypeMap, destMemberName string, opts []MemberOption) {
+	if mm := tm.memberFor(destMemberName, b.mapper.config.typeCache); mm != nil {
 		for _, opt := range opts {
 			opt(mm)
 		}
+		if mm.batch != nil {
+			b.mapper.config.hasBatches.Store(true)
+		}
 	}
+	tm.orderMemberDependencies()
+}
 
-	return b
+// memberFor returns the member map of a destination member, adding one if
+// the member exists but is not configured yet, or nil if it does not exist.
+func (tm *TypeMap) memberFor(destMemberName string, cache *typeCache) *MemberMap {
+	for _, mm := range tm.memberMaps {
+		if mm.destField == destMemberName {
+			return mm
+		}
+	}
+
+	fi, ok := cache.getTypeInfo(tm.destType).member(destMemberName)
+	if !ok {
+		return nil
+	}
+	mm := &MemberMap{
+		destField:    destMemberName,
+		destFieldIdx: fi.index,
+	}
+	tm.memberMaps = append(tm.memberMaps, mm)
+	return mm
 }
 
 // findMemberName attempts to find the member name from a selector function.
@@ -179,48 +253,88 @@ func (b *TypeMapBuilder[TSrc, TDest]) ForMemberByName(
 	destMemberName string,
 	opts ...MemberOption,
 ) *TypeMapBuilder[TSrc, TDest] {
-	var dest TDest
-	destType := reflect.TypeOf(dest)
-	if destType.Kind() == reflect.Ptr {
-		destType = destType.Elem()
-	}
+	b.update(func(tm *TypeMap) {
+		b.configureMember(tm, destMemberName, opts)
+	})
+
+	return b
+}
 
-	// Find or create member map
-	var mm *MemberMap
-	for _, m := range b.typeMap.memberMaps {
-		if m.destField == destMemberName {
-			mm = m
-			break
+// ResetMember drops every option configured for a destination member,
+// including ones inherited from a base or other package's configuration,
+// and maps it by convention again, as if it had never been configured.
+func (b *TypeMapBuilder[TSrc, TDest]) ResetMember(destMemberName string) *TypeMapBuilder[TSrc, TDest] {
+	b.update(func(tm *TypeMap) {
+		conventional := &TypeMap{srcType: tm.srcType, destType: tm.destType}
+		b.mapper.config.applyConventions(conventional)
+
+		var reset *MemberMap
+		for _, mm := range conventional.memberMaps {
+			if mm.destField == destMemberName {
+				reset = mm
+				break
+			}
 		}
-	}
 
-	if mm == nil {
-		destInfo := b.mapper.config.typeCache.getTypeInfo(destType)
-		if fi, ok := destInfo.fieldsByName[destMemberName]; ok {
-			mm = &MemberMap{
-				destField:    destMemberName,
-				destFieldIdx: fi.index,
+		memberMaps := make([]*MemberMap, 0, len(tm.memberMaps))
+		for _, mm := range tm.memberMaps {
+			switch {
+			case mm.destField != destMemberName:
+				memberMaps = append(memberMaps, mm)
+			case reset != nil:
+				memberMaps = append(memberMaps, reset)
 			}
-			b.typeMap.memberMaps = append(b.typeMap.memberMaps, mm)
 		}
-	}
+		tm.memberMaps = memberMaps
+		tm.orderMemberDependencies()
+	})
 
-	if mm != nil {
-		for _, opt := range opts {
-			opt(mm)
+	return b
+}
+
+// ForMembersWithTag applies member options to every destination member whose
+// struct tag has the given key, such as `sensitive:"true"`:
+//
+//	CreateMap[User, UserDTO](mapper).ForMembersWithTag("sensitive", Ignore())
+func (b *TypeMapBuilder[TSrc, TDest]) ForMembersWithTag(
+	tag string,
+	opts ...MemberOption,
+) *TypeMapBuilder[TSrc, TDest] {
+	b.update(func(tm *TypeMap) {
+		for _, field := range b.mapper.config.typeCache.getTypeInfo(tm.destType).fields {
+			if _, ok := field.tag.Lookup(tag); ok {
+				b.configureMember(tm, field.name, opts)
+			}
 		}
-	}
+	})
 
 	return b
 }
 
 // MemberOption is a function that configures a member mapping.
+//
+// Options setting where a member's value comes from apply with a fixed
+// precedence, whatever their order: Ignore first, then the last resolver
+// (MapFromFunc, MapFromBatch, MapFromFields, Coalesce, or MapFrom with a
+// path), then the last MapFrom source field. ValidateConfiguration reports
+// the options shadowed this way.
 type MemberOption func(*MemberMap)
 
 // MapFrom configures the source field name for a destination member.
+// The name may be a path into nested structs and slice or array elements,
+// such as "Addresses[0].City"; the member is left unchanged when an element
+// is out of range or the path crosses a nil pointer.
 func MapFrom(srcFieldName string) MemberOption {
+	if strings.ContainsAny(srcFieldName, ".[") {
+		return mapFromPath(srcFieldName)
+	}
 	return func(mm *MemberMap) {
 		mm.srcField = srcFieldName
+		mm.addSource(fmt.Sprintf("MapFrom(%q)", srcFieldName), false)
+		// Drop the index of a conventionally matched source member
+		mm.srcFieldIdx = nil
+		mm.useFlattening = false
+		mm.flattenPath = nil
 	}
 }
 
@@ -228,6 +342,9 @@ func MapFrom(srcFieldName string) MemberOption {
 func MapFromFunc(resolver ValueResolver) MemberOption {
 	return func(mm *MemberMap) {
 		mm.resolver = resolver
+		mm.srcFields = nil
+		mm.batch = nil
+		mm.addSource("MapFromFunc", true)
 	}
 }
 
@@ -242,6 +359,63 @@ func Ignore() MemberOption {
 func Condition(cond ConditionFunc) MemberOption {
 	return func(mm *MemberMap) {
 		mm.condition = cond
+		mm.ptrCond = nil
+		mm.condSrc = nil
+	}
+}
+
+// ConditionT configures a condition for mapping a destination member,
+// typed by the source type of the map or a pointer to it. Other types leave
+// the member unmapped and are reported by ValidateConfiguration.
+func ConditionT[TSrc any](cond func(src TSrc) bool) MemberOption {
+	srcType := reflect.TypeOf((*TSrc)(nil)).Elem()
+	return func(mm *MemberMap) {
+		mm.condition = func(src any) bool {
+			switch v := src.(type) {
+			case TSrc:
+				return cond(v)
+			case *TSrc:
+				return v != nil && cond(*v)
+			}
+			rv := reflect.ValueOf(src)
+			if !rv.IsValid() || reflect.PointerTo(rv.Type()) != srcType {
+				return false
+			}
+			ptr := reflect.New(rv.Type())
+			ptr.Elem().Set(rv)
+			return cond(ptr.Interface().(TSrc))
+		}
+		mm.ptrCond = nil
+		mm.condSrc = srcType
+	}
+}
+
+// ConditionPtr configures a condition for mapping a destination member that
+// receives a pointer to the source instead of a copy, so large structs
+// mapped by pointer are not copied per evaluation. After BeforeMap hooks, it
+// points to the working copy they mutated. Conditions must not modify the
+// source. Like ConditionT, it leaves members of maps from another source
+// type unmapped and ValidateConfiguration reports them.
+func ConditionPtr[TSrc any](cond func(src *TSrc) bool) MemberOption {
+	srcType := reflect.TypeOf((*TSrc)(nil)).Elem()
+	return func(mm *MemberMap) {
+		mm.condition = nil
+		mm.ptrCond = func(src reflect.Value) bool {
+			if src.Kind() == reflect.Pointer && src.Type().Elem() == srcType {
+				if src.IsNil() {
+					return false
+				}
+				src = src.Elem()
+			}
+			if src.Type() != srcType {
+				return false
+			}
+			if !src.CanAddr() {
+				src = sourceWorkingCopy(src)
+			}
+			return cond(src.Addr().Interface().(*TSrc))
+		}
+		mm.condSrc = srcType
 	}
 }
 
@@ -252,8 +426,19 @@ func UseConverter(converter TypeConverter) MemberOption {
 	}
 }
 
-// ConvertUsing registers a global type converter.
-func ConvertUsing[TSrc, TDest any](m *Mapper, converter func(TSrc) (TDest, error)) {
+// ElementConverter configures a converter applied to every element of a
+// slice, array or map member (map values for maps); the converter receives
+// the destination element type.
+func ElementConverter(converter TypeConverter) MemberOption {
+	return func(mm *MemberMap) {
+		mm.elemConverter = converter
+	}
+}
+
+// ConvertUsing registers a global type converter. Under WithSealOnFirstUse
+// it is ignored, and an error wrapping ErrMapSealed returned, once a type
+// map from TSrc or to TDest is sealed.
+func ConvertUsing[TSrc, TDest any](m *Mapper, converter func(TSrc) (TDest, error)) error {
 	var src TSrc
 	var dest TDest
 	srcType := reflect.TypeOf(src)
@@ -264,20 +449,29 @@ func ConvertUsing[TSrc, TDest any](m *Mapper, converter func(TSrc) (TDest, error
 	m.config.mu.Lock()
 	defer m.config.mu.Unlock()
 
+	if m.config.rejectSealed(nil, key) {
+		return sealedError(key)
+	}
+	m.config.checkLateConfiguration(nil, key)
 	m.config.converters[key] = func(s any, dt reflect.Type) (any, error) {
 		srcVal, ok := s.(TSrc)
 		if !ok {
 			return nil, &MappingError{
 				Message: "invalid source type for converter",
+				Code:    CodeIncompatibleTypes,
 			}
 		}
 		return converter(srcVal)
 	}
+	m.config.invalidatePlans()
+	return nil
 }
 
-// BeforeMap adds a function to be called before mapping.
+// BeforeMap adds a function to be called before mapping. src points to a
+// shallow working copy of the source: changes made through it are seen by
+// the member mapping and later hooks but not by the caller.
 func (b *TypeMapBuilder[TSrc, TDest]) BeforeMap(fn func(src *TSrc, dest *TDest) error) *TypeMapBuilder[TSrc, TDest] {
-	b.typeMap.beforeMap = append(b.typeMap.beforeMap, func(s any, d any) error {
+	hook := func(s any, d any) error {
 		srcPtr, ok := s.(*TSrc)
 		if !ok {
 			if srcVal, ok := s.(TSrc); ok {
@@ -291,13 +485,16 @@ func (b *TypeMapBuilder[TSrc, TDest]) BeforeMap(fn func(src *TSrc, dest *TDest)
 			return nil
 		}
 		return fn(srcPtr, destPtr)
+	}
+	b.update(func(tm *TypeMap) {
+		tm.beforeMap = append(tm.beforeMap, hook)
 	})
 	return b
 }
 
 // AfterMap adds a function to be called after mapping.
 func (b *TypeMapBuilder[TSrc, TDest]) AfterMap(fn func(src *TSrc, dest *TDest) error) *TypeMapBuilder[TSrc, TDest] {
-	b.typeMap.afterMap = append(b.typeMap.afterMap, func(s any, d any) error {
+	hook := func(s any, d any) error {
 		srcPtr, ok := s.(*TSrc)
 		if !ok {
 			if srcVal, ok := s.(TSrc); ok {
@@ -311,23 +508,65 @@ func (b *TypeMapBuilder[TSrc, TDest]) AfterMap(fn func(src *TSrc, dest *TDest) e
 			return nil
 		}
 		return fn(srcPtr, destPtr)
+	}
+	b.update(func(tm *TypeMap) {
+		tm.afterMap = append(tm.afterMap, hook)
 	})
 	return b
 }
 
 // CustomMap sets a custom mapping function for the entire type.
 func (b *TypeMapBuilder[TSrc, TDest]) CustomMap(fn func(src TSrc, dest *TDest) error) *TypeMapBuilder[TSrc, TDest] {
-	b.typeMap.customMapper = func(s any, d any) error {
+	custom := func(_ *MappingContext, s any, d any) error {
 		srcVal, ok := s.(TSrc)
 		if !ok {
-			return &MappingError{Message: "invalid source type for custom mapper"}
+			return &MappingError{Message: "invalid source type for custom mapper", Code: CodeIncompatibleTypes}
 		}
 		destPtr, ok := d.(*TDest)
 		if !ok {
-			return &MappingError{Message: "invalid destination type for custom mapper"}
+			return &MappingError{Message: "invalid destination type for custom mapper", Code: CodeIncompatibleTypes}
 		}
 		return fn(srcVal, destPtr)
 	}
+	b.update(func(tm *TypeMap) {
+		tm.customMapper = custom
+	})
+	return b
+}
+
+// WithMemberPolicy sets a policy deciding, on every mapping call, whether
+// each destination member is mapped. Members the policy rejects keep their
+// zero value, which centralizes role- or claim-based redaction in the map
+// configuration. ctx is nil for calls made without per-call options.
+func (b *TypeMapBuilder[TSrc, TDest]) WithMemberPolicy(policy MemberPolicy) *TypeMapBuilder[TSrc, TDest] {
+	b.update(func(tm *TypeMap) {
+		tm.memberPolicy = policy
+	})
+	return b
+}
+
+// CustomMapCtx sets a custom mapping function that receives the mapping
+// context, so it can map nested members through registered type maps with
+// MapWithContext.
+func (b *TypeMapBuilder[TSrc, TDest]) CustomMapCtx(fn func(ctx *MappingContext, src TSrc, dest *TDest) error) *TypeMapBuilder[TSrc, TDest] {
+	m := b.mapper
+	custom := func(ctx *MappingContext, s any, d any) error {
+		srcVal, ok := s.(TSrc)
+		if !ok {
+			return &MappingError{Message: "invalid source type for custom mapper", Code: CodeIncompatibleTypes}
+		}
+		destPtr, ok := d.(*TDest)
+		if !ok {
+			return &MappingError{Message: "invalid destination type for custom mapper", Code: CodeIncompatibleTypes}
+		}
+		if ctx == nil {
+			ctx = &MappingContext{mapper: m}
+		}
+		return fn(ctx, srcVal, destPtr)
+	}
+	b.update(func(tm *TypeMap) {
+		tm.customMapper = custom
+	})
 	return b
 }
 
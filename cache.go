@@ -7,9 +7,11 @@ import (
 )
 
 // typeCache caches type information for faster reflection operations.
+// Entries are written once and read many times, which is the access pattern
+// sync.Map is optimized for, so concurrent lookups of distinct types never
+// contend on a shared lock.
 type typeCache struct {
-	mu    sync.RWMutex
-	cache map[reflect.Type]*typeInfo
+	cache sync.Map // map[reflect.Type]*typeInfo
 }
 
 // typeInfo holds cached information about a type.
@@ -17,6 +19,10 @@ type typeInfo struct {
 	typ          reflect.Type
 	fields       []*fieldInfo
 	fieldsByName map[string]*fieldInfo
+	ambiguous    []string // promoted names dropped due to same-depth conflicts
+	// embedded holds the exported embedded structs in field order.
+	embedded       []*fieldInfo
+	embeddedByName map[string]*fieldInfo
 }
 
 // fieldInfo holds cached information about a struct field.
@@ -24,14 +30,13 @@ type fieldInfo struct {
 	name      string
 	index     []int
 	fieldType reflect.Type
+	tag       reflect.StructTag
 	canSet    bool
 }
 
 // newTypeCache creates a new type cache.
 func newTypeCache() *typeCache {
-	return &typeCache{
-		cache: make(map[reflect.Type]*typeInfo),
-	}
+	return &typeCache{}
 }
 
 // getTypeInfo retrieves or builds type information for a given type.
@@ -41,44 +46,93 @@ func (tc *typeCache) getTypeInfo(t reflect.Type) *typeInfo {
 		t = t.Elem()
 	}
 
-	tc.mu.RLock()
-	info, ok := tc.cache[t]
-	tc.mu.RUnlock()
-	if ok {
-		return info
-	}
-
-	tc.mu.Lock()
-	defer tc.mu.Unlock()
-
-	// Double-check after acquiring write lock
-	if info, ok = tc.cache[t]; ok {
-		return info
+	if info, ok := tc.cache.Load(t); ok {
+		return info.(*typeInfo)
 	}
 
-	info = tc.buildTypeInfo(t)
-	tc.cache[t] = info
-	return info
+	// Concurrent builders may race here; LoadOrStore keeps the first result
+	// so every caller observes the same *typeInfo.
+	info, _ := tc.cache.LoadOrStore(t, tc.buildTypeInfo(t))
+	return info.(*typeInfo)
 }
 
 // buildTypeInfo builds type information for a struct type.
 func (tc *typeCache) buildTypeInfo(t reflect.Type) *typeInfo {
 	info := &typeInfo{
-		typ:          t,
-		fields:       make([]*fieldInfo, 0),
-		fieldsByName: make(map[string]*fieldInfo),
+		typ:            t,
+		fields:         make([]*fieldInfo, 0),
+		fieldsByName:   make(map[string]*fieldInfo),
+		embeddedByName: make(map[string]*fieldInfo),
 	}
 
 	if t.Kind() != reflect.Struct {
 		return info
 	}
 
-	tc.collectFields(t, nil, info)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.IsExported() && derefType(field.Type).Kind() == reflect.Struct {
+			fi := &fieldInfo{
+				name:      field.Name,
+				index:     field.Index,
+				fieldType: field.Type,
+				tag:       field.Tag,
+				canSet:    true,
+			}
+			info.embedded = append(info.embedded, fi)
+			info.embeddedByName[field.Name] = fi
+		}
+	}
+
+	var candidates []*fieldInfo
+	tc.collectFields(t, nil, map[reflect.Type]bool{t: true}, &candidates)
+
+	// Apply Go's promotion rules: the shallowest field wins, and a name
+	// provided by several fields at the same depth is ambiguous and dropped.
+	shallowest := make(map[string]int)
+	count := make(map[string]int)
+	for _, fi := range candidates {
+		depth, seen := shallowest[fi.name]
+		switch {
+		case !seen || len(fi.index) < depth:
+			shallowest[fi.name] = len(fi.index)
+			count[fi.name] = 1
+		case len(fi.index) == depth:
+			count[fi.name]++
+		}
+	}
+
+	for _, fi := range candidates {
+		if len(fi.index) != shallowest[fi.name] {
+			continue
+		}
+		if count[fi.name] > 1 {
+			if !containsString(info.ambiguous, fi.name) {
+				info.ambiguous = append(info.ambiguous, fi.name)
+			}
+			continue
+		}
+		info.fields = append(info.fields, fi)
+		info.fieldsByName[fi.name] = fi
+	}
+
 	return info
 }
 
-// collectFields recursively collects fields from a struct type.
-func (tc *typeCache) collectFields(t reflect.Type, index []int, info *typeInfo) {
+// member looks up a field by name, falling back to the embedded structs so
+// an embedded struct can be mapped to or from a named member as a whole.
+func (info *typeInfo) member(name string) (*fieldInfo, bool) {
+	if fi, ok := info.fieldsByName[name]; ok {
+		return fi, true
+	}
+	fi, ok := info.embeddedByName[name]
+	return fi, ok
+}
+
+// collectFields recursively collects candidate fields from a struct type,
+// descending into embedded structs. visited guards against embedded
+// pointer cycles such as `type Node struct{ *Node }`.
+func (tc *typeCache) collectFields(t reflect.Type, index []int, visited map[reflect.Type]bool, candidates *[]*fieldInfo) {
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		fieldIdx := append(append([]int{}, index...), i)
@@ -90,7 +144,11 @@ func (tc *typeCache) collectFields(t reflect.Type, index []int, info *typeInfo)
 				fieldType = fieldType.Elem()
 			}
 			if fieldType.Kind() == reflect.Struct {
-				tc.collectFields(fieldType, fieldIdx, info)
+				if !visited[fieldType] {
+					visited[fieldType] = true
+					tc.collectFields(fieldType, fieldIdx, visited, candidates)
+					delete(visited, fieldType)
+				}
 				continue
 			}
 		}
@@ -100,15 +158,24 @@ func (tc *typeCache) collectFields(t reflect.Type, index []int, info *typeInfo)
 			continue
 		}
 
-		fi := &fieldInfo{
+		*candidates = append(*candidates, &fieldInfo{
 			name:      field.Name,
 			index:     fieldIdx,
 			fieldType: field.Type,
+			tag:       field.Tag,
 			canSet:    true,
+		})
+	}
+}
+
+// containsString reports whether s is present in list.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
 		}
-		info.fields = append(info.fields, fi)
-		info.fieldsByName[field.Name] = fi
 	}
+	return false
 }
 
 // splitPascalCase splits a PascalCase string into individual words.
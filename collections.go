@@ -0,0 +1,131 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// collectionOp post-processes a mapped collection member and returns the
+// value to store.
+type collectionOp func(v reflect.Value) reflect.Value
+
+// withCollectionOp returns a MemberOption appending op, which processes
+// slices of elemType, to a member's collection operations. The lists are
+// copied so earlier versions of a copy-on-write type map keep their own.
+// ValidateConfiguration reports ops whose element type does not match the
+// member's.
+func withCollectionOp(elemType reflect.Type, op collectionOp) MemberOption {
+	return func(mm *MemberMap) {
+		mm.collectionOps = append(mm.collectionOps[:len(mm.collectionOps):len(mm.collectionOps)], op)
+		mm.collElems = append(mm.collElems[:len(mm.collElems):len(mm.collElems)], elemType)
+	}
+}
+
+// SortBy orders the elements of a mapped slice member with less, using a
+// stable sort so equal elements keep their source order. T is the
+// destination element type; members with other element types are left
+// unsorted and reported by ValidateConfiguration. The sorted slice is a copy, so a source slice assigned to the
+// member as is keeps its order.
+func SortBy[T any](less func(a, b T) bool) MemberOption {
+	elemType := reflect.TypeOf((*T)(nil)).Elem()
+	return withCollectionOp(elemType, func(v reflect.Value) reflect.Value {
+		if v.Kind() != reflect.Slice || v.IsNil() || v.Type().Elem() != elemType {
+			return v
+		}
+		sorted := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(sorted, v)
+		sortSlice(sorted, less)
+		return sorted
+	})
+}
+
+// MapSliceSorted maps a slice like MapSlice and orders the result with less.
+func MapSliceSorted[TSrc, TDest any](m *Mapper, src []TSrc, less func(a, b TDest) bool) ([]TDest, error) {
+	result, err := MapSlice[TSrc, TDest](m, src)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return less(result[i], result[j])
+	})
+	return result, nil
+}
+
+// sortSlice stably sorts a slice value whose elements are of type T.
+func sortSlice[T any](v reflect.Value, less func(a, b T) bool) {
+	sort.SliceStable(v.Interface(), func(i, j int) bool {
+		return less(v.Index(i).Interface().(T), v.Index(j).Interface().(T))
+	})
+}
+
+// Distinct removes elements of a mapped slice member whose key equals that
+// of an earlier element, so duplicates in the source collection collapse
+// into one destination element. T is the destination element type; members
+// with other element types are left unchanged and reported by
+// ValidateConfiguration.
+func Distinct[T any, K comparable](key func(T) K) MemberOption {
+	elemType := reflect.TypeOf((*T)(nil)).Elem()
+	return withCollectionOp(elemType, func(v reflect.Value) reflect.Value {
+		if v.Kind() != reflect.Slice || v.IsNil() || v.Type().Elem() != elemType {
+			return v
+		}
+		seen := make(map[K]bool, v.Len())
+		out := reflect.MakeSlice(v.Type(), 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			k := key(elem.Interface().(T))
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			out = reflect.Append(out, elem)
+		}
+		return out
+	})
+}
+
+// MapGroupBy maps each source element and groups the results by key,
+// preserving source order within each group.
+func MapGroupBy[TSrc any, K comparable, TDest any](m *Mapper, src []TSrc, key func(TSrc) K) (map[K][]TDest, error) {
+	groups := make(map[K][]TDest)
+	for i, s := range src {
+		dest, err := Map[TDest](m, s)
+		if err != nil {
+			return nil, &MappingError{
+				Message:    fmt.Sprintf("error mapping element at index %d", i),
+				InnerError: err,
+			}
+		}
+		k := key(s)
+		groups[k] = append(groups[k], dest)
+	}
+	return groups, nil
+}
+
+// ShareIdenticalSlices lets slice destinations reference the source's
+// backing array instead of copying element by element whenever both have
+// the same element type. Same-typed slices are always shared; this extends
+// sharing to named slice types under WithDisableImplicitConversion and to
+// addressable source arrays. Changes made through either side are then
+// visible in the other.
+func ShareIdenticalSlices() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.shareSlices = true
+	}
+}
+
+// sharedSlice returns srcVal as a destType slice sharing its backing array,
+// if ShareIdenticalSlices allows it.
+func (m *Mapper) sharedSlice(srcVal reflect.Value, destType reflect.Type) (reflect.Value, bool) {
+	if !m.config.shareSlices || destType.Kind() != reflect.Slice || srcVal.Type().Elem() != destType.Elem() {
+		return reflect.Value{}, false
+	}
+	switch {
+	case srcVal.Kind() == reflect.Slice:
+		return srcVal.Convert(destType), true
+	case srcVal.CanAddr():
+		return srcVal.Slice(0, srcVal.Len()).Convert(destType), true
+	}
+	return reflect.Value{}, false
+}
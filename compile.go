@@ -0,0 +1,88 @@
+package automapper
+
+import (
+	"reflect"
+	"sort"
+)
+
+// TypeMapView gives OnCompile hooks access to a type map while it is built.
+// It is only valid for the duration of the hook.
+type TypeMapView struct {
+	tm     *TypeMap
+	config *MapperConfiguration
+}
+
+// OnCompile registers a hook run for every type map created afterwards,
+// explicitly or automatically, once its members are configured by
+// convention and before builder options apply. Hooks can inspect and
+// augment member maps to enforce organization-wide policies, such as
+// ignoring tenancy columns. An error from a hook is reported by
+// ValidateConfiguration and fails the mappings using the type map. Hooks
+// run once per type map, while the mapper's configuration is locked, and
+// must not configure the mapper.
+func (m *Mapper) OnCompile(hook func(tm *TypeMapView) error) {
+	m.config.mu.Lock()
+	defer m.config.mu.Unlock()
+	m.config.onCompile = append(m.config.onCompile, hook)
+}
+
+// runCompileHooks runs the OnCompile hooks for a new type map.
+func (c *MapperConfiguration) runCompileHooks(tm *TypeMap) error {
+	view := &TypeMapView{tm: tm, config: c}
+	for _, hook := range c.onCompile {
+		if err := hook(view); err != nil {
+			return &MappingError{
+				Message:    "compile hook error",
+				Code:       CodeHook,
+				SrcType:    tm.srcType,
+				DestType:   tm.destType,
+				InnerError: err,
+			}
+		}
+	}
+	return nil
+}
+
+// SrcType returns the source type of the type map.
+func (v *TypeMapView) SrcType() reflect.Type {
+	return v.tm.srcType
+}
+
+// DestType returns the destination type of the type map.
+func (v *TypeMapView) DestType() reflect.Type {
+	return v.tm.destType
+}
+
+// Members returns the names of the configured destination members, sorted.
+func (v *TypeMapView) Members() []string {
+	names := make([]string, 0, len(v.tm.memberMaps))
+	for _, mm := range v.tm.memberMaps {
+		names = append(names, mm.destField)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForMember applies member options to a destination member, configuring it
+// if it was not matched by convention.
+func (v *TypeMapView) ForMember(name string, opts ...MemberOption) error {
+	mm := v.tm.memberFor(name, v.config.typeCache)
+	if mm == nil {
+		return &MappingError{
+			Message:   "unknown destination member",
+			Code:      CodeInvalidArgument,
+			SrcType:   v.tm.srcType,
+			DestType:  v.tm.destType,
+			FieldName: name,
+		}
+	}
+
+	for _, opt := range opts {
+		opt(mm)
+	}
+	if mm.batch != nil {
+		v.config.hasBatches.Store(true)
+	}
+	v.tm.orderMemberDependencies()
+	return nil
+}
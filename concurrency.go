@@ -0,0 +1,132 @@
+package automapper
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// libraryDir is the directory holding this package's sources, used to find
+// the first caller frame outside the library.
+var libraryDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// CheckConcurrency enables a debug mode that detects configuration changes
+// made after mapping has begun. Such changes are safe, since type maps are
+// copy-on-write, but mappings already running keep using the old
+// configuration, which usually points at a missing initialization order.
+// Every offending call is reported with its call site by
+// ValidateConfiguration as a SeverityError issue.
+func (m *Mapper) CheckConcurrency() *Mapper {
+	m.config.checkConcurrency.Store(true)
+	return m
+}
+
+// noteMappingStarted records that tm has been used for mapping.
+func (m *Mapper) noteMappingStarted(tm *TypeMap) {
+	if !m.config.checkConcurrency.Load() {
+		return
+	}
+	if !m.config.mappingStarted.Load() {
+		m.config.mappingStarted.Store(true)
+	}
+	if !tm.used.Load() {
+		tm.used.Store(true)
+	}
+}
+
+// checkLateConfiguration records a violation if tm (or, when tm is nil, any
+// map) has already been used for mapping. The caller must hold config.mu.
+func (c *MapperConfiguration) checkLateConfiguration(tm *TypeMap, key typeMapKey) {
+	if !c.checkConcurrency.Load() {
+		return
+	}
+	if tm != nil && !tm.used.Load() {
+		return
+	}
+	if tm == nil && !c.mappingStarted.Load() {
+		return
+	}
+
+	operation, callSite := configurationCaller()
+	c.violations = append(c.violations, ValidationIssue{
+		Severity: SeverityError,
+		SrcType:  key.srcType,
+		DestType: key.destType,
+		Message:  fmt.Sprintf("%s called at %s after mapping began", operation, callSite),
+	})
+}
+
+// rejectSealed reports whether a configuration call must be ignored because
+// tm is sealed or, when tm is nil, because a global registration for key
+// would change a sealed map with key's source or destination type, and
+// records the call as a violation. The caller must hold config.mu.
+func (c *MapperConfiguration) rejectSealed(tm *TypeMap, key typeMapKey) bool {
+	sealed := tm != nil && tm.sealed.Load()
+	if tm == nil {
+		for mapKey, registered := range c.typeMaps {
+			if registered.sealed.Load() && sharesType(mapKey, key) {
+				sealed = true
+				break
+			}
+		}
+	}
+	if !sealed {
+		return false
+	}
+
+	operation, callSite := configurationCaller()
+	c.violations = append(c.violations, ValidationIssue{
+		Severity: SeverityError,
+		SrcType:  key.srcType,
+		DestType: key.destType,
+		Message:  fmt.Sprintf("%s called at %s after a type map was sealed; the change is ignored", operation, callSite),
+	})
+	return true
+}
+
+// sharesType reports whether a registration for key involves the source
+// or destination type of the type map registered for mapKey.
+func sharesType(mapKey, key typeMapKey) bool {
+	return (key.srcType != nil && derefType(key.srcType) == mapKey.srcType) ||
+		(key.destType != nil && derefType(key.destType) == mapKey.destType)
+}
+
+// sealedError is the error a builder reports for a call rejected because
+// the type map for key is sealed.
+func sealedError(key typeMapKey) *MappingError {
+	return &MappingError{
+		Message:    "cannot configure type map after it has been sealed; configure maps before their first use",
+		Code:       CodeSealed,
+		SrcType:    key.srcType,
+		DestType:   key.destType,
+		InnerError: ErrMapSealed,
+	}
+}
+
+// configurationCaller returns the library function that was called and the
+// file:line of the first frame outside the library that called it.
+func configurationCaller() (operation, callSite string) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		inLibrary := filepath.Dir(frame.File) == libraryDir && !strings.HasSuffix(frame.File, "_test.go")
+		if !inLibrary {
+			return operation, fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !strings.Contains(frame.Function, ".func") {
+			// Generic functions are named like "pkg.CreateMap[...]"
+			name := strings.TrimSuffix(frame.Function, "[...]")
+			operation = name[strings.LastIndex(name, ".")+1:]
+		}
+		if !more {
+			return operation, "unknown"
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package automapper
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// WithConcurrentResolvers runs the independent resolvers of the type map
+// concurrently, at most max at a time, before the other members are
+// mapped. Members ordered with After, or that other members depend on, keep
+// running in order. Resolvers see the destination as it was before any
+// member was mapped. Once a resolver fails, or the context.Context set with
+// WithContext is done, no further resolvers are started and the members
+// not yet resolved are skipped. A resolver that panics fails the call.
+func (b *TypeMapBuilder[TSrc, TDest]) WithConcurrentResolvers(max int) *TypeMapBuilder[TSrc, TDest] {
+	b.update(func(tm *TypeMap) {
+		tm.concurrency = max
+	})
+	return b
+}
+
+// resolvedValue is the outcome of a resolver run ahead of its member.
+// Skipped members were not resolved because another resolver failed.
+type resolvedValue struct {
+	value   any
+	err     error
+	skipped bool
+}
+
+// member returns a copy of mm whose resolver yields the resolved outcome,
+// so resolveMember converts, assigns and wraps it as usual.
+func (r resolvedValue) member(mm *MemberMap) *MemberMap {
+	cp := *mm
+	cp.resolver = func(any, any) (any, error) { return r.value, r.err }
+	cp.batch = nil
+	cp.retries = 0
+	return &cp
+}
+
+// resolveConcurrently runs the independent resolvers of a plan configured
+// with WithConcurrentResolvers and returns their outcomes by member. A
+// failed resolver's outcome is returned with the others, and the members
+// left unresolved are marked skipped; executePlan reports the failure when
+// it reaches the member.
+func (m *Mapper) resolveConcurrently(ctx *MappingContext, srcVal, destVal reflect.Value, p *typeMapPlan, mask *fieldMask) (map[*MemberMap]resolvedValue, error) {
+	if p.concurrency <= 1 {
+		return nil, nil
+	}
+
+	members := concurrentMembers(ctx, srcVal, p, mask)
+	if len(members) < 2 {
+		return nil, nil
+	}
+
+	goCtx, cancel := context.WithCancel(ctx.Context())
+	defer cancel()
+
+	src, dest := srcVal.Interface(), destVal.Interface()
+	results := make(map[*MemberMap]resolvedValue, len(members))
+	sem := make(chan struct{}, p.concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+launch:
+	for _, mm := range members {
+		select {
+		case <-goCtx.Done():
+			break launch
+		case sem <- struct{}{}:
+		}
+		if goCtx.Err() != nil {
+			break launch
+		}
+
+		wg.Add(1)
+		go func(mm *MemberMap) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			value, err := callResolverRecovered(ctx, mm, src, dest)
+			mu.Lock()
+			results[mm] = resolvedValue{value: value, err: err}
+			mu.Unlock()
+			if err != nil {
+				cancel()
+			}
+		}(mm)
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		if r.err != nil {
+			for _, mm := range members {
+				if _, ok := results[mm]; !ok {
+					results[mm] = resolvedValue{skipped: true}
+				}
+			}
+			return results, nil
+		}
+	}
+	if err := ctx.Context().Err(); err != nil {
+		return nil, &MappingError{
+			Message:    "resolvers cancelled",
+			Code:       CodeCancelled,
+			SrcType:    srcVal.Type(),
+			DestType:   destVal.Type(),
+			InnerError: err,
+		}
+	}
+	return results, nil
+}
+
+// callResolverRecovered calls the member's resolver like callResolver,
+// returning a panic as an error since it runs on a worker goroutine.
+func callResolverRecovered(ctx *MappingContext, mm *MemberMap, src, dest any) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, fmt.Errorf("resolver panicked: %v", r)
+		}
+	}()
+	return callResolver(ctx, mm, src, dest)
+}
+
+// concurrentMembers returns the plan's members whose resolvers may run
+// concurrently: selected for this call, passing their condition, and
+// neither ordered after nor depended on by another member.
+func concurrentMembers(ctx *MappingContext, srcVal reflect.Value, p *typeMapPlan, mask *fieldMask) []*MemberMap {
+	dependedOn := make(map[string]bool)
+	for i := range p.instructions {
+		for _, dep := range p.instructions[i].member.after {
+			dependedOn[dep] = true
+		}
+	}
+
+	var members []*MemberMap
+	for i := range p.instructions {
+		mm := p.instructions[i].member
+		if mm.resolver == nil && mm.batch == nil {
+			continue
+		}
+		if len(mm.after) > 0 || dependedOn[mm.destField] {
+			continue
+		}
+		if mask != nil {
+			if _, selected := mask.children[mm.destField]; !selected {
+				continue
+			}
+		}
+		if p.policy != nil && !p.policy(ctx, mm.destField) {
+			continue
+		}
+		if !mm.passesCondition(srcVal) {
+			continue
+		}
+		members = append(members, mm)
+	}
+	return members
+}
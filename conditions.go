@@ -0,0 +1,71 @@
+package automapper
+
+import "reflect"
+
+// valueCondition decides from a member's source value and the destination
+// member's current value whether the member is mapped.
+type valueCondition func(src, dest reflect.Value) bool
+
+// pointerCondition decides from the source struct, passed by pointer,
+// whether a member is mapped.
+type pointerCondition func(src reflect.Value) bool
+
+// passesCondition reports whether mm's condition on the source struct, if
+// any, allows the member to be mapped from srcVal.
+func (mm *MemberMap) passesCondition(srcVal reflect.Value) bool {
+	switch {
+	case mm.ptrCond != nil:
+		return mm.ptrCond(srcVal)
+	case mm.condition != nil:
+		return mm.condition(srcVal.Interface())
+	}
+	return true
+}
+
+// IfNotZero maps the member only when its source value is not the zero
+// value of its type, leaving the destination's value alone otherwise.
+func IfNotZero() MemberOption {
+	return func(mm *MemberMap) {
+		mm.valueCond = func(src, _ reflect.Value) bool {
+			return !src.IsZero()
+		}
+	}
+}
+
+// IfNotEmpty maps the member only when its source value is a non-empty
+// string, slice, array or map, or a non-nil pointer to one. Values of other
+// kinds are mapped when they are not zero.
+func IfNotEmpty() MemberOption {
+	return func(mm *MemberMap) {
+		mm.valueCond = func(src, _ reflect.Value) bool {
+			src = derefValue(src)
+			if !src.IsValid() {
+				return false
+			}
+			switch src.Kind() {
+			case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+				return src.Len() > 0
+			}
+			return !src.IsZero()
+		}
+	}
+}
+
+// IfChanged maps the member only when its source value differs from the
+// destination's current value, so equal members of an existing destination
+// passed to MapTo keep their identity. Source values of another type are
+// compared after conversion to the destination type, and always mapped if
+// they cannot be converted.
+func IfChanged() MemberOption {
+	return func(mm *MemberMap) {
+		mm.valueCond = func(src, dest reflect.Value) bool {
+			if src.Type() != dest.Type() {
+				if !src.Type().ConvertibleTo(dest.Type()) {
+					return true
+				}
+				src = src.Convert(dest.Type())
+			}
+			return !reflect.DeepEqual(src.Interface(), dest.Interface())
+		}
+	}
+}
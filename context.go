@@ -0,0 +1,273 @@
+package automapper
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MappingContext carries per-call state through a single mapping operation.
+// Plain Map and MapTo calls run without a context; a nil *MappingContext is
+// valid and behaves as an empty context.
+type MappingContext struct {
+	mapper    *Mapper
+	items     map[string]any
+	rootKey   typeMapKey
+	ignored   map[string]bool
+	signature string
+	mask      *fieldMask // destination members selected at the current depth
+	observer  MemberObserver
+	memo      *memoCache
+	refs      map[refKey]reflect.Value
+	locale    string
+	goCtx     context.Context
+	batch     *batchResults
+	report    *MapReport
+	verifying bool
+	memoNext  *memoKey // key of the struct about to be memoized
+	interned  map[string]string
+	arena     *slabArena
+	depth     int // struct nesting depth, when tracksStructDepth
+	// finalizers and collected hook errors of the current call
+	finalizers []finalizer
+	hookErrors []error
+}
+
+// MemberObserver is notified of a destination member changed by a mapping,
+// with its dotted path and its values before and after the call.
+type MemberObserver func(path string, old, new any)
+
+// MapOption configures a single mapping call.
+type MapOption func(*MappingContext)
+
+// WithIgnoredMembers skips the named destination members of the top-level
+// type map for this call only.
+func WithIgnoredMembers(members ...string) MapOption {
+	return func(c *MappingContext) {
+		if c.ignored == nil {
+			c.ignored = make(map[string]bool, len(members))
+		}
+		for _, name := range members {
+			c.ignored[name] = true
+		}
+	}
+}
+
+// WithFieldMask restricts mapping to the listed destination member paths
+// ("Name", "Address.City"). Unlisted members keep their zero value; a path
+// naming a nested member selects everything beneath it.
+func WithFieldMask(paths ...string) MapOption {
+	return func(c *MappingContext) {
+		if c.mask == nil {
+			c.mask = &fieldMask{children: make(map[string]*fieldMask)}
+		}
+		for _, path := range paths {
+			c.mask.add(strings.Split(path, "."))
+		}
+	}
+}
+
+// OnMemberAssigned registers an observer called, for this call only, for
+// every destination member whose value the mapping changed. Observation
+// snapshots the destination first and is intended for audit trails rather
+// than hot paths.
+func OnMemberAssigned(fn MemberObserver) MapOption {
+	return func(c *MappingContext) {
+		c.observer = fn
+	}
+}
+
+// WithItem stores a value in the mapping context for this call only.
+// Items can be read back with MappingContext.Item.
+func WithItem(key string, value any) MapOption {
+	return func(c *MappingContext) {
+		if c.items == nil {
+			c.items = make(map[string]any)
+		}
+		c.items[key] = value
+	}
+}
+
+// WithContext sets the context.Context of this call, whose deadline and
+// cancellation bound resolver retries. Resolvers can read it back with
+// MappingContext.Context.
+func WithContext(goCtx context.Context) MapOption {
+	return func(c *MappingContext) {
+		c.goCtx = goCtx
+	}
+}
+
+// newMappingContext builds a context for a call mapping into destType.
+func (m *Mapper) newMappingContext(src any, destType reflect.Type, opts []MapOption) *MappingContext {
+	ctx := &MappingContext{mapper: m}
+	for _, opt := range opts {
+		opt(ctx)
+	}
+
+	srcType := reflect.TypeOf(src)
+	for srcType != nil && srcType.Kind() == reflect.Ptr {
+		srcType = srcType.Elem()
+	}
+	if destType.Kind() == reflect.Ptr {
+		destType = destType.Elem()
+	}
+	ctx.rootKey = typeMapKey{srcType: srcType, destType: destType}
+
+	if len(ctx.ignored) > 0 {
+		names := make([]string, 0, len(ctx.ignored))
+		for name := range ctx.ignored {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		ctx.signature = strings.Join(names, ",")
+	}
+
+	return ctx
+}
+
+// Item returns a value stored with WithItem.
+func (c *MappingContext) Item(key string) (any, bool) {
+	if c == nil {
+		return nil, false
+	}
+	v, ok := c.items[key]
+	return v, ok
+}
+
+// Context returns the context.Context set with WithContext, or
+// context.Background if none was set.
+func (c *MappingContext) Context() context.Context {
+	if c == nil || c.goCtx == nil {
+		return context.Background()
+	}
+	return c.goCtx
+}
+
+// Mapper returns the mapper performing the current mapping, or nil for a
+// nil context.
+func (c *MappingContext) Mapper() *Mapper {
+	if c == nil {
+		return nil
+	}
+	return c.mapper
+}
+
+// maskNode returns the field mask for the struct currently being mapped,
+// or nil if every member is selected.
+func (c *MappingContext) maskNode() *fieldMask {
+	if c == nil {
+		return nil
+	}
+	return c.mask
+}
+
+// needsStandardPath reports whether mapping key must use the plan-based
+// standard path because per-call options affect its members.
+func (c *MappingContext) needsStandardPath(key typeMapKey) bool {
+	return c.overridesPlan(key) || c.maskNode() != nil || c.recording() || (c != nil && c.verifying)
+}
+
+// overridesPlan reports whether the context derives a custom plan for key.
+func (c *MappingContext) overridesPlan(key typeMapKey) bool {
+	return c != nil && c.signature != "" && c.rootKey == key
+}
+
+// MapWithOptions performs mapping from source to a new destination instance
+// using per-call options.
+func MapWithOptions[TDest any](m *Mapper, src any, opts ...MapOption) (TDest, error) {
+	var dest TDest
+	if err := m.checkNilSource(src, reflect.TypeOf((*TDest)(nil)).Elem()); err != nil {
+		return dest, err
+	}
+	ctx := m.newMappingContext(src, reflect.TypeOf((*TDest)(nil)).Elem(), opts)
+
+	err := m.mapObserved(ctx, reflect.ValueOf(src), reflect.ValueOf(&dest).Elem())
+	if err != nil {
+		return dest, err
+	}
+
+	return dest, nil
+}
+
+// MapToWithOptions performs mapping from source to an existing destination
+// using per-call options.
+func MapToWithOptions[TDest any](m *Mapper, src any, dest *TDest, opts ...MapOption) error {
+	if err := m.checkNilSource(src, reflect.TypeOf(dest).Elem()); err != nil {
+		return err
+	}
+	ctx := m.newMappingContext(src, reflect.TypeOf(dest).Elem(), opts)
+	return m.mapObserved(ctx, reflect.ValueOf(src), reflect.ValueOf(dest).Elem())
+}
+
+// mapObserved maps a top-level call, reporting changed members to the
+// context's observer if one is registered.
+func (m *Mapper) mapObserved(ctx *MappingContext, srcVal, destVal reflect.Value) error {
+	if ctx.observer == nil {
+		return m.mapValue(ctx, srcVal, destVal)
+	}
+
+	before := deepCopyValue(destVal)
+	if err := m.mapValue(ctx, srcVal, destVal); err != nil {
+		return err
+	}
+	walkChanges(before, destVal, goFieldName, func(path string, old, new reflect.Value) {
+		ctx.observer(path, old.Interface(), new.Interface())
+	})
+	return nil
+}
+
+// MapPartial maps only the destination members named by fieldMask,
+// leaving all others at their zero value. Paths may address nested members
+// with dot notation, as in field-mask APIs and sparse fieldsets.
+func MapPartial[TDest any](m *Mapper, src any, fieldMask []string) (TDest, error) {
+	return MapWithOptions[TDest](m, src, WithFieldMask(fieldMask...))
+}
+
+// fieldMask is a tree of selected destination member names. A nil child
+// selects the member and everything beneath it.
+type fieldMask struct {
+	children map[string]*fieldMask
+}
+
+// add selects a member path in the mask.
+func (f *fieldMask) add(path []string) {
+	child, exists := f.children[path[0]]
+	if len(path) == 1 {
+		// Selecting a whole member supersedes any narrower selection
+		f.children[path[0]] = nil
+		return
+	}
+	if exists && child == nil {
+		return
+	}
+	if child == nil {
+		child = &fieldMask{children: make(map[string]*fieldMask)}
+		f.children[path[0]] = child
+	}
+	child.add(path[1:])
+}
+
+// MapWithContext maps src to a new destination instance within an existing
+// mapping context, typically from a custom mapper mapping nested members.
+// It returns an error for a nil context.
+func MapWithContext[TDest any](ctx *MappingContext, src any) (TDest, error) {
+	var dest TDest
+	if ctx.Mapper() == nil {
+		return dest, &MappingError{
+			Message:  "no mapping context",
+			Code:     CodeInvalidArgument,
+			DestType: reflect.TypeOf((*TDest)(nil)).Elem(),
+		}
+	}
+	if err := ctx.mapper.checkNilSource(src, reflect.TypeOf((*TDest)(nil)).Elem()); err != nil {
+		return dest, err
+	}
+
+	err := ctx.mapper.mapValue(ctx, reflect.ValueOf(src), reflect.ValueOf(&dest).Elem())
+	if err != nil {
+		return dest, err
+	}
+
+	return dest, nil
+}
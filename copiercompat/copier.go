@@ -0,0 +1,22 @@
+// Package copiercompat provides the Copy function of github.com/jinzhu/copier
+// on top of automapper, so projects can migrate call sites incrementally
+// while gaining its reflection caching and registered type maps.
+package copiercompat
+
+import automapper "github.com/csmart-libs/go-automapper"
+
+// defaultMapper backs Copy. Fields are matched by name as copier does.
+var defaultMapper = automapper.New()
+
+// Copy copies fromValue into the value toValue points to, matching fields
+// by name. Like copier.Copy, the destination comes first and slices copy
+// element-wise into slices of another element type.
+func Copy(toValue any, fromValue any) error {
+	return CopyWith(defaultMapper, toValue, fromValue)
+}
+
+// CopyWith is Copy using m, so type maps and converters registered on m
+// apply to the copy.
+func CopyWith(m *automapper.Mapper, toValue any, fromValue any) error {
+	return automapper.MapToAny(m, fromValue, toValue)
+}
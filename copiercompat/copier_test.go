@@ -0,0 +1,36 @@
+package copiercompat
+
+import "testing"
+
+type user struct {
+	Name string
+	Age  int
+	Role string
+}
+
+type employee struct {
+	Name string
+	Age  int
+}
+
+func TestCopy(t *testing.T) {
+	var e employee
+	if err := Copy(&e, user{Name: "John", Age: 30, Role: "admin"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Name != "John" || e.Age != 30 {
+		t.Errorf("unexpected copy: %+v", e)
+	}
+
+	var employees []employee
+	if err := Copy(&employees, []user{{Name: "A"}, {Name: "B"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(employees) != 2 || employees[1].Name != "B" {
+		t.Errorf("unexpected slice copy: %+v", employees)
+	}
+
+	if err := Copy(e, user{}); err == nil {
+		t.Error("expected error for non-pointer destination")
+	}
+}
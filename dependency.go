@@ -0,0 +1,67 @@
+package automapper
+
+// After declares that a member depends on other destination members: it is
+// mapped once they are, so its resolver sees their mapped values in the
+// destination it receives.
+//
+//	ForMemberByName("Summary", automapper.After("Name", "Total"),
+//	    automapper.MapFromFunc(func(src, dest any) (any, error) {
+//	        d := dest.(OrderDTO)
+//	        return fmt.Sprintf("%s: %s", d.Name, d.Total), nil
+//	    }))
+func After(members ...string) MemberOption {
+	return func(mm *MemberMap) {
+		mm.after = append(mm.after[:len(mm.after):len(mm.after)], members...)
+	}
+}
+
+// orderMemberDependencies reorders the member maps so every member follows
+// the members it declared with After, keeping the configured order
+// otherwise. Members in a dependency cycle keep their relative order at the
+// end and are reported by ValidateConfiguration.
+func (tm *TypeMap) orderMemberDependencies() {
+	hasDependencies := false
+	for _, mm := range tm.memberMaps {
+		hasDependencies = hasDependencies || len(mm.after) > 0
+	}
+	if !hasDependencies {
+		return
+	}
+
+	mapped := make(map[string]bool, len(tm.memberMaps))
+	for _, mm := range tm.memberMaps {
+		mapped[mm.destField] = true
+	}
+
+	ordered := make([]*MemberMap, 0, len(tm.memberMaps))
+	placed := make(map[string]bool, len(tm.memberMaps))
+	pending := append([]*MemberMap(nil), tm.memberMaps...)
+	for len(pending) > 0 {
+		next := -1
+		for i, mm := range pending {
+			if dependenciesPlaced(mm, mapped, placed) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			ordered = append(ordered, pending...)
+			break
+		}
+		ordered = append(ordered, pending[next])
+		placed[pending[next].destField] = true
+		pending = append(pending[:next], pending[next+1:]...)
+	}
+	tm.memberMaps = ordered
+}
+
+// dependenciesPlaced reports whether every mapped member mm depends on has
+// been placed.
+func dependenciesPlaced(mm *MemberMap, mapped, placed map[string]bool) bool {
+	for _, dep := range mm.after {
+		if mapped[dep] && !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package automapper
+
+import "reflect"
+
+// MapReport lists the assignments a mapping would make, as recorded by
+// DryRunMap.
+type MapReport struct {
+	SrcType     string
+	DestType    string
+	Assignments []Assignment
+}
+
+// Assignment is a destination member a mapping would set. Path is the dotted
+// destination path and Source the dotted source path, empty for members
+// produced by resolvers. Nested structs are reported member by member;
+// collections are reported whole.
+type Assignment struct {
+	Path   string
+	Source string
+	Op     Op
+	Value  any
+}
+
+// DryRunMap runs the mapping of src into TDest and reports the members it
+// would assign, with their resolved and converted values, instead of
+// returning the destination. Resolvers and hooks run as they would for Map,
+// so approval tooling sees exactly what a real mapping would write.
+func DryRunMap[TDest any](m *Mapper, src any) (*MapReport, error) {
+	destType := reflect.TypeOf((*TDest)(nil)).Elem()
+	if err := m.checkNilSource(src, destType); err != nil {
+		return nil, err
+	}
+
+	ctx := m.newMappingContext(src, destType, nil)
+	ctx.report = &MapReport{
+		SrcType:  ctx.rootKey.srcType.String(),
+		DestType: ctx.rootKey.destType.String(),
+	}
+	if isNilSource(src) {
+		return ctx.report, nil
+	}
+
+	var scratch TDest
+	if err := m.mapValue(ctx, reflect.ValueOf(src), reflect.ValueOf(&scratch).Elem()); err != nil {
+		return nil, err
+	}
+	return ctx.report, nil
+}
+
+// recording reports whether the context belongs to a DryRunMap call.
+func (c *MappingContext) recording() bool {
+	return c != nil && c.report != nil
+}
+
+// recordAssignment adds the member just assigned by ins to the dry-run
+// report. Assignments recorded since start belong to the members of a
+// nested struct and are prefixed with the member's name instead.
+func (m *Mapper) recordAssignment(ctx *MappingContext, start int, ins *instruction, destField reflect.Value) {
+	if !ctx.recording() {
+		return
+	}
+	report := ctx.report
+	name := ins.member.destField
+
+	switch destField.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		report.Assignments = report.Assignments[:start]
+	default:
+		if len(report.Assignments) > start {
+			for i := start; i < len(report.Assignments); i++ {
+				report.Assignments[i].Path = name + "." + report.Assignments[i].Path
+			}
+			return
+		}
+	}
+
+	assignment := Assignment{Path: name, Op: opNames[ins.op], Value: destField.Interface()}
+	if ins.op != opResolve {
+		assignment.Source = m.memberSourcePath(ins.member)
+	}
+	report.Assignments = append(report.Assignments, assignment)
+}
+
+// reportLen returns the number of assignments recorded so far.
+func (c *MappingContext) reportLen() int {
+	if !c.recording() {
+		return 0
+	}
+	return len(c.report.Assignments)
+}
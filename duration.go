@@ -0,0 +1,99 @@
+package automapper
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// DurationFormat selects how a type map represents time.Duration members in
+// string or integer fields.
+type DurationFormat int
+
+const (
+	// DurationNative applies the ordinary conversion rules, under which an
+	// integer holds nanoseconds (the default).
+	DurationNative DurationFormat = iota
+	// DurationString maps durations to and from strings such as "1h30m0s",
+	// parsed with time.ParseDuration. The empty string is a zero duration.
+	DurationString
+	// DurationMillis maps durations to and from integers holding
+	// milliseconds. Sub-millisecond parts are truncated.
+	DurationMillis
+)
+
+// durationType is the reflect.Type of time.Duration.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// WithDurationFormat maps time.Duration members of this type map to and from
+// the representation selected by format, on either side of the map.
+func (b *TypeMapBuilder[TSrc, TDest]) WithDurationFormat(format DurationFormat) *TypeMapBuilder[TSrc, TDest] {
+	b.update(func(tm *TypeMap) {
+		tm.durations = format
+	})
+	return b
+}
+
+// isDurationPair reports whether format maps between src and dest: a
+// time.Duration and a string for DurationString, or a time.Duration and an
+// integer for DurationMillis.
+func isDurationPair(format DurationFormat, src, dest reflect.Type) bool {
+	other := dest
+	switch {
+	case src == durationType && dest == durationType:
+		return false
+	case dest == durationType:
+		other = src
+	case src != durationType:
+		return false
+	}
+
+	switch format {
+	case DurationString:
+		return other.Kind() == reflect.String
+	case DurationMillis:
+		return weakKind(other.Kind()) == reflect.Int || weakKind(other.Kind()) == reflect.Uint
+	}
+	return false
+}
+
+// assignDuration maps between a time.Duration and a string or integer
+// holding milliseconds, as selected by WithDurationFormat.
+func assignDuration(src, dest reflect.Value) error {
+	if dest.Type() == durationType {
+		var d time.Duration
+		switch weakKind(src.Kind()) {
+		case reflect.String:
+			if s := src.String(); s != "" {
+				parsed, err := time.ParseDuration(s)
+				if err != nil {
+					return err
+				}
+				d = parsed
+			}
+		case reflect.Int:
+			d = time.Duration(src.Int()) * time.Millisecond
+		default:
+			d = time.Duration(src.Uint()) * time.Millisecond
+		}
+		dest.SetInt(int64(d))
+		return nil
+	}
+
+	d := time.Duration(src.Int())
+	switch weakKind(dest.Kind()) {
+	case reflect.String:
+		dest.SetString(d.String())
+	case reflect.Int:
+		if dest.OverflowInt(d.Milliseconds()) {
+			return strconv.ErrRange
+		}
+		dest.SetInt(d.Milliseconds())
+	default:
+		if d < 0 || dest.OverflowUint(uint64(d.Milliseconds())) {
+			return strconv.ErrRange
+		}
+		dest.SetUint(uint64(d.Milliseconds()))
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// WithMapKeyTag names the struct tag, such as "json" or "mapstructure",
+// that gives the map key for a destination field when mapping from
+// map[string]any and other string-keyed maps. Untagged fields use their
+// field name.
+func WithMapKeyTag(tag string) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.mapKeyTag = tag
+	}
+}
+
+// isStringKeyedMap reports whether t is a map with string keys.
+func isStringKeyedMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+}
+
+// mapKeyFor returns the map key feeding a destination field, or false if
+// the field is excluded with a "-" tag.
+func (m *Mapper) mapKeyFor(field *fieldInfo) (string, bool) {
+	if m.config.mapKeyTag == "" {
+		return field.name, true
+	}
+	tag, ok := field.tag.Lookup(m.config.mapKeyTag)
+	if !ok {
+		return field.name, true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field.name, true
+	}
+	return name, true
+}
+
+// mapFromMap maps a string-keyed map into a struct. Each destination field
+// takes the entry with its key, matched exactly first and then ignoring case;
+// fields without an entry are left unchanged.
+func (m *Mapper) mapFromMap(ctx *MappingContext, srcVal, destVal reflect.Value) error {
+	keyType := srcVal.Type().Key()
+
+	m.config.mu.RLock()
+	tm := m.config.typeMaps[typeMapKey{srcType: srcVal.Type(), destType: destVal.Type()}]
+	m.config.mu.RUnlock()
+	weak := tm != nil && tm.weakTypes
+
+	var folded map[string]reflect.Value
+	for _, field := range m.config.typeCache.getTypeInfo(destVal.Type()).fields {
+		key, ok := m.mapKeyFor(field)
+		if !ok {
+			continue
+		}
+
+		value := srcVal.MapIndex(reflect.ValueOf(key).Convert(keyType))
+		if !value.IsValid() {
+			if folded == nil {
+				folded = make(map[string]reflect.Value, srcVal.Len())
+				iter := srcVal.MapRange()
+				for iter.Next() {
+					folded[strings.ToLower(iter.Key().String())] = iter.Value()
+				}
+			}
+			value = folded[strings.ToLower(key)]
+		}
+		if !value.IsValid() {
+			continue
+		}
+
+		destField := destVal.FieldByIndex(field.index)
+		if !destField.CanSet() {
+			continue
+		}
+		var err error
+		if elem := derefValue(value); weak && elem.IsValid() && weakConvertible(elem.Type(), destField.Type()) {
+			err = weakAssign(elem, destField)
+		} else {
+			err = m.assignValue(ctx, value, destField)
+		}
+		if err != nil {
+			return &MappingError{
+				Message:    fmt.Sprintf("error mapping key %q", key),
+				SrcType:    srcVal.Type(),
+				DestType:   destVal.Type(),
+				FieldName:  field.name,
+				InnerError: err,
+			}
+		}
+	}
+	return nil
+}
+
+// interfaceTarget returns the destination type of the single registered
+// type map from srcType whose destination, or a pointer to it, implements
+// the non-empty interface destType. Sources that implement destType
+// themselves are assigned as they are, and so are values assigned to empty
+// interfaces such as any.
+func (m *Mapper) interfaceTarget(srcType, destType reflect.Type) (reflect.Type, bool) {
+	if destType.NumMethod() == 0 || srcType.Implements(destType) || reflect.PtrTo(srcType).Implements(destType) {
+		return nil, false
+	}
+
+	m.config.mu.RLock()
+	defer m.config.mu.RUnlock()
+
+	var target reflect.Type
+	for _, candidate := range m.config.bySource[srcType] {
+		if !candidate.Implements(destType) {
+			candidate = reflect.PtrTo(candidate)
+			if !candidate.Implements(destType) {
+				continue
+			}
+		}
+		if target != nil {
+			// Ambiguous: several registered destinations fit
+			return nil, false
+		}
+		target = candidate
+	}
+	return target, target != nil
+}
+
+// assignToInterface maps a struct held by an interface, or assigned to one,
+// through the registered type map for its concrete type when a non-empty
+// interface destination, which the struct does not implement, admits
+// exactly one registered destination, and a string-keyed
+// map into a non-empty interface through its discriminator. It reports false
+// if no such map exists.
+func (m *Mapper) assignToInterface(ctx *MappingContext, srcVal, destVal reflect.Value) (bool, error) {
+	destType := destVal.Type()
+	if destType.Kind() != reflect.Interface {
+		return false, nil
+	}
+	if isStringKeyedMap(srcVal.Type()) && destType.NumMethod() > 0 {
+		return m.assignDiscriminated(ctx, srcVal, destVal)
+	}
+	if srcVal.Kind() != reflect.Struct {
+		return false, nil
+	}
+	target, ok := m.interfaceTarget(srcVal.Type(), destType)
+	if !ok {
+		return false, nil
+	}
+
+	result := reflect.New(derefType(target))
+	start := ctx.pendingFinalizers()
+	if err := m.mapStruct(ctx, srcVal, result.Elem(), srcVal.Type(), result.Elem().Type()); err != nil {
+		return true, err
+	}
+	if target.Kind() == reflect.Ptr {
+		destVal.Set(result)
+	} else {
+		if err := ctx.finalizeDetached(start); err != nil {
+			return true, err
+		}
+		destVal.Set(result.Elem())
+	}
+	return true, nil
+}
+
+// assignDiscriminated maps a string-keyed map into the interface destVal
+// through the destination registered for its discriminator, if that
+// destination, or a pointer to it, implements the interface.
+func (m *Mapper) assignDiscriminated(ctx *MappingContext, srcVal, destVal reflect.Value) (bool, error) {
+	target := m.discriminatedTarget(srcVal)
+	if target == nil || target.Kind() != reflect.Struct {
+		return false, nil
+	}
+
+	result := reflect.New(target)
+	start := ctx.pendingFinalizers()
+	if err := m.mapFromMap(ctx, srcVal, result.Elem()); err != nil {
+		return true, err
+	}
+	switch destType := destVal.Type(); {
+	case target.Implements(destType):
+		if err := ctx.finalizeDetached(start); err != nil {
+			return true, err
+		}
+		destVal.Set(result.Elem())
+	case result.Type().Implements(destType):
+		destVal.Set(result)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
@@ -1,20 +1,37 @@
 package automapper
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"unsafe"
 )
 
+// ErrMapSealed is wrapped by the error TypeMapBuilder.Err returns when a
+// sealed type map is configured.
+var ErrMapSealed = errors.New("type map is sealed")
+
 // MappingError represents an error that occurred during mapping.
+// Errors raised by a member's resolver, converters or transforms carry the
+// member's Path from the outermost destination, e.g. "Orders[2].Total", and
+// with WithErrorSourceSnapshot a Source snapshot of the struct being mapped.
+// Code classifies the failure; see ErrorCode.
 type MappingError struct {
+	Code       ErrorCode
 	Message    string
 	SrcType    reflect.Type
 	DestType   reflect.Type
 	FieldName  string
+	Path       string
+	Source     any
 	InnerError error
 }
 
 func (e *MappingError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("mapping error for field '%s' (%v -> %v): %s",
+			e.Path, e.SrcType, e.DestType, e.Message)
+	}
 	if e.FieldName != "" {
 		return fmt.Sprintf("mapping error for field '%s' (%v -> %v): %s",
 			e.FieldName, e.SrcType, e.DestType, e.Message)
@@ -30,22 +47,57 @@ func (e *MappingError) Unwrap() error {
 }
 
 // Map performs mapping from source to a new destination instance.
+// Slice and map destinations, such as Map[[]UserDTO](m, users), are mapped
+// element-wise with the semantics of MapSlice and MapMap.
 func Map[TDest any](m *Mapper, src any) (TDest, error) {
 	var dest TDest
-	destVal := reflect.ValueOf(&dest).Elem()
+	if err := m.checkNilSource(src, reflect.TypeOf((*TDest)(nil)).Elem()); err != nil {
+		return dest, err
+	}
 
-	err := m.mapValue(reflect.ValueOf(src), destVal)
+	// Slices and maps are mapped element-wise, as by MapSlice and MapMap
+	if kind := reflect.TypeOf((*TDest)(nil)).Elem().Kind(); kind == reflect.Slice || kind == reflect.Map {
+		return mapCollection[TDest](m, src)
+	}
+
+	// Primitive-only maps are copied straight into dest so it never escapes
+	if m.fastMap(src, unsafe.Pointer(&dest), reflect.TypeOf((*TDest)(nil)).Elem()) {
+		return dest, nil
+	}
+
+	destPtr := new(TDest)
+	err := m.mapValue(nil, reflect.ValueOf(src), reflect.ValueOf(destPtr).Elem())
 	if err != nil {
-		return dest, err
+		return *destPtr, err
 	}
 
-	return dest, nil
+	return *destPtr, nil
 }
 
 // MapTo performs mapping from source to an existing destination instance.
 func MapTo[TDest any](m *Mapper, src any, dest *TDest) error {
 	destVal := reflect.ValueOf(dest).Elem()
-	return m.mapValue(reflect.ValueOf(src), destVal)
+	if err := m.checkNilSource(src, destVal.Type()); err != nil {
+		return err
+	}
+	return m.mapValue(nil, reflect.ValueOf(src), destVal)
+}
+
+// MapToAny maps src into the value dest points to. It is the non-generic
+// form of MapTo for callers that only know the destination type at run time.
+func MapToAny(m *Mapper, src any, dest any) error {
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.IsNil() {
+		return &MappingError{
+			Message:  "destination must be a non-nil pointer",
+			Code:     CodeInvalidArgument,
+			DestType: reflect.TypeOf(dest),
+		}
+	}
+	if err := m.checkNilSource(src, destVal.Type().Elem()); err != nil {
+		return err
+	}
+	return m.mapValue(nil, reflect.ValueOf(src), destVal.Elem())
 }
 
 // MapSlice maps a slice of source objects to a slice of destination objects.
@@ -57,6 +109,13 @@ func MapSlice[TSrc, TDest any](m *Mapper, src []TSrc) ([]TDest, error) {
 		return []TDest{}, nil
 	}
 
+	if tm := m.batchTypeMap(reflect.TypeOf((*TSrc)(nil)).Elem(), reflect.TypeOf((*TDest)(nil)).Elem()); tm != nil {
+		return mapSliceBatched[TSrc, TDest](m, tm, src)
+	}
+	if m.config.interning || m.config.arena {
+		return mapSliceShared[TSrc, TDest](m, src)
+	}
+
 	result := make([]TDest, len(src))
 	for i, s := range src {
 		dest, err := Map[TDest](m, s)
@@ -71,13 +130,212 @@ func MapSlice[TSrc, TDest any](m *Mapper, src []TSrc) ([]TDest, error) {
 	return result, nil
 }
 
+// MapSliceToAppend maps src and appends the results to *dest, so mapped
+// results can be accumulated across batches. On error *dest is unchanged.
+func MapSliceToAppend[TSrc, TDest any](m *Mapper, src []TSrc, dest *[]TDest) error {
+	mapped, err := MapSlice[TSrc, TDest](m, src)
+	if err != nil {
+		return err
+	}
+	*dest = append(*dest, mapped...)
+	return nil
+}
+
+// MapSliceToReplace maps src and replaces *dest with the results. On error
+// *dest is unchanged.
+func MapSliceToReplace[TSrc, TDest any](m *Mapper, src []TSrc, dest *[]TDest) error {
+	mapped, err := MapSlice[TSrc, TDest](m, src)
+	if err != nil {
+		return err
+	}
+	*dest = mapped
+	return nil
+}
+
+// mapSliceShared maps the elements of src within a single context, so they
+// share the context's interned strings and arena.
+func mapSliceShared[TSrc, TDest any](m *Mapper, src []TSrc) ([]TDest, error) {
+	ctx := &MappingContext{mapper: m}
+	destType := reflect.TypeOf((*TDest)(nil)).Elem()
+
+	result := make([]TDest, len(src))
+	for i, s := range src {
+		err := m.checkNilSource(s, destType)
+		if err == nil {
+			err = m.mapValue(ctx, reflect.ValueOf(s), reflect.ValueOf(&result[i]).Elem())
+		}
+		if err != nil {
+			return nil, &MappingError{
+				Message:    fmt.Sprintf("error mapping element at index %d", i),
+				InnerError: err,
+			}
+		}
+	}
+	return result, nil
+}
+
+// MapMap maps a map of source values to a map of destination values,
+// using registered type maps for the values.
+func MapMap[K comparable, VSrc, VDest any](m *Mapper, src map[K]VSrc) (map[K]VDest, error) {
+	if src == nil {
+		if m.config.allowNilColl {
+			return nil, nil
+		}
+		return map[K]VDest{}, nil
+	}
+
+	var result map[K]VDest
+	if m.copiesMapValues(reflect.TypeOf(src), reflect.TypeOf(result), reflect.ValueOf(src), reflect.ValueOf(&result).Elem()) {
+		return result, nil
+	}
+
+	result = make(map[K]VDest, len(src))
+	for k, s := range src {
+		dest, err := Map[VDest](m, s)
+		if err != nil {
+			return nil, &MappingError{
+				Message:    fmt.Sprintf("error mapping map value for key %v", k),
+				InnerError: err,
+			}
+		}
+		result[k] = dest
+	}
+	return result, nil
+}
+
+// mapCollection maps src into a new slice or map destination of Map.
+func mapCollection[TDest any](m *Mapper, src any) (TDest, error) {
+	destPtr := new(TDest)
+	err := m.mapTopLevel(reflect.ValueOf(src), reflect.ValueOf(destPtr).Elem())
+	return *destPtr, err
+}
+
+// mapTopLevel maps a source collection into the slice or map destination
+// of a Map call element by element, like MapSlice and MapMap: nil sources
+// follow WithAllowNullCollections, slice elements share batch resolver
+// results, interned strings and arenas, and errors name the failing index
+// or key. Other sources and registered converters go through mapValue.
+func (m *Mapper) mapTopLevel(srcVal, destVal reflect.Value) error {
+	srcVal = derefValue(srcVal)
+	if !srcVal.IsValid() {
+		return nil
+	}
+	srcType, destType := srcVal.Type(), destVal.Type()
+
+	m.config.mu.RLock()
+	_, hasConverter := m.config.converters[typeMapKey{srcType: srcType, destType: destType}]
+	m.config.mu.RUnlock()
+
+	switch {
+	case hasConverter:
+	case destType.Kind() == reflect.Slice && isSequenceKind(srcType.Kind()):
+		return m.mapSliceElements(srcVal, destVal)
+	case destType.Kind() == reflect.Map && srcType.Kind() == reflect.Map &&
+		srcType.Key().AssignableTo(destType.Key()):
+		return m.mapMapValues(srcVal, destVal)
+	}
+	return m.mapValue(nil, srcVal, destVal)
+}
+
+// mapSliceElements maps a source slice or array into a slice as MapSlice
+// does.
+func (m *Mapper) mapSliceElements(srcVal, destVal reflect.Value) error {
+	destType := destVal.Type()
+	if srcVal.Kind() == reflect.Slice && srcVal.IsNil() {
+		if m.config.allowNilColl {
+			destVal.Set(reflect.Zero(destType))
+		} else {
+			destVal.Set(reflect.MakeSlice(destType, 0, 0))
+		}
+		return nil
+	}
+
+	n := srcVal.Len()
+	var ctx *MappingContext
+	if tm := m.batchTypeMap(srcVal.Type().Elem(), destType.Elem()); tm != nil {
+		srcs := make([]any, n)
+		for i := range srcs {
+			if v := derefValue(srcVal.Index(i)); v.IsValid() {
+				srcs[i] = v.Interface()
+			}
+		}
+		var err error
+		if ctx, err = m.batchContext(tm, srcs); err != nil {
+			return err
+		}
+	} else if m.config.interning || m.config.arena {
+		ctx = &MappingContext{mapper: m}
+	}
+
+	result := reflect.MakeSlice(destType, n, n)
+	for i := 0; i < n; i++ {
+		if ctx != nil && ctx.batch != nil {
+			ctx.batch.index = i
+		}
+		elem := srcVal.Index(i)
+		err := m.checkNilSource(elem.Interface(), destType.Elem())
+		if err == nil {
+			err = m.mapValue(ctx, elem, result.Index(i))
+		}
+		if err != nil {
+			return &MappingError{
+				Message:    fmt.Sprintf("error mapping element at index %d", i),
+				InnerError: err,
+			}
+		}
+	}
+	destVal.Set(result)
+	return nil
+}
+
+// mapMapValues maps the values of a source map into a map with the same
+// keys as MapMap does.
+func (m *Mapper) mapMapValues(srcVal, destVal reflect.Value) error {
+	destType := destVal.Type()
+	if srcVal.IsNil() {
+		if m.config.allowNilColl {
+			destVal.Set(reflect.Zero(destType))
+		} else {
+			destVal.Set(reflect.MakeMap(destType))
+		}
+		return nil
+	}
+
+	if m.copiesMapValues(srcVal.Type(), destType, srcVal, destVal) {
+		return nil
+	}
+
+	result := reflect.MakeMapWithSize(destType, srcVal.Len())
+	iter := srcVal.MapRange()
+	for iter.Next() {
+		value := reflect.New(destType.Elem()).Elem()
+		err := m.checkNilSource(iter.Value().Interface(), destType.Elem())
+		if err == nil {
+			err = m.mapValue(nil, iter.Value(), value)
+		}
+		if err != nil {
+			return &MappingError{
+				Message:    fmt.Sprintf("error mapping map value for key %v", iter.Key().Interface()),
+				InnerError: err,
+			}
+		}
+		result.SetMapIndex(iter.Key(), value)
+	}
+	destVal.Set(result)
+	return nil
+}
+
 // mapValue is the core mapping function that handles all type mappings.
-func (m *Mapper) mapValue(srcVal, destVal reflect.Value) error {
+func (m *Mapper) mapValue(ctx *MappingContext, srcVal, destVal reflect.Value) error {
 	// Handle nil source
 	if !srcVal.IsValid() {
 		return nil
 	}
 
+	if shared, err := m.mapSharedReference(ctx, srcVal, destVal); shared {
+		return err
+	}
+
 	// Dereference pointers
 	srcVal = derefValue(srcVal)
 	if !srcVal.IsValid() {
@@ -88,7 +346,7 @@ func (m *Mapper) mapValue(srcVal, destVal reflect.Value) error {
 	destType := destVal.Type()
 	if destType.Kind() == reflect.Ptr {
 		if destVal.IsNil() {
-			destVal.Set(reflect.New(destType.Elem()))
+			destVal.Set(m.newValue(ctx, destType.Elem()))
 		}
 		destVal = destVal.Elem()
 		destType = destType.Elem()
@@ -110,25 +368,37 @@ func (m *Mapper) mapValue(srcVal, destVal reflect.Value) error {
 	}
 
 	// Handle different kinds
-	switch srcType.Kind() {
-	case reflect.Struct:
-		return m.mapStruct(srcVal, destVal, srcType, destType)
-	case reflect.Slice, reflect.Array:
-		return m.mapSlice(srcVal, destVal, srcType, destType)
-	case reflect.Map:
-		return m.mapMap(srcVal, destVal, srcType, destType)
+	switch srcKind, destKind := srcType.Kind(), destType.Kind(); {
+	case srcKind == reflect.Struct && destKind == reflect.Struct:
+		return m.mapStruct(ctx, srcVal, destVal, srcType, destType)
+	case isSequenceKind(srcKind) && isSequenceKind(destKind):
+		return m.mapSlice(ctx, srcVal, destVal, srcType, destType)
+	case srcKind == reflect.Map && destKind == reflect.Map:
+		return m.mapMap(ctx, srcVal, destVal, srcType, destType)
+	case isStringKeyedMap(srcType) && destKind == reflect.Struct:
+		return m.mapFromMap(ctx, srcVal, destVal)
 	default:
+		if dispatched, err := m.assignToInterface(ctx, srcVal, destVal); dispatched {
+			return err
+		}
+		if assigned, err := m.assignJSONSafe(srcVal, destVal); assigned {
+			return err
+		}
+		if assigned, err := m.assignInterface(srcVal, destVal); assigned {
+			return err
+		}
 		// Direct assignment for compatible types
 		if srcType.AssignableTo(destType) {
 			destVal.Set(srcVal)
 			return nil
 		}
-		if srcType.ConvertibleTo(destType) {
+		if m.convertible(srcType, destType) {
 			destVal.Set(srcVal.Convert(destType))
 			return nil
 		}
 		return &MappingError{
 			Message:  "incompatible types",
+			Code:     CodeIncompatibleTypes,
 			SrcType:  srcType,
 			DestType: destType,
 		}
@@ -136,7 +406,60 @@ func (m *Mapper) mapValue(srcVal, destVal reflect.Value) error {
 }
 
 // mapStruct maps a struct from source to destination.
-func (m *Mapper) mapStruct(srcVal, destVal reflect.Value, srcType, destType reflect.Type) error {
+func (m *Mapper) mapStruct(ctx *MappingContext, srcVal, destVal reflect.Value, srcType, destType reflect.Type) error {
+	if m.config.tracksStructDepth() {
+		if ctx == nil {
+			ctx = &MappingContext{mapper: m}
+		}
+		if ctx.depth == 0 {
+			return m.mapOutermostStruct(ctx, srcVal, destVal, srcType, destType)
+		}
+		ctx.depth++
+		defer func() { ctx.depth-- }()
+	}
+	return m.mapStructAtDepth(ctx, srcVal, destVal, srcType, destType)
+}
+
+// tracksStructDepth reports whether mappings must track how deeply structs
+// are nested, for finalizers, collected hook errors, batch resolvers,
+// recursive types, string interning or WithoutNestedHooks.
+func (c *MapperConfiguration) tracksStructDepth() bool {
+	return c.noNestedHook || c.hasFinalizers.Load() || c.hasHookCollect.Load() || c.hasBatches.Load() ||
+		c.hasRecursive.Load() || c.interning
+}
+
+// mapOutermostStruct maps the outermost struct of a call. Finalizers queued
+// by it and its nested members run afterwards, innermost first, and hook
+// errors collected along the way are returned together.
+func (m *Mapper) mapOutermostStruct(ctx *MappingContext, srcVal, destVal reflect.Value, srcType, destType reflect.Type) error {
+	ctx.depth = 1
+	defer func() {
+		ctx.depth = 0
+		ctx.finalizers = nil
+		ctx.hookErrors = nil
+	}()
+
+	if err := m.mapStructAtDepth(ctx, srcVal, destVal, srcType, destType); err != nil {
+		return err
+	}
+	for _, f := range ctx.finalizers {
+		if err := f.fn(f.dest.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	return errors.Join(ctx.hookErrors...)
+}
+
+// mapStructAtDepth maps a struct once its nesting depth is accounted for.
+func (m *Mapper) mapStructAtDepth(ctx *MappingContext, srcVal, destVal reflect.Value, srcType, destType reflect.Type) error {
+	if ctx != nil && ctx.memo != nil {
+		return m.mapStructMemoized(ctx, srcVal, destVal, srcType, destType)
+	}
+	return m.mapStructUncached(ctx, srcVal, destVal, srcType, destType)
+}
+
+// mapStructUncached maps a struct through its type map.
+func (m *Mapper) mapStructUncached(ctx *MappingContext, srcVal, destVal reflect.Value, srcType, destType reflect.Type) error {
 	key := typeMapKey{srcType: srcType, destType: destType}
 
 	m.config.mu.RLock()
@@ -146,43 +469,81 @@ func (m *Mapper) mapStruct(srcVal, destVal reflect.Value, srcType, destType refl
 	m.config.mu.RUnlock()
 
 	if !exists {
+		if m.config.strictMaps {
+			return &MappingError{
+				Message:  "no type map registered",
+				Code:     CodeNoTypeMap,
+				SrcType:  srcType,
+				DestType: destType,
+			}
+		}
 		// Auto-create mapping if not exists
-		typeMap = m.autoCreateTypeMap(srcType, destType)
+		var err error
+		if typeMap, err = m.autoCreateTypeMap(srcType, destType); err != nil {
+			return err
+		}
 	}
-
-	// Use optimized path if available and optimization is enabled
-	if optLevel > OptimizationNone && optMap != nil && optMap.compiled {
-		return m.mapStructOptimized(srcVal, destVal, optMap)
+	if err := m.checkDepth(ctx, typeMap); err != nil {
+		return err
+	}
+	if typeMap.compileErr != nil {
+		return typeMap.compileErr
+	}
+	if err := m.checkUnsettable(typeMap); err != nil {
+		return err
+	}
+	m.markUsed(typeMap)
+
+	// Use optimized path if available and optimization is enabled; type maps
+	// whose configuration requires the plan and calls with per-call options
+	// stay on the standard path
+	var err error
+	if optLevel > OptimizationNone && optMap != nil && optMap.compiled && !optMap.standardOnly &&
+		!ctx.needsStandardPath(key) {
+		if m.config.verifyOpt {
+			err = m.mapStructVerified(ctx, srcVal, destVal, optMap)
+		} else {
+			err = m.mapStructOptimized(ctx, srcVal, destVal, optMap)
+		}
+	} else {
+		// Standard mapping path
+		err = m.mapStructStandard(ctx, srcVal, destVal, typeMap)
 	}
 
-	// Standard mapping path
-	return m.mapStructStandard(srcVal, destVal, typeMap)
+	if err == nil && len(typeMap.finalizers) > 0 {
+		ctx.queueFinalizers(typeMap, destVal)
+	}
+	return err
 }
 
 // mapStructStandard performs standard reflection-based struct mapping.
-func (m *Mapper) mapStructStandard(srcVal, destVal reflect.Value, typeMap *TypeMap) error {
-	// Execute before map functions
-	for _, beforeFn := range typeMap.beforeMap {
-		if err := beforeFn(srcVal.Interface(), destVal.Addr().Interface()); err != nil {
+func (m *Mapper) mapStructStandard(ctx *MappingContext, srcVal, destVal reflect.Value, typeMap *TypeMap) error {
+	hooksEnabled := m.runsHooks(ctx)
+	memoKey := ctx.takeMemoKey()
+
+	// Execute before map functions on a working copy of the source
+	if hooksEnabled && len(typeMap.beforeMap) > 0 {
+		srcVal = sourceWorkingCopy(srcVal)
+		if err := m.runHooks(ctx, typeMap, typeMap.beforeMap, srcVal.Addr().Interface(), destVal.Addr().Interface()); err != nil {
 			return err
 		}
 	}
 
 	// Use custom mapper if defined
 	if typeMap.customMapper != nil {
-		return typeMap.customMapper(srcVal.Interface(), destVal.Addr().Interface())
+		return typeMap.customMapper(ctx, srcVal.Interface(), destVal.Addr().Interface())
 	}
 
-	// Map each member
-	for _, mm := range typeMap.memberMaps {
-		if err := m.mapMember(srcVal, destVal, mm); err != nil {
-			return err
-		}
+	// Map each member using the compiled plan
+	if err := m.executePlan(ctx, srcVal, destVal, m.planForContext(ctx, typeMap)); err != nil {
+		return err
 	}
+	m.wireBackReferences(destVal)
+	ctx.memoizeMapped(memoKey, destVal)
 
 	// Execute after map functions
-	for _, afterFn := range typeMap.afterMap {
-		if err := afterFn(srcVal.Interface(), destVal.Addr().Interface()); err != nil {
+	if hooksEnabled && len(typeMap.afterMap) > 0 {
+		if err := m.runHooks(ctx, typeMap, typeMap.afterMap, srcVal.Interface(), destVal.Addr().Interface()); err != nil {
 			return err
 		}
 	}
@@ -190,35 +551,57 @@ func (m *Mapper) mapStructStandard(srcVal, destVal reflect.Value, typeMap *TypeM
 	return nil
 }
 
-// mapMember maps a single member from source to destination.
-func (m *Mapper) mapMember(srcVal, destVal reflect.Value, mm *MemberMap) error {
-	// Check if ignored
-	if mm.ignore {
-		return nil
-	}
+// runsHooks reports whether Before/AfterMap hooks run for the struct being
+// mapped: always, unless WithoutNestedHooks limits them to the outermost one.
+func (m *Mapper) runsHooks(ctx *MappingContext) bool {
+	return !m.config.noNestedHook || ctx.depth <= 1
+}
 
-	// Check condition
-	if mm.condition != nil && !mm.condition(srcVal.Interface()) {
-		return nil
-	}
+// sourceWorkingCopy returns an addressable shallow copy of srcVal. BeforeMap
+// hooks receive a pointer to it and members are mapped from it, so hook
+// mutations are seen by the mapping without changing the caller's source.
+func sourceWorkingCopy(srcVal reflect.Value) reflect.Value {
+	work := reflect.New(srcVal.Type()).Elem()
+	work.Set(srcVal)
+	return work
+}
 
+// assignMember maps a single member whose ignore and condition checks passed.
+func (m *Mapper) assignMember(ctx *MappingContext, srcVal, destVal reflect.Value, mm *MemberMap) error {
 	// Get destination field
 	destField := destVal.FieldByIndex(mm.destFieldIdx)
 	if !destField.CanSet() {
+		if m.config.unsettable {
+			return unsettableError(srcVal.Type(), destVal.Type(), mm.destField)
+		}
 		return nil
 	}
 
+	return m.resolveMember(ctx, srcVal, destVal, destField, mm)
+}
+
+// resolveMember obtains the member's source value through its resolver or
+// source field, applies its converter and assigns the result to destField.
+func (m *Mapper) resolveMember(ctx *MappingContext, srcVal, destVal, destField reflect.Value, mm *MemberMap) error {
 	var srcValue reflect.Value
 
 	// Use value resolver if defined
-	if mm.resolver != nil {
-		result, err := mm.resolver(srcVal.Interface(), destVal.Interface())
+	if mm.resolver != nil || mm.batch != nil {
+		result, err := callResolver(ctx, mm, srcVal.Interface(), destVal.Interface())
 		if err != nil {
-			return &MappingError{
+			mappingErr := &MappingError{
 				Message:    "resolver error",
+				Code:       CodeResolver,
+				SrcType:    srcVal.Type(),
+				DestType:   destVal.Type(),
 				FieldName:  mm.destField,
+				Path:       mm.destField,
 				InnerError: err,
 			}
+			if m.config.errSnapshot {
+				mappingErr.Source = sourceSnapshot(srcVal, m.config.redactionTag)
+			}
+			return mappingErr
 		}
 		srcValue = reflect.ValueOf(result)
 	} else if len(mm.srcFieldIdx) > 0 {
@@ -231,7 +614,8 @@ func (m *Mapper) mapMember(srcVal, destVal reflect.Value, mm *MemberMap) error {
 		return nil
 	}
 
-	if !srcValue.IsValid() {
+	if !srcValue.IsValid() || (mm.valueCond != nil && !mm.valueCond(srcValue, destField)) ||
+		(mm.zeroTimeNil && isZeroTime(srcValue)) {
 		return nil
 	}
 
@@ -241,19 +625,95 @@ func (m *Mapper) mapMember(srcVal, destVal reflect.Value, mm *MemberMap) error {
 		if err != nil {
 			return &MappingError{
 				Message:    "converter error",
+				Code:       CodeConversion,
 				FieldName:  mm.destField,
+				Path:       mm.destField,
 				InnerError: err,
 			}
 		}
 		srcValue = reflect.ValueOf(result)
 	}
 
+	if mm.elemConverter != nil {
+		converted, err := m.convertElements(ctx, srcValue, destField.Type(), mm.elemConverter)
+		if err != nil {
+			return &MappingError{
+				Message:    "element converter error",
+				Code:       CodeConversion,
+				FieldName:  mm.destField,
+				Path:       mm.destField,
+				InnerError: err,
+			}
+		}
+		srcValue = converted
+	}
+
+	if len(mm.transforms) > 0 {
+		transformed, err := applyTransforms(mm, srcValue, destField.Type())
+		if err != nil {
+			return &MappingError{
+				Message:    "transform error",
+				Code:       CodeTransform,
+				FieldName:  mm.destField,
+				Path:       mm.destField,
+				InnerError: err,
+			}
+		}
+		srcValue = transformed
+	}
+
+	if mm.redactor != nil {
+		srcValue = redactValue(mm, srcValue, destField.Type())
+	}
+
+	if assignEmptyMap(mm.emptyMap, srcValue, destField) || assignNilCollection(mm.nilColl, srcValue, destField) {
+		return nil
+	}
+
 	// Perform the assignment
-	return m.assignValue(srcValue, destField)
+	start := ctx.pendingFinalizers()
+	if err := m.assignValue(ctx, srcValue, destField); err != nil {
+		prependErrorPath(err, mm.destField)
+		return err
+	}
+
+	if len(mm.collectionOps) == 0 {
+		return nil
+	}
+	// Collection operations copy value elements into a new slice
+	if destField.Kind() == reflect.Slice && destField.Type().Elem().Kind() != reflect.Ptr {
+		if err := ctx.finalizeDetached(start); err != nil {
+			return err
+		}
+	}
+	for _, op := range mm.collectionOps {
+		destField.Set(op(destField))
+	}
+	return nil
+}
+
+// convertible reports whether values of srcType may be converted to
+// destType, unless WithDisableImplicitConversion is set.
+func (m *Mapper) convertible(srcType, destType reflect.Type) bool {
+	return m.config.convertible(srcType, destType)
+}
+
+// convertible is Mapper.convertible for code running at configuration time.
+func (c *MapperConfiguration) convertible(srcType, destType reflect.Type) bool {
+	return !c.noConvert && srcType.ConvertibleTo(destType)
 }
 
 // assignValue assigns a source value to a destination field.
-func (m *Mapper) assignValue(srcVal reflect.Value, destVal reflect.Value) error {
+func (m *Mapper) assignValue(ctx *MappingContext, srcVal reflect.Value, destVal reflect.Value) error {
+	if shared, err := m.mapSharedReference(ctx, srcVal, destVal); shared {
+		return err
+	}
+
+	// Errors are mapped by their interface, before it is unwrapped
+	if assigned, err := m.assignError(srcVal, destVal); assigned {
+		return err
+	}
+
 	srcVal = derefValue(srcVal)
 	if !srcVal.IsValid() {
 		return nil
@@ -267,10 +727,14 @@ func (m *Mapper) assignValue(srcVal reflect.Value, destVal reflect.Value) error
 		if !srcVal.IsValid() || (srcVal.Kind() == reflect.Ptr && srcVal.IsNil()) {
 			return nil
 		}
+		// Invalid nullable values leave the pointer nil
+		if m.assignNullable(srcVal, destVal) {
+			return nil
+		}
 		if destVal.IsNil() {
-			destVal.Set(reflect.New(destType.Elem()))
+			destVal.Set(m.newValue(ctx, destType.Elem()))
 		}
-		return m.assignValue(srcVal, destVal.Elem())
+		return m.assignValue(ctx, srcVal, destVal.Elem())
 	}
 
 	// Check for registered type converter
@@ -288,78 +752,149 @@ func (m *Mapper) assignValue(srcVal reflect.Value, destVal reflect.Value) error
 		return nil
 	}
 
+	if m.assignNullable(srcVal, destVal) {
+		return nil
+	}
+
+	if assigned, err := m.assignTime(srcVal, destVal); assigned {
+		return err
+	}
+
+	if assigned, err := assignNetwork(srcVal, destVal); assigned {
+		return err
+	}
+
+	if m.config.weakTypes && weakConvertible(srcType, destType) {
+		return weakAssign(srcVal, destVal)
+	}
+
+	if dispatched, err := m.assignToInterface(ctx, srcVal, destVal); dispatched {
+		return err
+	}
+
+	if assigned, err := m.assignJSONSafe(srcVal, destVal); assigned {
+		return err
+	}
+
+	if assigned, err := m.assignInterface(srcVal, destVal); assigned {
+		return err
+	}
+
+	// Registered struct type maps take precedence over direct assignment and
+	// conversion so their hooks and member options are applied
+	if srcType.Kind() == reflect.Struct && destType.Kind() == reflect.Struct && m.hasTypeMap(key) {
+		return m.mapStruct(ctx, srcVal, destVal, srcType, destType)
+	}
+
 	// Direct assignment
-	if srcType.AssignableTo(destType) {
+	collection := srcType.Kind() == destType.Kind() && m.mapsInterfaceElements(destType)
+	if srcType.AssignableTo(destType) && !collection {
 		destVal.Set(srcVal)
 		return nil
 	}
 
 	// Type conversion
-	if srcType.ConvertibleTo(destType) {
+	if m.convertible(srcType, destType) && !collection {
 		destVal.Set(srcVal.Convert(destType))
 		return nil
 	}
 
 	// Nested mapping for structs
 	if srcType.Kind() == reflect.Struct && destType.Kind() == reflect.Struct {
-		return m.mapValue(srcVal, destVal)
+		return m.mapValue(ctx, srcVal, destVal)
+	}
+
+	// Slice and array mapping
+	if isSequenceKind(srcType.Kind()) && isSequenceKind(destType.Kind()) {
+		return m.mapSlice(ctx, srcVal, destVal, srcType, destType)
+	}
+
+	// Map mapping
+	if srcType.Kind() == reflect.Map && destType.Kind() == reflect.Map {
+		return m.mapMap(ctx, srcVal, destVal, srcType, destType)
 	}
 
-	// Slice mapping
-	if srcType.Kind() == reflect.Slice && destType.Kind() == reflect.Slice {
-		return m.mapSlice(srcVal, destVal, srcType, destType)
+	// Dynamic maps into structs
+	if isStringKeyedMap(srcType) && destType.Kind() == reflect.Struct {
+		return m.mapFromMap(ctx, srcVal, destVal)
 	}
 
 	return &MappingError{
 		Message:  "cannot assign value",
+		Code:     CodeIncompatibleTypes,
 		SrcType:  srcType,
 		DestType: destType,
 	}
 }
 
-// mapSlice maps a slice from source to destination.
-func (m *Mapper) mapSlice(srcVal, destVal reflect.Value, _, destType reflect.Type) error {
-	if srcVal.IsNil() {
-		if m.config.allowNilColl {
+// mapSlice maps a slice or array from source to destination.
+func (m *Mapper) mapSlice(ctx *MappingContext, srcVal, destVal reflect.Value, _, destType reflect.Type) error {
+	if srcVal.Kind() == reflect.Slice && srcVal.IsNil() {
+		if m.config.allowNilColl || destType.Kind() == reflect.Array {
 			destVal.Set(reflect.Zero(destType))
 		} else {
 			destVal.Set(reflect.MakeSlice(destType, 0, 0))
 		}
 		return nil
 	}
+	if shared, ok := m.sharedSlice(srcVal, destType); ok {
+		destVal.Set(shared)
+		return nil
+	}
 
 	srcLen := srcVal.Len()
-	destSlice := reflect.MakeSlice(destType, srcLen, srcLen)
+	var destSeq reflect.Value
+	if destType.Kind() == reflect.Array {
+		if srcLen > destType.Len() {
+			return &MappingError{
+				Message:  fmt.Sprintf("source has %d elements but destination array holds %d", srcLen, destType.Len()),
+				Code:     CodeIncompatibleTypes,
+				SrcType:  srcVal.Type(),
+				DestType: destType,
+			}
+		}
+		destSeq = reflect.New(destType).Elem()
+	} else {
+		destSeq = reflect.MakeSlice(destType, srcLen, srcLen)
+	}
 	destElemType := destType.Elem()
 
 	for i := 0; i < srcLen; i++ {
 		srcElem := srcVal.Index(i)
-		destElem := destSlice.Index(i)
+		destElem := destSeq.Index(i)
 
 		if destElemType.Kind() == reflect.Ptr {
-			destElem.Set(reflect.New(destElemType.Elem()))
-			if err := m.mapValue(srcElem, destElem.Elem()); err != nil {
-				return &MappingError{
-					Message:    fmt.Sprintf("error mapping slice element at index %d", i),
-					InnerError: err,
+			if m.config.keepNilElems && !derefValue(srcElem).IsValid() {
+				continue
+			}
+			if shared, err := m.mapSharedReference(ctx, srcElem, destElem); shared {
+				if err != nil {
+					return elementError(err, i, fmt.Sprintf("error mapping slice element at index %d", i))
 				}
+				continue
+			}
+			destElem.Set(m.newValue(ctx, destElemType.Elem()))
+			if err := m.mapValue(ctx, srcElem, destElem.Elem()); err != nil {
+				return elementError(err, i, fmt.Sprintf("error mapping slice element at index %d", i))
 			}
 		} else {
-			if err := m.mapValue(srcElem, destElem); err != nil {
-				return &MappingError{
-					Message:    fmt.Sprintf("error mapping slice element at index %d", i),
-					InnerError: err,
-				}
+			if err := m.mapValue(ctx, srcElem, destElem); err != nil {
+				return elementError(err, i, fmt.Sprintf("error mapping slice element at index %d", i))
 			}
 		}
 	}
 
-	destVal.Set(destSlice)
+	destVal.Set(destSeq)
 	return nil
 }
 
+// isSequenceKind reports whether k is a slice or array kind.
+func isSequenceKind(k reflect.Kind) bool {
+	return k == reflect.Slice || k == reflect.Array
+}
+
 // mapMap maps a map from source to destination.
-func (m *Mapper) mapMap(srcVal, destVal reflect.Value, _, destType reflect.Type) error {
+func (m *Mapper) mapMap(ctx *MappingContext, srcVal, destVal reflect.Value, _, destType reflect.Type) error {
 	if srcVal.IsNil() {
 		if m.config.allowNilColl {
 			destVal.Set(reflect.Zero(destType))
@@ -369,6 +904,10 @@ func (m *Mapper) mapMap(srcVal, destVal reflect.Value, _, destType reflect.Type)
 		return nil
 	}
 
+	if copier := m.mapCopierFor(srcVal.Type(), destType); copier != nil && copier(srcVal, destVal) {
+		return nil
+	}
+
 	destMap := reflect.MakeMapWithSize(destType, srcVal.Len())
 	destKeyType := destType.Key()
 	destValType := destType.Elem()
@@ -382,11 +921,12 @@ func (m *Mapper) mapMap(srcVal, destVal reflect.Value, _, destType reflect.Type)
 		destKey := reflect.New(destKeyType).Elem()
 		if srcKey.Type().AssignableTo(destKeyType) {
 			destKey.Set(srcKey)
-		} else if srcKey.Type().ConvertibleTo(destKeyType) {
+		} else if m.convertible(srcKey.Type(), destKeyType) {
 			destKey.Set(srcKey.Convert(destKeyType))
 		} else {
 			return &MappingError{
 				Message:  "cannot convert map key",
+				Code:     CodeIncompatibleTypes,
 				SrcType:  srcKey.Type(),
 				DestType: destKeyType,
 			}
@@ -394,8 +934,13 @@ func (m *Mapper) mapMap(srcVal, destVal reflect.Value, _, destType reflect.Type)
 
 		// Convert value
 		destMapVal := reflect.New(destValType).Elem()
-		if err := m.assignValue(srcMapVal, destMapVal); err != nil {
-			return err
+		start := ctx.pendingFinalizers()
+		err := m.assignValue(ctx, srcMapVal, destMapVal)
+		if err == nil {
+			err = ctx.finalizeDetached(start)
+		}
+		if err != nil {
+			return elementError(err, srcKey.Interface(), fmt.Sprintf("error mapping map value for key %v", srcKey.Interface()))
 		}
 
 		destMap.SetMapIndex(destKey, destMapVal)
@@ -405,8 +950,119 @@ func (m *Mapper) mapMap(srcVal, destVal reflect.Value, _, destType reflect.Type)
 	return nil
 }
 
+// convertElements builds a destType collection from a source slice, array
+// or map by applying conv to every element (map values for maps).
+func (m *Mapper) convertElements(ctx *MappingContext, srcVal reflect.Value, destType reflect.Type, conv TypeConverter) (reflect.Value, error) {
+	srcVal = derefValue(srcVal)
+	srcKind, destKind := srcVal.Kind(), destType.Kind()
+
+	switch {
+	case isSequenceKind(srcKind) && isSequenceKind(destKind):
+		if srcKind == reflect.Slice && srcVal.IsNil() && destKind == reflect.Slice {
+			if m.config.allowNilColl {
+				return reflect.Zero(destType), nil
+			}
+			return reflect.MakeSlice(destType, 0, 0), nil
+		}
+		srcLen := srcVal.Len()
+		var destSeq reflect.Value
+		if destKind == reflect.Array {
+			if srcLen > destType.Len() {
+				return reflect.Value{}, &MappingError{
+					Message:  fmt.Sprintf("source has %d elements but destination array holds %d", srcLen, destType.Len()),
+					Code:     CodeIncompatibleTypes,
+					SrcType:  srcVal.Type(),
+					DestType: destType,
+				}
+			}
+			destSeq = reflect.New(destType).Elem()
+		} else {
+			destSeq = reflect.MakeSlice(destType, srcLen, srcLen)
+		}
+		for i := 0; i < srcLen; i++ {
+			result, err := conv(srcVal.Index(i).Interface(), destType.Elem())
+			if err != nil {
+				return reflect.Value{}, &MappingError{
+					Message:    fmt.Sprintf("error converting element at index %d", i),
+					InnerError: err,
+				}
+			}
+			if err := m.assignValue(ctx, reflect.ValueOf(result), destSeq.Index(i)); err != nil {
+				return reflect.Value{}, &MappingError{
+					Message:    fmt.Sprintf("error converting element at index %d", i),
+					InnerError: err,
+				}
+			}
+		}
+		return destSeq, nil
+
+	case srcKind == reflect.Map && destKind == reflect.Map:
+		if srcVal.IsNil() {
+			if m.config.allowNilColl {
+				return reflect.Zero(destType), nil
+			}
+			return reflect.MakeMap(destType), nil
+		}
+		destMap := reflect.MakeMapWithSize(destType, srcVal.Len())
+		iter := srcVal.MapRange()
+		for iter.Next() {
+			destKey := reflect.New(destType.Key()).Elem()
+			if err := m.assignValue(ctx, iter.Key(), destKey); err != nil {
+				return reflect.Value{}, &MappingError{
+					Message:    fmt.Sprintf("error converting map value for key %v", iter.Key().Interface()),
+					InnerError: err,
+				}
+			}
+			result, err := conv(iter.Value().Interface(), destType.Elem())
+			if err != nil {
+				return reflect.Value{}, &MappingError{
+					Message:    fmt.Sprintf("error converting map value for key %v", iter.Key().Interface()),
+					InnerError: err,
+				}
+			}
+			destElem := reflect.New(destType.Elem()).Elem()
+			start := ctx.pendingFinalizers()
+			if err := m.assignValue(ctx, reflect.ValueOf(result), destElem); err == nil {
+				err = ctx.finalizeDetached(start)
+			}
+			if err != nil {
+				return reflect.Value{}, &MappingError{
+					Message:    fmt.Sprintf("error converting map value for key %v", iter.Key().Interface()),
+					InnerError: err,
+				}
+			}
+			destMap.SetMapIndex(destKey, destElem)
+		}
+		return destMap, nil
+	}
+
+	return reflect.Value{}, &MappingError{
+		Message:  "element converter requires slice, array or map members",
+		Code:     CodeIncompatibleTypes,
+		SrcType:  srcVal.Type(),
+		DestType: destType,
+	}
+}
+
+// markUsed seals a type map on its first use when WithSealOnFirstUse is set
+// and records its use for CheckConcurrency.
+func (m *Mapper) markUsed(tm *TypeMap) {
+	if m.config.sealOnUse && !tm.sealed.Load() {
+		tm.sealed.Store(true)
+	}
+	m.noteMappingStarted(tm)
+}
+
+// hasTypeMap reports whether a type map is registered for key.
+func (m *Mapper) hasTypeMap(key typeMapKey) bool {
+	m.config.mu.RLock()
+	_, ok := m.config.typeMaps[key]
+	m.config.mu.RUnlock()
+	return ok
+}
+
 // autoCreateTypeMap creates a type map automatically for unmapped types.
-func (m *Mapper) autoCreateTypeMap(srcType, destType reflect.Type) *TypeMap {
+func (m *Mapper) autoCreateTypeMap(srcType, destType reflect.Type) (*TypeMap, error) {
 	key := typeMapKey{srcType: srcType, destType: destType}
 
 	m.config.mu.Lock()
@@ -414,7 +1070,7 @@ func (m *Mapper) autoCreateTypeMap(srcType, destType reflect.Type) *TypeMap {
 
 	// Double-check after acquiring lock
 	if tm, exists := m.config.typeMaps[key]; exists {
-		return tm
+		return tm, nil
 	}
 
 	tm := &TypeMap{
@@ -422,18 +1078,21 @@ func (m *Mapper) autoCreateTypeMap(srcType, destType reflect.Type) *TypeMap {
 		destType:     destType,
 		memberMaps:   make([]*MemberMap, 0),
 		ignoreFields: make(map[string]bool),
+		autoCreated:  true,
 	}
 
-	tm.autoConfigureMembers(m.config.typeCache)
-	m.config.typeMaps[key] = tm
+	if err := m.config.autoConfigure(tm); err != nil {
+		return nil, err
+	}
+	m.config.registerTypeMap(key, tm)
 
 	// Compile optimized version if optimization is enabled
 	if m.config.optLevel > OptimizationNone {
-		optMap := compileOptimizedTypeMap(tm, m.config.optLevel)
+		optMap := m.config.compileOptimized(tm, m.config.optLevel)
 		m.config.optimizedMaps[key] = optMap
 	}
 
-	return tm
+	return tm, nil
 }
 
 // derefValue dereferences a pointer value.
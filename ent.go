@@ -0,0 +1,91 @@
+package automapper
+
+import "reflect"
+
+// EdgePolicy determines how edges of ent-generated entities that were not
+// loaded are mapped.
+type EdgePolicy int
+
+const (
+	// EdgeNotLoadedSkip leaves the destination member unset (default).
+	EdgeNotLoadedSkip EdgePolicy = iota
+	// EdgeNotLoadedEmpty maps a not-loaded edge as an empty collection, or
+	// nil for unique edges.
+	EdgeNotLoadedEmpty
+	// EdgeNotLoadedError fails the mapping.
+	EdgeNotLoadedError
+)
+
+// edgesField is the field holding an ent entity's eager-loaded edges.
+const edgesField = "Edges"
+
+// WithEdgePolicy sets how edges of ent-generated entities that were not
+// loaded are mapped.
+func WithEdgePolicy(policy EdgePolicy) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.edgePolicy = policy
+	}
+}
+
+// configureEdges maps destination members that have no source field of
+// their own from the source's ent Edges struct, so user.Edges.Posts maps to
+// UserDTO.Posts.
+func (c *MapperConfiguration) configureEdges(tm *TypeMap) {
+	edges, ok := c.typeCache.getTypeInfo(tm.srcType).fieldsByName[edgesField]
+	if !ok || edges.fieldType.Kind() != reflect.Struct {
+		return
+	}
+
+	configured := make(map[string]bool, len(tm.memberMaps))
+	for _, mm := range tm.memberMaps {
+		configured[mm.destField] = true
+	}
+
+	edgeInfo := c.typeCache.getTypeInfo(edges.fieldType)
+	for _, destField := range c.typeCache.getTypeInfo(tm.destType).fields {
+		if configured[destField.name] {
+			continue
+		}
+		edge, ok := edgeInfo.fieldsByName[destField.name]
+		if !ok {
+			continue
+		}
+		tm.memberMaps = append(tm.memberMaps, &MemberMap{
+			destField:    destField.name,
+			destFieldIdx: destField.index,
+			resolver:     c.edgeResolver(edges.index, edge.index, edge.name),
+		})
+	}
+}
+
+// edgeResolver resolves an edge of the Edges struct at edgesIdx, consulting
+// the generated <Edge>OrErr method to detect edges that were not loaded.
+func (c *MapperConfiguration) edgeResolver(edgesIdx, edgeIdx []int, name string) ValueResolver {
+	return func(src any, _ any) (any, error) {
+		edges := reflect.ValueOf(src).FieldByIndex(edgesIdx)
+		value := edges.FieldByIndex(edgeIdx)
+
+		if orErr := edges.MethodByName(name + "OrErr"); orErr.IsValid() && orErr.Type().NumOut() == 2 {
+			if err, _ := orErr.Call(nil)[1].Interface().(error); err != nil {
+				switch c.edgePolicy {
+				case EdgeNotLoadedEmpty:
+					if value.Kind() == reflect.Slice {
+						return reflect.MakeSlice(value.Type(), 0, 0).Interface(), nil
+					}
+					return nil, nil
+				case EdgeNotLoadedError:
+					return nil, &MappingError{
+						Message:    "edge not loaded",
+						Code:       CodeNotLoaded,
+						SrcType:    reflect.TypeOf(src),
+						FieldName:  name,
+						InnerError: err,
+					}
+				default:
+					return nil, nil
+				}
+			}
+		}
+		return value.Interface(), nil
+	}
+}
@@ -0,0 +1,101 @@
+package automapper
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrorCode is a machine-readable classification of a MappingError, stable
+// across releases so API layers can translate mapping failures without
+// parsing messages.
+type ErrorCode string
+
+const (
+	// CodeUnknown classifies errors without a more specific code.
+	CodeUnknown ErrorCode = "unknown"
+	// CodeNoTypeMap reports that no type map, selector, version or event
+	// is registered for the requested mapping.
+	CodeNoTypeMap ErrorCode = "no_type_map"
+	// CodeIncompatibleTypes reports a source value that cannot be assigned
+	// to its destination.
+	CodeIncompatibleTypes ErrorCode = "incompatible_types"
+	// CodeConversion reports a failed converter, weak type or parsing
+	// conversion.
+	CodeConversion ErrorCode = "conversion"
+	// CodeResolver reports a failed value or batch resolver.
+	CodeResolver ErrorCode = "resolver"
+	// CodeCancelled reports a mapping stopped by its context.
+	CodeCancelled ErrorCode = "cancelled"
+	// CodeTransform reports a failed member transform.
+	CodeTransform ErrorCode = "transform"
+	// CodeHook reports a failed Before/AfterMap or OnCompile hook.
+	CodeHook ErrorCode = "hook"
+	// CodeSealed reports configuration of a sealed type map.
+	CodeSealed ErrorCode = "sealed"
+	// CodeNilSource reports a nil source where one is required.
+	CodeNilSource ErrorCode = "nil_source"
+	// CodeBind reports a request that could not be bound.
+	CodeBind ErrorCode = "bind"
+	// CodeNotLoaded reports an ent edge that was not loaded.
+	CodeNotLoaded ErrorCode = "not_loaded"
+	// CodeRecursion reports recursive types nested deeper than the limit
+	// set with WithMaxDepth.
+	CodeRecursion ErrorCode = "recursion"
+	// CodeUnsupported reports a member kind the mapper is configured to
+	// refuse, such as func and chan members.
+	CodeUnsupported ErrorCode = "unsupported"
+	// CodeUnsettable reports a destination field that cannot be set, see
+	// WithErrorOnUnsettableDest.
+	CodeUnsettable ErrorCode = "unsettable"
+	// CodeInvalidArgument reports a call given an argument it cannot use,
+	// such as a nil destination or an unknown member name.
+	CodeInvalidArgument ErrorCode = "invalid_argument"
+)
+
+// ErrorCode returns the code of the error. Errors wrapping the failure of a
+// collection element or nested mapping report the code of that failure.
+func (e *MappingError) ErrorCode() ErrorCode {
+	if e.Code != "" {
+		return e.Code
+	}
+	var inner *MappingError
+	if errors.As(e.InnerError, &inner) {
+		return inner.ErrorCode()
+	}
+	return CodeUnknown
+}
+
+// mappingErrorJSON is the JSON form of a MappingError.
+type mappingErrorJSON struct {
+	Code     ErrorCode `json:"code"`
+	Message  string    `json:"message"`
+	Field    string    `json:"field,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	SrcType  string    `json:"srcType,omitempty"`
+	DestType string    `json:"destType,omitempty"`
+	Cause    string    `json:"cause,omitempty"`
+}
+
+// MarshalJSON encodes the error with its code, suitable for building
+// problem-details responses. The source snapshot is left out so responses
+// never echo the mapped data:
+//
+//	{"code":"resolver","message":"resolver error","path":"Lines[1].Price","cause":"negative price"}
+func (e *MappingError) MarshalJSON() ([]byte, error) {
+	out := mappingErrorJSON{
+		Code:    e.ErrorCode(),
+		Message: e.Message,
+		Field:   e.FieldName,
+		Path:    e.Path,
+	}
+	if e.SrcType != nil {
+		out.SrcType = e.SrcType.String()
+	}
+	if e.DestType != nil {
+		out.DestType = e.DestType.String()
+	}
+	if e.InnerError != nil {
+		out.Cause = e.InnerError.Error()
+	}
+	return json.Marshal(out)
+}
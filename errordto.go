@@ -0,0 +1,96 @@
+package automapper
+
+import "reflect"
+
+// ErrorDTO is the structured form of an error value, for result records
+// that carry failure reasons. Source members holding an error map to
+// ErrorDTO and *ErrorDTO members, as well as to string members, which
+// receive the message.
+type ErrorDTO struct {
+	Message string
+	// Code is set for errors with an ErrorCode method, such as MappingError
+	Code ErrorCode `json:",omitempty"`
+	// Cause is the error wrapped by this one, if any
+	Cause *ErrorDTO `json:",omitempty"`
+}
+
+// Types involved in error mapping.
+var (
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	errorDTOType = reflect.TypeOf(ErrorDTO{})
+)
+
+// WithErrorMessage sets how error values are rendered into string members
+// and ErrorDTO messages, e.g. to hide internal details from API clients.
+// The default is the error's Error method.
+func WithErrorMessage(format func(err error) string) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.errMessage = format
+	}
+}
+
+// NewErrorDTO returns the structured form of err, following the chain of
+// wrapped errors into Cause, or nil if err is nil. Errors joined with
+// errors.Join contribute their first error as the cause.
+func NewErrorDTO(err error) *ErrorDTO {
+	return newErrorDTO(err, error.Error)
+}
+
+// newErrorDTO builds the ErrorDTO of err with messages rendered by format.
+func newErrorDTO(err error, format func(error) string) *ErrorDTO {
+	if err == nil {
+		return nil
+	}
+	dto := &ErrorDTO{Message: format(err)}
+	if coded, ok := err.(interface{ ErrorCode() ErrorCode }); ok {
+		dto.Code = coded.ErrorCode()
+	}
+
+	var cause error
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		cause = wrapped.Unwrap()
+	case interface{ Unwrap() []error }:
+		if errs := wrapped.Unwrap(); len(errs) > 0 {
+			cause = errs[0]
+		}
+	}
+	dto.Cause = newErrorDTO(cause, format)
+	return dto
+}
+
+// errorMessage renders err with the configured formatter.
+func (c *MapperConfiguration) errorMessage(err error) string {
+	if c.errMessage != nil {
+		return c.errMessage(err)
+	}
+	return err.Error()
+}
+
+// assignError maps a source member holding an error into a string, ErrorDTO
+// or *ErrorDTO destination, and reports whether it did. Nil errors leave the
+// destination unchanged, as other nil sources do.
+func (m *Mapper) assignError(srcVal, destVal reflect.Value) (bool, error) {
+	if !srcVal.IsValid() || !srcVal.Type().Implements(errorType) {
+		return false, nil
+	}
+	destType := destVal.Type()
+	toString := destType.Kind() == reflect.String && srcVal.Kind() == reflect.Interface
+	if !toString && destType != errorDTOType && destType != reflect.PointerTo(errorDTOType) {
+		return false, nil
+	}
+	if (srcVal.Kind() == reflect.Interface || srcVal.Kind() == reflect.Ptr) && srcVal.IsNil() {
+		return true, nil
+	}
+
+	err := srcVal.Interface().(error)
+	switch {
+	case toString:
+		destVal.SetString(m.config.errorMessage(err))
+	case destType == errorDTOType:
+		destVal.Set(reflect.ValueOf(*newErrorDTO(err, m.config.errorMessage)))
+	default:
+		destVal.Set(reflect.ValueOf(newErrorDTO(err, m.config.errorMessage)))
+	}
+	return true, nil
+}
@@ -0,0 +1,127 @@
+package automapper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// WithErrorSourceSnapshot attaches a snapshot of the source struct being
+// mapped to resolver errors, in MappingError.Source, so error logs identify
+// the failing record. Fields carrying the WithRedactionTag tag are redacted
+// in the snapshot the same way they are in mapped values.
+func WithErrorSourceSnapshot() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.errSnapshot = true
+	}
+}
+
+// prependErrorPath prefixes the member paths of the MappingErrors in err's
+// chain with segment, a member name or an element index such as "[2]", as
+// the error propagates out of nested mappings.
+func prependErrorPath(err error, segment string) {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		me, ok := e.(*MappingError)
+		if !ok || me.Path == "" {
+			continue
+		}
+		if strings.HasPrefix(me.Path, "[") {
+			me.Path = segment + me.Path
+		} else {
+			me.Path = segment + "." + me.Path
+		}
+	}
+}
+
+// elementError wraps the error of mapping a collection element, carrying
+// the member path of err through the element's index or key.
+func elementError(err error, key any, message string) error {
+	prependErrorPath(err, fmt.Sprintf("[%v]", key))
+
+	wrapped := &MappingError{Message: message, InnerError: err}
+	var inner *MappingError
+	if errors.As(err, &inner) {
+		wrapped.Path = inner.Path
+	}
+	return wrapped
+}
+
+// maxSnapshotDepth caps how deeply sourceSnapshot descends into nested
+// values.
+const maxSnapshotDepth = 32
+
+// sourceSnapshot returns a copy of v with structs converted to maps of
+// their exported fields and tagged fields redacted. Pointers back to a value
+// being snapshotted, as in parent references, are replaced by "<cycle>", and
+// values nested deeper than maxSnapshotDepth by "<max depth>".
+func sourceSnapshot(v reflect.Value, redactionTag string) any {
+	s := snapshotter{redactionTag: redactionTag, active: make(map[uintptr]bool)}
+	return s.snapshot(v, 0)
+}
+
+// snapshotter holds the state of one sourceSnapshot call: the pointers on
+// the path to the value being snapshotted.
+type snapshotter struct {
+	redactionTag string
+	active       map[uintptr]bool
+}
+
+// snapshot returns the snapshot of v, nested depth levels deep.
+func (s *snapshotter) snapshot(v reflect.Value, depth int) any {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr {
+			ptr := v.Pointer()
+			if s.active[ptr] {
+				return "<cycle>"
+			}
+			s.active[ptr] = true
+			defer delete(s.active, ptr)
+		}
+		v = v.Elem()
+	}
+	if depth > maxSnapshotDepth {
+		return "<max depth>"
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if !hasExportedFields(v.Type()) {
+			return v.Interface()
+		}
+		snapshot := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			value := s.snapshot(v.Field(i), depth+1)
+			if tag, tagged := field.Tag.Lookup(s.redactionTag); s.redactionTag != "" && tagged {
+				if tag == "hash" {
+					value = HashValue(value)
+				} else {
+					value = MaskValue(value)
+				}
+			}
+			snapshot[field.Name] = value
+		}
+		return snapshot
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		elems := make([]any, v.Len())
+		for i := range elems {
+			elems[i] = s.snapshot(v.Index(i), depth+1)
+		}
+		return elems
+	}
+
+	if !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,119 @@
+package automapper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrUnknownEvent is wrapped by errors for events with no registration.
+var ErrUnknownEvent = errors.New("unknown event")
+
+// EventRegistry maps decoded event payloads, identified by event name and
+// schema version, into internal commands through a mapper. Consumers
+// register each event type once and dispatch on the command they get back.
+// An EventRegistry is safe for concurrent use.
+type EventRegistry struct {
+	mapper *Mapper
+	mu     sync.RWMutex
+	events map[eventKey]eventRegistration
+}
+
+// eventKey identifies an event schema.
+type eventKey struct {
+	name    string
+	version string
+}
+
+// eventRegistration records the payload and command types of an event.
+type eventRegistration struct {
+	eventType reflect.Type
+	cmdType   reflect.Type
+}
+
+// NewEventRegistry creates an event registry mapping through m.
+func NewEventRegistry(m *Mapper) *EventRegistry {
+	return &EventRegistry{
+		mapper: m,
+		events: make(map[eventKey]eventRegistration),
+	}
+}
+
+// RegisterEvent registers version of the event name: its payloads decode
+// into TEvent and map to TCmd. Evolving schemas register each version with
+// its own payload type, usually mapping to the same command. The returned
+// builder configures the TEvent to TCmd type map, which is created if it
+// does not exist yet.
+func RegisterEvent[TEvent, TCmd any](r *EventRegistry, name, version string) *TypeMapBuilder[TEvent, TCmd] {
+	b, ok := GetMap[TEvent, TCmd](r.mapper)
+	if !ok {
+		b = CreateMap[TEvent, TCmd](r.mapper)
+	}
+
+	r.mu.Lock()
+	r.events[eventKey{name: name, version: version}] = eventRegistration{
+		eventType: reflect.TypeOf((*TEvent)(nil)).Elem(),
+		cmdType:   reflect.TypeOf((*TCmd)(nil)).Elem(),
+	}
+	r.mu.Unlock()
+	return b
+}
+
+// Decode decodes a raw payload of the named event version with unmarshal,
+// such as json.Unmarshal, and maps it to the registered command type.
+func (r *EventRegistry) Decode(name, version string, payload []byte, unmarshal func([]byte, any) error) (any, error) {
+	reg, err := r.lookup(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	event := reflect.New(reg.eventType)
+	if err := unmarshal(payload, event.Interface()); err != nil {
+		return nil, &MappingError{
+			Message:    fmt.Sprintf("failed to decode event %q version %q", name, version),
+			Code:       CodeConversion,
+			SrcType:    reg.eventType,
+			DestType:   reg.cmdType,
+			InnerError: err,
+		}
+	}
+	return r.mapEvent(reg, event.Elem())
+}
+
+// MapEvent maps an already decoded event of the named version to the
+// registered command type.
+func (r *EventRegistry) MapEvent(name, version string, event any) (any, error) {
+	reg, err := r.lookup(name, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.mapper.checkNilSource(event, reg.cmdType); err != nil {
+		return nil, err
+	}
+	return r.mapEvent(reg, reflect.ValueOf(event))
+}
+
+// lookup returns the registration for an event version.
+func (r *EventRegistry) lookup(name, version string) (eventRegistration, error) {
+	r.mu.RLock()
+	reg, ok := r.events[eventKey{name: name, version: version}]
+	r.mu.RUnlock()
+	if !ok {
+		return reg, &MappingError{
+			Message:    fmt.Sprintf("no event registered for %q version %q", name, version),
+			Code:       CodeNoTypeMap,
+			InnerError: ErrUnknownEvent,
+		}
+	}
+	return reg, nil
+}
+
+// mapEvent maps an event value to a new command.
+func (r *EventRegistry) mapEvent(reg eventRegistration, event reflect.Value) (any, error) {
+	cmd := reflect.New(reg.cmdType).Elem()
+	if err := r.mapper.mapValue(nil, event, cmd); err != nil {
+		return nil, err
+	}
+	return cmd.Interface(), nil
+}
@@ -0,0 +1,295 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Op names the operation of a plan Instruction.
+type Op string
+
+const (
+	// OpCopy assigns the source field directly.
+	OpCopy Op = "copy"
+	// OpConvert converts the source field with a Go conversion.
+	OpConvert Op = "convert"
+	// OpResolve runs the member's resolver, converters and transforms.
+	OpResolve Op = "resolve"
+	// OpRecurse maps pointers, nested structs, collections and registered
+	// type converters.
+	OpRecurse Op = "recurse"
+	// OpWeak converts between strings, numbers and bools under weak typing.
+	OpWeak Op = "weak"
+	// OpFormat formats a number or time for the call's locale.
+	OpFormat Op = "format"
+	// OpDuration maps between a time.Duration and a string or integer
+	// milliseconds.
+	OpDuration Op = "duration"
+)
+
+// opNames maps the engine's opcodes to their exported names.
+var opNames = map[opCode]Op{
+	opCopy:     OpCopy,
+	opConvert:  OpConvert,
+	opResolve:  OpResolve,
+	opRecurse:  OpRecurse,
+	opWeak:     OpWeak,
+	opFormat:   OpFormat,
+	opDuration: OpDuration,
+}
+
+// Plan is the compiled form of a type map: the member mapping steps the
+// engine performs, in order. Plans hold only plain data so they can be
+// persisted, inspected or used to generate code, and run with
+// Mapper.Execute. Ignored lists the destination members skipped with
+// Ignore or by convention, which have no instruction.
+type Plan struct {
+	SrcType      string
+	DestType     string
+	Instructions []Instruction
+	Ignored      []string
+}
+
+// Instruction is a single member mapping step of a Plan. Source is the
+// dotted source path, empty for members produced by resolvers; SrcIndex and
+// DestIndex are the reflect field index paths.
+type Instruction struct {
+	Op        Op
+	Member    string
+	Source    string
+	SrcIndex  []int
+	DestIndex []int
+}
+
+// PlanOf returns the compiled plan of the type map registered for TSrc to
+// TDest.
+func PlanOf[TSrc, TDest any](m *Mapper) (*Plan, error) {
+	tm, err := m.registeredTypeMap(reflect.TypeOf((*TSrc)(nil)).Elem(), reflect.TypeOf((*TDest)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return m.exportPlan(tm), nil
+}
+
+// Plans returns the compiled plans of every registered type map, ordered
+// by source and destination type name.
+func (m *Mapper) Plans() []*Plan {
+	maps := m.sortedTypeMaps()
+	plans := make([]*Plan, len(maps))
+	for i, tm := range maps {
+		plans[i] = m.exportPlan(tm)
+	}
+	return plans
+}
+
+// PlanSnapshot renders the compiled plans of every registered type map as
+// deterministic text, for golden files: a change in how members are matched
+// or converted, e.g. after upgrading the library, then shows up as a
+// reviewable diff.
+func (m *Mapper) PlanSnapshot() string {
+	var b strings.Builder
+	for i, plan := range m.Plans() {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(plan.String())
+	}
+	return b.String()
+}
+
+// String renders the plan as text: its type pair followed by one line per
+// instruction, in destination field order, with the member, operation and
+// source path. Field indices are left out of the text.
+func (p *Plan) String() string {
+	instructions := append([]Instruction(nil), p.Instructions...)
+	sort.SliceStable(instructions, func(i, j int) bool {
+		return indexLess(instructions[i].DestIndex, instructions[j].DestIndex)
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s -> %s\n", p.SrcType, p.DestType)
+	for _, ins := range instructions {
+		fmt.Fprintf(&b, "\t%s: %s", ins.Member, ins.Op)
+		if ins.Source != "" {
+			fmt.Fprintf(&b, " %s", ins.Source)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// indexLess orders field index paths lexicographically, so an embedded
+// struct sorts before its promoted fields.
+func indexLess(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// exportPlan converts the compiled plan of tm into a Plan.
+func (m *Mapper) exportPlan(tm *TypeMap) *Plan {
+	p := m.planFor(tm)
+	plan := &Plan{
+		SrcType:      tm.srcType.String(),
+		DestType:     tm.destType.String(),
+		Instructions: make([]Instruction, len(p.instructions)),
+	}
+	for i, ins := range p.instructions {
+		plan.Instructions[i] = Instruction{
+			Op:        opNames[ins.op],
+			Member:    ins.member.destField,
+			SrcIndex:  append([]int(nil), ins.srcIdx...),
+			DestIndex: append([]int(nil), ins.destIdx...),
+		}
+		if ins.op != opResolve {
+			plan.Instructions[i].Source = m.memberSourcePath(ins.member)
+		}
+	}
+	for _, mm := range tm.memberMaps {
+		if mm.ignore {
+			plan.Ignored = append(plan.Ignored, mm.destField)
+		}
+	}
+	return plan
+}
+
+// Execute runs the instructions of plan from src into dest, a pointer to
+// the plan's destination type. Members are looked up by name in the
+// registered type map, so their resolvers, converters, conditions and
+// member policy apply, and the type map counts as used, as by Map; hooks,
+// custom mappers and finalizers of the type map do not run.
+func (m *Mapper) Execute(plan *Plan, src, dest any) error {
+	srcVal := derefValue(reflect.ValueOf(src))
+	destPtr := reflect.ValueOf(dest)
+	if !srcVal.IsValid() || destPtr.Kind() != reflect.Ptr || destPtr.IsNil() {
+		return &MappingError{
+			Message: "execute needs a non-nil source and a non-nil destination pointer",
+			Code:    CodeInvalidArgument,
+		}
+	}
+	destVal := derefValue(destPtr)
+
+	tm, err := m.registeredTypeMap(srcVal.Type(), destVal.Type())
+	if err != nil {
+		return err
+	}
+	if plan.SrcType != tm.srcType.String() || plan.DestType != tm.destType.String() {
+		return &MappingError{
+			Message:  fmt.Sprintf("plan maps %s -> %s", plan.SrcType, plan.DestType),
+			Code:     CodeInvalidArgument,
+			SrcType:  tm.srcType,
+			DestType: tm.destType,
+		}
+	}
+
+	p, err := m.internalPlan(tm, plan)
+	if err != nil {
+		return err
+	}
+	if err := m.checkUnsettable(tm); err != nil {
+		return err
+	}
+	m.markUsed(tm)
+	return m.executePlan(nil, srcVal, destVal, p)
+}
+
+// internalPlan converts an exported plan back into executable instructions,
+// checking its members and field indices against the type map.
+func (m *Mapper) internalPlan(tm *TypeMap, plan *Plan) (*typeMapPlan, error) {
+	ops := make(map[Op]opCode, len(opNames))
+	for code, name := range opNames {
+		ops[name] = code
+	}
+	members := make(map[string]*MemberMap, len(tm.memberMaps))
+	for _, mm := range tm.memberMaps {
+		members[mm.destField] = mm
+	}
+
+	p := &typeMapPlan{
+		instructions: make([]instruction, 0, len(plan.Instructions)),
+		policy:       tm.memberPolicy,
+	}
+	for _, in := range plan.Instructions {
+		invalid := func(format string, args ...any) error {
+			return &MappingError{
+				Message:   fmt.Sprintf(format, args...),
+				Code:      CodeInvalidArgument,
+				SrcType:   tm.srcType,
+				DestType:  tm.destType,
+				FieldName: in.Member,
+			}
+		}
+
+		op, ok := ops[in.Op]
+		if !ok {
+			return nil, invalid("unknown plan operation %q", in.Op)
+		}
+		mm, ok := members[in.Member]
+		if !ok {
+			return nil, invalid("plan member is not in the type map")
+		}
+		destType := fieldTypeByIndex(tm.destType, in.DestIndex)
+		if len(in.DestIndex) == 0 || destType == nil {
+			return nil, invalid("invalid destination index %v", in.DestIndex)
+		}
+		if op != opResolve {
+			srcType := fieldTypeByIndex(tm.srcType, in.SrcIndex)
+			if len(in.SrcIndex) == 0 || srcType == nil {
+				return nil, invalid("invalid source index %v", in.SrcIndex)
+			}
+			if !m.opApplies(op, srcType, destType) {
+				return nil, invalid("cannot %s %v to %v", in.Op, srcType, destType)
+			}
+		}
+
+		p.instructions = append(p.instructions, instruction{
+			op:       op,
+			member:   mm,
+			srcIdx:   in.SrcIndex,
+			destIdx:  in.DestIndex,
+			destType: destType,
+		})
+	}
+	return p, nil
+}
+
+// opApplies reports whether op can assign a srcType field to a destType
+// field, so loaded plans cannot make executePlan panic.
+func (m *Mapper) opApplies(op opCode, srcType, destType reflect.Type) bool {
+	switch op {
+	case opCopy:
+		return srcType.AssignableTo(destType)
+	case opConvert:
+		return m.convertible(srcType, destType)
+	case opWeak:
+		return weakConvertible(srcType, destType)
+	case opFormat:
+		return destType.Kind() == reflect.String && displayFormattable(srcType)
+	case opDuration:
+		return isDurationPair(DurationString, srcType, destType) || isDurationPair(DurationMillis, srcType, destType)
+	}
+	return true
+}
+
+// registeredTypeMap returns the type map registered for a pair.
+func (m *Mapper) registeredTypeMap(srcType, destType reflect.Type) (*TypeMap, error) {
+	key := typeMapKey{srcType: derefType(srcType), destType: derefType(destType)}
+
+	m.config.mu.RLock()
+	tm, ok := m.config.typeMaps[key]
+	m.config.mu.RUnlock()
+	if !ok {
+		return nil, &MappingError{
+			Message:  "no type map registered",
+			Code:     CodeNoTypeMap,
+			SrcType:  key.srcType,
+			DestType: key.destType,
+		}
+	}
+	return tm, nil
+}
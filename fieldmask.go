@@ -0,0 +1,177 @@
+package automapper
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// FieldMaskPaths compares two versions of a destination, typically taken
+// before and after MapTo, and returns the paths of the members that differ
+// in protobuf FieldMask notation ("address.city"), ready for use as
+// fieldmaskpb.FieldMask{Paths: paths}. Names come from the protobuf struct
+// tag when present and are the snake_case Go field names otherwise.
+func FieldMaskPaths[T any](before, after T) []string {
+	return changedPaths(reflect.ValueOf(&before).Elem(), reflect.ValueOf(&after).Elem(), protoFieldName)
+}
+
+// FieldMaskFromMembers converts Go member paths of T ("Address.City") into
+// protobuf FieldMask paths, so a list of mapped members can drive an update
+// request.
+func FieldMaskFromMembers[T any](members ...string) ([]string, error) {
+	rootType := reflect.TypeOf((*T)(nil)).Elem()
+
+	paths := make([]string, 0, len(members))
+	for _, member := range members {
+		t := rootType
+		parts := strings.Split(member, ".")
+		names := make([]string, len(parts))
+		for i, part := range parts {
+			t = derefType(t)
+			var field reflect.StructField
+			found := false
+			if t.Kind() == reflect.Struct {
+				field, found = t.FieldByName(part)
+			}
+			if !found || !field.IsExported() {
+				return nil, &MappingError{
+					Message:   "unknown destination member",
+					Code:      CodeInvalidArgument,
+					DestType:  rootType,
+					FieldName: member,
+				}
+			}
+			names[i] = protoFieldName(field)
+			t = field.Type
+		}
+		paths = append(paths, strings.Join(names, "."))
+	}
+	return paths, nil
+}
+
+// changedPaths returns the member paths that differ between two values of
+// the same type, or nil if the values are not (pointers to) structs. If
+// only one of them is a nil pointer, every member path is returned.
+func changedPaths(before, after reflect.Value, name func(reflect.StructField) string) []string {
+	paths := []string{}
+	if !walkChanges(before, after, name, func(path string, _, _ reflect.Value) {
+		paths = append(paths, path)
+	}) {
+		return nil
+	}
+	return paths
+}
+
+// walkChanges calls report for each member that differs between two values
+// of the same type and reports whether the values were (pointers to) structs.
+// When only one of them is a nil pointer, every member counts as changed and
+// the nil side is reported as zero values.
+func walkChanges(before, after reflect.Value, name func(reflect.StructField) string, report func(path string, before, after reflect.Value)) bool {
+	visited := make(map[[2]uintptr]bool)
+	all := false
+	for before.Kind() == reflect.Ptr {
+		switch {
+		case before.IsNil() && after.IsNil():
+			return derefType(before.Type()).Kind() == reflect.Struct
+		case before.IsNil() || after.IsNil():
+			all = true
+		default:
+			visited[[2]uintptr{before.Pointer(), after.Pointer()}] = true
+		}
+		before, after = elemOrZero(before), elemOrZero(after)
+	}
+	if before.Kind() != reflect.Struct {
+		return false
+	}
+	diffMembers("", before, after, all, name, report, visited)
+	return true
+}
+
+// elemOrZero returns the value pointer v points to, or its zero value if v
+// is nil.
+func elemOrZero(v reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return reflect.Zero(v.Type().Elem())
+	}
+	return v.Elem()
+}
+
+// diffMembers reports the exported members that differ between two values of
+// the same struct type. Nested structs with exported fields are compared
+// member by member; everything else is compared as a whole. Pairs of
+// pointers already being compared, such as back-references to a parent,
+// are skipped. With all set, every member is reported.
+func diffMembers(prefix string, before, after reflect.Value, all bool, name func(reflect.StructField) string, report func(path string, before, after reflect.Value), visited map[[2]uintptr]bool) {
+	t := before.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		path := prefix + name(field)
+		b, a := before.Field(i), after.Field(i)
+
+		if b.Kind() == reflect.Ptr && !b.IsNil() && !a.IsNil() {
+			pair := [2]uintptr{b.Pointer(), a.Pointer()}
+			if visited[pair] {
+				continue
+			}
+			visited[pair] = true
+			b, a = b.Elem(), a.Elem()
+		}
+		if b.Kind() == reflect.Struct && hasExportedFields(b.Type()) {
+			diffMembers(path+".", b, a, all, name, report, visited)
+			continue
+		}
+		if all || !reflect.DeepEqual(b.Interface(), a.Interface()) {
+			report(path, b, a)
+		}
+	}
+}
+
+// hasExportedFields reports whether a struct type has any exported field.
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// derefType strips pointer indirections from t.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// protoFieldName returns the protobuf name of a struct field: the name=
+// entry of its protobuf tag, or the snake_case Go field name.
+func protoFieldName(field reflect.StructField) string {
+	for _, part := range strings.Split(field.Tag.Get("protobuf"), ",") {
+		if name, ok := strings.CutPrefix(part, "name="); ok {
+			return name
+		}
+	}
+	return snakeCase(field.Name)
+}
+
+// snakeCase converts a Go identifier to snake_case, keeping acronyms
+// together ("UserID" → "user_id").
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package automapper
+
+import "reflect"
+
+// finalizer is a queued Finalize call for one mapped destination.
+type finalizer struct {
+	fn   func(dest any) error
+	dest reflect.Value
+}
+
+// Finalize adds a function called once the whole mapping call has
+// completed, unlike AfterMap, which runs as soon as this type map's members
+// are mapped. It sees a fully populated destination: nested members have
+// been mapped and finalized, and back-references wired. Finalize runs on
+// every mapping path, including optimized ones. Destinations stored by value
+// in a map, an interface or a slice member reordered with SortBy or Distinct
+// are finalized before they are stored, once their own members are mapped.
+func (b *TypeMapBuilder[TSrc, TDest]) Finalize(fn func(dest *TDest) error) *TypeMapBuilder[TSrc, TDest] {
+	wrapper := func(dest any) error {
+		return fn(dest.(*TDest))
+	}
+
+	b.update(func(tm *TypeMap) {
+		tm.finalizers = append(tm.finalizers, wrapper)
+	})
+	b.mapper.config.hasFinalizers.Store(true)
+	return b
+}
+
+// pendingFinalizers returns the number of finalizers queued so far, to pass
+// to finalizeDetached.
+func (c *MappingContext) pendingFinalizers() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.finalizers)
+}
+
+// finalizeDetached runs the finalizers queued since start. Destinations
+// mapped into a temporary that is then copied, into a map entry or an
+// interface, are finalized this way before the copy, as a queued finalizer
+// would only reach the discarded temporary.
+func (c *MappingContext) finalizeDetached(start int) error {
+	if c == nil || len(c.finalizers) <= start {
+		return nil
+	}
+	pending := append([]finalizer(nil), c.finalizers[start:]...)
+	c.finalizers = c.finalizers[:start]
+	for _, f := range pending {
+		if err := f.fn(f.dest.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queueFinalizers queues the finalizers of typeMap for destVal.
+func (c *MappingContext) queueFinalizers(typeMap *TypeMap, destVal reflect.Value) {
+	for _, fn := range typeMap.finalizers {
+		c.finalizers = append(c.finalizers, finalizer{fn: fn, dest: destVal})
+	}
+}
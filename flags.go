@@ -0,0 +1,92 @@
+package automapper
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Bitmask is the set of integer types usable as flag masks.
+type Bitmask interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// flagEntry is one named bit of a flag table.
+type flagEntry[T Bitmask] struct {
+	bit  T
+	name string
+}
+
+// RegisterFlags registers global converters between the bitmask type T and
+// []string, naming each set bit after the flag table, e.g. a permission mask
+// of 5 as []string{"read", "execute"} for {1: "read", 2: "write", 4:
+// "execute"}. Names are listed in bit order. Mapping fails with
+// CodeConversion for bits or names missing from the table. RegisterFlags
+// panics if a table value is not a single bit or a name is used twice: the
+// table is fixed in code, so such a table is a programming error. Converters
+// rejected because a type map is sealed are reported as by ConvertUsing.
+func RegisterFlags[T Bitmask](m *Mapper, table map[T]string) error {
+	maskType := reflect.TypeOf((*T)(nil)).Elem()
+	entries := make([]flagEntry[T], 0, len(table))
+	bitsByName := make(map[string]T, len(table))
+	for bit, name := range table {
+		if bit == 0 || bit&(bit-1) != 0 {
+			panic(&MappingError{Message: fmt.Sprintf("flag %q is not a single bit", name), Code: CodeInvalidArgument, SrcType: maskType})
+		}
+		if _, dup := bitsByName[name]; dup {
+			panic(&MappingError{Message: fmt.Sprintf("flag %q is registered twice", name), Code: CodeInvalidArgument, SrcType: maskType})
+		}
+		bitsByName[name] = bit
+		entries = append(entries, flagEntry[T]{bit: bit, name: name})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return uint64(entries[i].bit) < uint64(entries[j].bit)
+	})
+
+	namesType := reflect.TypeOf([]string(nil))
+	toNames := ConvertUsing(m, func(mask T) ([]string, error) {
+		names := make([]string, 0, bits.OnesCount64(uint64(mask)))
+		rest := mask
+		for _, e := range entries {
+			if mask&e.bit != 0 {
+				names = append(names, e.name)
+				rest &^= e.bit
+			}
+		}
+		if rest != 0 {
+			return nil, &MappingError{
+				Message:  fmt.Sprintf("unknown flag bits %#x", uint64(rest)),
+				Code:     CodeConversion,
+				SrcType:  maskType,
+				DestType: namesType,
+			}
+		}
+		return names, nil
+	})
+	toMask := ConvertUsing(m, func(names []string) (T, error) {
+		var mask T
+		var unknown []string
+		for _, name := range names {
+			bit, ok := bitsByName[name]
+			if !ok {
+				unknown = append(unknown, name)
+				continue
+			}
+			mask |= bit
+		}
+		if len(unknown) > 0 {
+			return 0, &MappingError{
+				Message:  fmt.Sprintf("unknown flags %s", strings.Join(unknown, ", ")),
+				Code:     CodeConversion,
+				SrcType:  namesType,
+				DestType: maskType,
+			}
+		}
+		return mask, nil
+	})
+	return errors.Join(toNames, toMask)
+}
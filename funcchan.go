@@ -0,0 +1,54 @@
+package automapper
+
+import "reflect"
+
+// FuncChanPolicy controls destination members of func and chan kind, which
+// hold behaviour or synchronization rather than data.
+type FuncChanPolicy int
+
+const (
+	// FuncChanSkip leaves func and chan members unmapped (the default).
+	FuncChanSkip FuncChanPolicy = iota
+	// FuncChanError fails the mapping when a func or chan member would be
+	// mapped from a source field. Members configured with Ignore or
+	// MapFromFunc are unaffected.
+	FuncChanError
+)
+
+// WithFuncChanPolicy sets how type maps treat func and chan members.
+// Either way such members are not reported by ValidateConfiguration.
+func WithFuncChanPolicy(policy FuncChanPolicy) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.funcChan = policy
+	}
+}
+
+// isFuncOrChan reports whether a field holds a func or chan.
+func isFuncOrChan(field reflect.StructField) bool {
+	kind := field.Type.Kind()
+	return kind == reflect.Func || kind == reflect.Chan
+}
+
+// applyFuncChanPolicy configures the func and chan members of a new type map.
+func (c *MapperConfiguration) applyFuncChanPolicy(tm *TypeMap) {
+	if c.funcChan == FuncChanSkip {
+		tm.ignoreMatching(isFuncOrChan, c.typeCache)
+		return
+	}
+
+	for _, mm := range tm.memberMaps {
+		destField := tm.destType.FieldByIndex(mm.destFieldIdx)
+		if !isFuncOrChan(destField) {
+			continue
+		}
+		mm.resolver = func(any, any) (any, error) {
+			return nil, &MappingError{
+				Message:   "cannot map func or chan member",
+				Code:      CodeUnsupported,
+				SrcType:   tm.srcType,
+				DestType:  tm.destType,
+				FieldName: destField.Name,
+			}
+		}
+	}
+}
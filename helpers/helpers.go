@@ -0,0 +1,102 @@
+// Package helpers provides value resolvers for common derived members, such
+// as slugs and display names, so they can be declared instead of written as
+// closures:
+//
+//	automapper.CreateMap[Article, ArticleDTO](mapper).
+//	    ForMemberByName("Slug", automapper.MapFromFunc(helpers.Slugify("Title"))).
+//	    ForMemberByName("Author", automapper.MapFromFunc(helpers.Concat(" ", "FirstName", "LastName")))
+//
+// Source members are named by field, with dot notation for nested members
+// ("Author.Name"). Nil pointers along the path read as empty.
+package helpers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	automapper "github.com/csmart-libs/go-automapper"
+)
+
+// Slugify resolves the source member as a URL slug: lower case letters and
+// digits with every other run of characters replaced by a single hyphen,
+// so "Hello, World!" becomes "hello-world".
+func Slugify(member string) automapper.ValueResolver {
+	return func(src any, _ any) (any, error) {
+		text, err := memberString(src, member)
+		if err != nil {
+			return nil, err
+		}
+		return slug(text), nil
+	}
+}
+
+// Concat resolves the source members joined by sep, skipping empty values,
+// so a missing middle name does not leave a double separator.
+func Concat(sep string, members ...string) automapper.ValueResolver {
+	return func(src any, _ any) (any, error) {
+		parts := make([]string, 0, len(members))
+		for _, member := range members {
+			text, err := memberString(src, member)
+			if err != nil {
+				return nil, err
+			}
+			if text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, sep), nil
+	}
+}
+
+// memberString reads a source member by dotted path and formats it as a
+// string.
+func memberString(src any, member string) (string, error) {
+	v := reflect.ValueOf(src)
+	for _, name := range strings.Split(member, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return "", nil
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return "", fmt.Errorf("source member %q not found", member)
+		}
+		field, ok := v.Type().FieldByName(name)
+		if !ok || !field.IsExported() {
+			return "", fmt.Errorf("source member %q not found", member)
+		}
+		v = v.FieldByIndex(field.Index)
+	}
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// slug converts text to a lower-case, hyphen-separated slug.
+func slug(text string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingHyphen = b.Len() > 0
+			continue
+		}
+		if pendingHyphen {
+			b.WriteByte('-')
+			pendingHyphen = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
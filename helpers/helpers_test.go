@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"testing"
+
+	automapper "github.com/csmart-libs/go-automapper"
+)
+
+type author struct {
+	FirstName  string
+	MiddleName string
+	LastName   string
+}
+
+type article struct {
+	Title  string
+	Author *author
+}
+
+type articleDTO struct {
+	Slug   string
+	Byline string
+}
+
+func TestSlugifyAndConcat(t *testing.T) {
+	mapper := automapper.New()
+	automapper.CreateMap[article, articleDTO](mapper).
+		ForMemberByName("Slug", automapper.MapFromFunc(Slugify("Title"))).
+		ForMemberByName("Byline", automapper.MapFromFunc(Concat(" ", "Author.FirstName", "Author.MiddleName", "Author.LastName")))
+
+	src := article{Title: "  Hello, World! Go 1.22 Released ", Author: &author{FirstName: "Rob", LastName: "Pike"}}
+	dest, err := automapper.Map[articleDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Slug != "hello-world-go-1-22-released" {
+		t.Errorf("unexpected slug: %q", dest.Slug)
+	}
+	if dest.Byline != "Rob Pike" {
+		t.Errorf("unexpected byline: %q", dest.Byline)
+	}
+
+	anonymous, err := automapper.Map[articleDTO](mapper, article{Title: "Café Crème"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if anonymous.Slug != "café-crème" || anonymous.Byline != "" {
+		t.Errorf("unexpected result: %+v", anonymous)
+	}
+
+	if _, err := Slugify("Missing")(src, nil); err == nil {
+		t.Error("expected error for unknown member")
+	}
+}
@@ -0,0 +1,71 @@
+package automapper
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// HookErrorPolicy selects what happens when a BeforeMap or AfterMap hook of
+// a type map returns an error.
+type HookErrorPolicy int
+
+const (
+	// HookErrorAbort stops the mapping and returns the hook's error (the
+	// default).
+	HookErrorAbort HookErrorPolicy = iota
+	// HookErrorLog reports the error to the hook error logger and carries on.
+	HookErrorLog
+	// HookErrorCollect carries on and returns every collected hook error,
+	// joined, once the whole mapping call has completed. The destination is
+	// fully mapped when the error is returned.
+	HookErrorCollect
+)
+
+// WithHookErrorPolicy sets how hook errors of this type map are handled.
+func (b *TypeMapBuilder[TSrc, TDest]) WithHookErrorPolicy(policy HookErrorPolicy) *TypeMapBuilder[TSrc, TDest] {
+	b.update(func(tm *TypeMap) {
+		tm.hookPolicy = policy
+	})
+	if policy == HookErrorCollect {
+		b.mapper.config.hasHookCollect.Store(true)
+	}
+	return b
+}
+
+// WithHookErrorLogger sets the function receiving hook errors of type maps
+// using HookErrorLog. By default they are logged with slog at warning level.
+func WithHookErrorLogger(logger func(err error)) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.hookLogger = logger
+	}
+}
+
+// runHooks calls BeforeMap or AfterMap hooks of typeMap, applying its hook
+// error policy. Errors under HookErrorAbort are returned unchanged.
+func (m *Mapper) runHooks(ctx *MappingContext, typeMap *TypeMap, hooks []BeforeAfterMapFunc, src, dest any) error {
+	for _, hook := range hooks {
+		err := hook(src, dest)
+		if err == nil {
+			continue
+		}
+		if typeMap.hookPolicy == HookErrorAbort {
+			return err
+		}
+
+		err = &MappingError{
+			Message:    fmt.Sprintf("hook error: %v", err),
+			Code:       CodeHook,
+			SrcType:    typeMap.srcType,
+			DestType:   typeMap.destType,
+			InnerError: err,
+		}
+		if typeMap.hookPolicy == HookErrorCollect {
+			ctx.hookErrors = append(ctx.hookErrors, err)
+		} else if m.config.hookLogger != nil {
+			m.config.hookLogger(err)
+		} else {
+			slog.Warn("automapper: hook failed", "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// InterfacePolicy controls values assigned to interface-typed destination
+// members, such as any, that no registered type map handles.
+type InterfacePolicy int
+
+const (
+	// InterfaceAssign assigns values as they are (the default), so slices,
+	// maps and pointers held by the destination are shared with the source.
+	InterfaceAssign InterfacePolicy = iota
+	// InterfaceDeepCopy assigns deep copies of values holding slices, maps
+	// or pointers, so the destination never aliases the source.
+	InterfaceDeepCopy
+	// InterfaceError fails the mapping for values holding slices, maps or
+	// pointers. Other values, including nil slices and maps, are assigned
+	// as they are.
+	InterfaceError
+)
+
+// WithInterfacePolicy sets how values are assigned to interface-typed
+// destination members, and to the interface elements of slices, arrays and
+// maps, when no registered type map handles their type.
+func WithInterfacePolicy(policy InterfacePolicy) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.ifacePolicy = policy
+	}
+}
+
+// mapsInterfaceElements reports whether collections of type t must be
+// mapped element by element so the interface policy and
+// WithJSONSafeInterfaces reach the values they hold, rather than being
+// copied or converted as a whole.
+func (m *Mapper) mapsInterfaceElements(t reflect.Type) bool {
+	if m.config.ifacePolicy == InterfaceAssign && !m.config.jsonSafe {
+		return false
+	}
+	return holdsInterfaces(t)
+}
+
+// holdsInterfaces reports whether t is a slice, array or map whose
+// elements are interfaces or such collections.
+func holdsInterfaces(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return t.Elem().Kind() == reflect.Interface || holdsInterfaces(t.Elem())
+	}
+	return false
+}
+
+// assignInterface assigns srcVal to the interface destVal under the
+// configured policy, and reports whether it did.
+func (m *Mapper) assignInterface(srcVal, destVal reflect.Value) (bool, error) {
+	if m.config.ifacePolicy == InterfaceAssign || destVal.Kind() != reflect.Interface ||
+		!srcVal.Type().AssignableTo(destVal.Type()) {
+		return false, nil
+	}
+	if !sharesMemory(srcVal.Type()) || isNilReference(srcVal) {
+		destVal.Set(srcVal)
+		return true, nil
+	}
+
+	if m.config.ifacePolicy == InterfaceError {
+		return true, &MappingError{
+			Message:  fmt.Sprintf("cannot assign %v to an interface member without aliasing the source", srcVal.Type()),
+			Code:     CodeUnsupported,
+			SrcType:  srcVal.Type(),
+			DestType: destVal.Type(),
+		}
+	}
+	destVal.Set(deepCopyValue(srcVal))
+	return true, nil
+}
+
+// isNilReference reports whether v is a nil slice or map, which shares no
+// memory with the source.
+func isNilReference(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.IsNil()
+	}
+	return false
+}
+
+// sharesMemory reports whether copying a value of type t by assignment
+// leaves the copy referring to memory of the original.
+func sharesMemory(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	case reflect.Array:
+		return sharesMemory(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if sharesMemory(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package automapper
+
+import "reflect"
+
+// FieldPredicate selects struct fields, e.g. for IgnoreFieldsMatching.
+type FieldPredicate func(field reflect.StructField) bool
+
+// IgnoreFieldsMatching ignores, in every type map, the destination members
+// whose field, or whose source field, matches the predicate. It keeps
+// non-data fields such as locks or protobuf XXX_ internals from being copied:
+//
+//	automapper.IgnoreFieldsMatching(func(f reflect.StructField) bool {
+//		return f.Type == reflect.TypeOf(sync.Mutex{}) || strings.HasPrefix(f.Name, "XXX_")
+//	})
+func IgnoreFieldsMatching(match FieldPredicate) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.ignoreMatch = append(c.ignoreMatch, match)
+	}
+}
+
+// IgnoreFieldsMatching ignores the destination members of this type map
+// whose field, or whose source field, matches the predicate.
+func (b *TypeMapBuilder[TSrc, TDest]) IgnoreFieldsMatching(match FieldPredicate) *TypeMapBuilder[TSrc, TDest] {
+	b.update(func(tm *TypeMap) {
+		tm.ignoreMatching(match, b.mapper.config.typeCache)
+	})
+	return b
+}
+
+// ignoreMatching marks the members of tm matched by the predicate as
+// ignored, adding member maps for unmapped destination fields so they are
+// not reported as unmapped either.
+func (tm *TypeMap) ignoreMatching(match FieldPredicate, cache *typeCache) {
+	mapped := make(map[string]*MemberMap, len(tm.memberMaps))
+	for _, mm := range tm.memberMaps {
+		mapped[mm.destField] = mm
+	}
+
+	for _, field := range cache.getTypeInfo(tm.destType).fields {
+		mm := mapped[field.name]
+		matched := match(tm.destType.FieldByIndex(field.index))
+		if !matched && mm != nil && len(mm.srcFieldIdx) > 0 {
+			matched = match(sourceStructField(tm.srcType, mm.srcFieldIdx))
+		}
+		if !matched {
+			continue
+		}
+		if mm == nil {
+			mm = &MemberMap{destField: field.name, destFieldIdx: field.index}
+			tm.memberMaps = append(tm.memberMaps, mm)
+		}
+		mm.ignore = true
+	}
+}
@@ -0,0 +1,44 @@
+package automapper
+
+import "reflect"
+
+// Strings longer than maxInternLen are never interned, and at most
+// maxInterned distinct values are kept per call, so unique values such as
+// IDs or descriptions do not grow the table without bound.
+const (
+	maxInternLen = 64
+	maxInterned  = 4096
+)
+
+// WithStringInterning makes each mapping call share one copy of equal
+// short string members, such as status codes or country names, across the
+// destinations it produces. Large MapSlice results then keep one string per
+// distinct value instead of one per element, once the sources are released.
+func WithStringInterning() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.interning = true
+	}
+}
+
+// internString replaces the string held by destField with the call's
+// shared copy of that value.
+func (m *Mapper) internString(ctx *MappingContext, destField reflect.Value) {
+	if !m.config.interning || ctx == nil || destField.Kind() != reflect.String {
+		return
+	}
+	s := destField.String()
+	if len(s) > maxInternLen {
+		return
+	}
+
+	if shared, ok := ctx.interned[s]; ok {
+		destField.SetString(shared)
+		return
+	}
+	if ctx.interned == nil {
+		ctx.interned = make(map[string]string)
+	}
+	if len(ctx.interned) < maxInterned {
+		ctx.interned[s] = s
+	}
+}
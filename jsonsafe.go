@@ -0,0 +1,145 @@
+package automapper
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// jsonConverter produces the JSON-safe form of a value.
+type jsonConverter func(v any) (any, error)
+
+// Types with built-in JSON-safe forms.
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// WithJSONSafeInterfaces converts values assigned to interface-typed
+// destination members, such as any, and to the interface elements of
+// slices, arrays and maps, into forms that encode to JSON predictably, so
+// DTOs can be encoded without post-processing. Converters
+// registered with ConvertForJSON are tried first; then times become strings
+// in the WithTimeFormat layout, durations strings such as "1h30m0s", and
+// encoding.TextMarshaler values such as decimals and addresses their text.
+// Other json.Marshaler values are encoded into a json.RawMessage. Values of
+// other types are assigned as they are.
+func WithJSONSafeInterfaces() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.jsonSafe = true
+	}
+}
+
+// ConvertForJSON registers the JSON-safe form of T used by
+// WithJSONSafeInterfaces, taking precedence over the built-in forms. Like
+// ConvertUsing, it returns an error once a type map from T is sealed.
+func ConvertForJSON[T any](m *Mapper, converter func(T) (any, error)) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	m.config.mu.Lock()
+	defer m.config.mu.Unlock()
+
+	if m.config.rejectSealed(nil, typeMapKey{srcType: t}) {
+		return sealedError(typeMapKey{srcType: t})
+	}
+	m.config.checkLateConfiguration(nil, typeMapKey{srcType: t})
+	if m.config.jsonConv == nil {
+		m.config.jsonConv = make(map[reflect.Type]jsonConverter)
+	}
+	m.config.jsonConv[t] = func(v any) (any, error) {
+		return converter(v.(T))
+	}
+	return nil
+}
+
+// assignJSONSafe assigns the JSON-safe form of srcVal to the interface
+// destVal under WithJSONSafeInterfaces, and reports whether it did.
+func (m *Mapper) assignJSONSafe(srcVal, destVal reflect.Value) (bool, error) {
+	if !m.config.jsonSafe || destVal.Kind() != reflect.Interface {
+		return false, nil
+	}
+	srcType := srcVal.Type()
+
+	// Pointer sources arrive dereferenced; their methods and converters
+	// registered for the pointer type still apply
+	ptrVal := reflect.Value{}
+	if srcVal.CanAddr() {
+		ptrVal = srcVal.Addr()
+	}
+
+	m.config.mu.RLock()
+	converter, ok := m.config.jsonConv[srcType]
+	if !ok && ptrVal.IsValid() {
+		if converter, ok = m.config.jsonConv[ptrVal.Type()]; ok {
+			srcVal = ptrVal
+		}
+	}
+	m.config.mu.RUnlock()
+
+	var safe any
+	switch {
+	case ok:
+		var err error
+		if safe, err = converter(srcVal.Interface()); err != nil {
+			return true, &MappingError{
+				Message:    "JSON-safe conversion failed",
+				Code:       CodeConversion,
+				SrcType:    srcType,
+				DestType:   destVal.Type(),
+				InnerError: err,
+			}
+		}
+	case srcType == timeType:
+		if t := srcVal.Interface().(time.Time); !t.IsZero() {
+			safe = t.Format(m.config.timeLayout())
+		} else {
+			safe = ""
+		}
+	case srcType == durationType:
+		safe = srcVal.Interface().(time.Duration).String()
+	case implements(srcVal, ptrVal, textMarshalerType):
+		text, err := methodValue(srcVal, ptrVal, textMarshalerType).(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return true, &MappingError{Message: "cannot marshal text", Code: CodeConversion, SrcType: srcType, InnerError: err}
+		}
+		safe = string(text)
+	case implements(srcVal, ptrVal, jsonMarshalerType):
+		raw, err := methodValue(srcVal, ptrVal, jsonMarshalerType).(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return true, &MappingError{Message: "cannot marshal JSON", Code: CodeConversion, SrcType: srcType, InnerError: err}
+		}
+		safe = json.RawMessage(raw)
+	default:
+		return false, nil
+	}
+
+	if safe == nil {
+		destVal.Set(reflect.Zero(destVal.Type()))
+		return true, nil
+	}
+	safeVal := reflect.ValueOf(safe)
+	if !safeVal.Type().AssignableTo(destVal.Type()) {
+		return true, &MappingError{
+			Message:  "JSON-safe form does not implement the member's interface",
+			Code:     CodeIncompatibleTypes,
+			SrcType:  safeVal.Type(),
+			DestType: destVal.Type(),
+		}
+	}
+	destVal.Set(safeVal)
+	return true, nil
+}
+
+// implements reports whether v, or ptr when valid, implements iface.
+func implements(v, ptr reflect.Value, iface reflect.Type) bool {
+	return v.Type().Implements(iface) || (ptr.IsValid() && ptr.Type().Implements(iface))
+}
+
+// methodValue returns v, or ptr if only the pointer implements iface.
+func methodValue(v, ptr reflect.Value, iface reflect.Type) any {
+	if v.Type().Implements(iface) {
+		return v.Interface()
+	}
+	return ptr.Interface()
+}
@@ -0,0 +1,166 @@
+package automapper
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocaleFormat describes how a locale writes numbers and dates.
+type LocaleFormat struct {
+	DecimalSeparator string
+	GroupSeparator   string
+	DateLayout       string
+}
+
+// builtinLocales are the locale formats available without configuration,
+// keyed by BCP 47 tag or bare language.
+var builtinLocales = map[string]LocaleFormat{
+	"en":    {DecimalSeparator: ".", GroupSeparator: ",", DateLayout: "01/02/2006"},
+	"en-GB": {DecimalSeparator: ".", GroupSeparator: ",", DateLayout: "02/01/2006"},
+	"de":    {DecimalSeparator: ",", GroupSeparator: ".", DateLayout: "02.01.2006"},
+	"fr":    {DecimalSeparator: ",", GroupSeparator: " ", DateLayout: "02/01/2006"},
+	"es":    {DecimalSeparator: ",", GroupSeparator: ".", DateLayout: "02/01/2006"},
+	"it":    {DecimalSeparator: ",", GroupSeparator: ".", DateLayout: "02/01/2006"},
+	"ja":    {DecimalSeparator: ".", GroupSeparator: ",", DateLayout: "2006/01/02"},
+}
+
+// defaultLocale is used when a call sets no locale or an unknown one.
+const defaultLocale = "en"
+
+// WithLocaleFormat adds or replaces the format used for a locale tag.
+func WithLocaleFormat(locale string, format LocaleFormat) ConfigOption {
+	return func(c *MapperConfiguration) {
+		if c.locales == nil {
+			c.locales = make(map[string]LocaleFormat)
+		}
+		c.locales[locale] = format
+	}
+}
+
+// WithLocale sets the locale, such as "de-DE", that display type maps
+// format numbers and dates for during this call.
+func WithLocale(locale string) MapOption {
+	return func(c *MappingContext) {
+		c.locale = locale
+	}
+}
+
+// AsDisplay declares that the type map produces display DTOs: string
+// members fed by numbers or times are formatted for the locale set with
+// WithLocale, e.g. 1234.5 as "1.234,5" for "de-DE".
+func (b *TypeMapBuilder[TSrc, TDest]) AsDisplay() *TypeMapBuilder[TSrc, TDest] {
+	b.update(func(tm *TypeMap) {
+		tm.display = true
+	})
+	return b
+}
+
+// Locale returns the locale set for the call with WithLocale, or "" if none.
+func (c *MappingContext) Locale() string {
+	if c == nil {
+		return ""
+	}
+	return c.locale
+}
+
+// localeFormat returns the format for the call's locale, trying the full
+// tag, then its language, then the default locale.
+func (c *MappingContext) localeFormat() LocaleFormat {
+	var configured map[string]LocaleFormat
+	if c != nil && c.mapper != nil {
+		configured = c.mapper.config.locales
+	}
+
+	locale := c.Locale()
+	language, _, _ := strings.Cut(locale, "-")
+	for _, tag := range []string{locale, language, defaultLocale} {
+		if f, ok := configured[tag]; ok {
+			return f
+		}
+		if f, ok := builtinLocales[tag]; ok {
+			return f
+		}
+	}
+	return builtinLocales[defaultLocale]
+}
+
+// FormatNumber formats an integer or floating-point number for the call's
+// locale, with digit grouping. Other values are formatted as by fmt.
+func (c *MappingContext) FormatNumber(v any) string {
+	return formatNumber(reflect.ValueOf(v), c.localeFormat())
+}
+
+// FormatDate formats a time as a date for the call's locale. The zero time
+// formats as "".
+func (c *MappingContext) FormatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(c.localeFormat().DateLayout)
+}
+
+// displayFormattable reports whether display maps format values of t.
+func displayFormattable(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// formatDisplay formats a number or time for the call's locale.
+func formatDisplay(ctx *MappingContext, v reflect.Value) string {
+	if v.Type() == timeType {
+		return ctx.FormatDate(v.Interface().(time.Time))
+	}
+	return formatNumber(v, ctx.localeFormat())
+}
+
+// formatNumber writes a number with the locale's separators.
+func formatNumber(v reflect.Value, f LocaleFormat) string {
+	var digits string
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		digits = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		digits = strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		if math.IsInf(v.Float(), 0) || math.IsNaN(v.Float()) {
+			return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+		}
+		digits = strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	case reflect.Invalid:
+		return ""
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	whole, fraction, hasFraction := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(f.GroupSeparator)
+		}
+		b.WriteRune(r)
+	}
+	if hasFraction {
+		b.WriteString(f.DecimalSeparator)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
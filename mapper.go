@@ -18,10 +18,17 @@ package automapper
 import (
 	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Mapper is the main interface for object-to-object mapping.
 // It provides methods to configure mappings and perform mapping operations.
+//
+// A Mapper is safe for concurrent use by multiple goroutines, including
+// configuring maps while other goroutines map: type maps are copy-on-write,
+// so a mapping always runs against one consistent configuration version.
+// Use CheckConcurrency to find configuration that happens after mapping began.
 type Mapper struct {
 	config *MapperConfiguration
 }
@@ -30,14 +37,80 @@ type Mapper struct {
 type MapperConfiguration struct {
 	mu           sync.RWMutex
 	typeMaps     map[typeMapKey]*TypeMap
+	bySource     map[reflect.Type][]reflect.Type
 	typeCache    *typeCache
 	converters   map[typeMapKey]TypeConverter
+	versions     map[versionKey]reflect.Type
+	selectors    map[reflect.Type]destinationSelector
+	variants     map[discriminatorKey]reflect.Type
 	allowNilColl bool
+	keepNilElems bool
+	sealOnUse    bool
+	strictMaps   bool
+	redactionTag string
+	edgePolicy   EdgePolicy
+	mapKeyTag    string
+	weakTypes    bool
+	ignoreMatch  []FieldPredicate
+	funcChan     FuncChanPolicy
+	timeFormat   string
+	locales      map[string]LocaleFormat
+	noNestedHook bool
+	hookLogger   func(err error)
+	onCompile    []func(tm *TypeMapView) error
+	nilSource    NilSourcePolicy
+	maxDepth     int
+	errSnapshot  bool
+	unsettable   bool
+	noConvert    bool
+	verifyOpt    bool
+	interning    bool
+	arena        bool
+	shareSlices  bool
+	unknownKind  UnknownKindPolicy
+	ifacePolicy  InterfacePolicy
+	jsonSafe     bool
+	jsonConv     map[reflect.Type]jsonConverter
+	errMessage   func(err error) string
 
 	// Optimization settings
 	optLevel      OptimizationLevel
 	useUnsafe     bool
+	unsafeIf      func() bool
 	optimizedMaps map[typeMapKey]*TypeMapOptimized
+
+	// generation is bumped on every configuration change so compiled
+	// plans can detect that they are stale.
+	generation   atomic.Uint64
+	derivedPlans atomic.Pointer[derivedPlanIndex]
+
+	// usage counts mapped members under WithUsageStats
+	usage *sync.Map // map[usageKey]*atomic.Uint64
+
+	// hasBackRefs is set once any type map configures a back-reference;
+	// backRefs indexes them by destination type for the current generation
+	hasBackRefs atomic.Bool
+	backRefs    atomic.Pointer[backRefIndex]
+
+	// mapCopiers caches the typed map copy loops for the current generation
+	mapCopiers atomic.Pointer[mapCopierIndex]
+
+	// hasFinalizers is set once any type map configures a finalizer
+	hasFinalizers atomic.Bool
+
+	// hasHookCollect is set once any type map collects hook errors
+	hasHookCollect atomic.Bool
+
+	// hasBatches is set once any member uses a batch resolver
+	hasBatches atomic.Bool
+
+	// hasRecursive is set once any type map has a recursive source type
+	hasRecursive atomic.Bool
+
+	// Concurrency debug mode (see CheckConcurrency)
+	checkConcurrency atomic.Bool
+	mappingStarted   atomic.Bool
+	violations       []ValidationIssue
 }
 
 // typeMapKey uniquely identifies a source-destination type pair.
@@ -51,10 +124,29 @@ type TypeMap struct {
 	srcType      reflect.Type
 	destType     reflect.Type
 	memberMaps   []*MemberMap
-	customMapper CustomMapperFunc
+	customMapper contextMapperFunc
 	beforeMap    []BeforeAfterMapFunc
 	afterMap     []BeforeAfterMapFunc
 	ignoreFields map[string]bool
+	memberPolicy MemberPolicy
+	backRef      string
+	finalizers   []func(dest any) error
+	hookPolicy   HookErrorPolicy
+	weakTypes    bool
+	display      bool
+	durations    DurationFormat
+	nilColl      NilCollectionPolicy
+	concurrency  int
+	recursive    bool
+	unsettable   []string
+	compileErr   error
+	plan         atomic.Pointer[typeMapPlan]
+	sealed       atomic.Bool
+	used         atomic.Bool
+
+	// autoCreated marks a type map created while mapping rather than
+	// registered; configuring it through a builder clears the mark
+	autoCreated bool
 }
 
 // MemberMap represents the mapping configuration for a single member/field.
@@ -63,12 +155,37 @@ type MemberMap struct {
 	destFieldIdx  []int
 	srcField      string
 	srcFieldIdx   []int
+	srcFields     []string
 	resolver      ValueResolver
+	batch         BatchResolver
 	converter     TypeConverter
+	elemConverter TypeConverter
+	redactor      Redactor
+	transforms    []valueTransform
+	collectionOps []collectionOp
+	collElems     []reflect.Type
+	after         []string
+	retries       int
+	backoff       time.Duration
 	condition     ConditionFunc
+	ptrCond       pointerCondition
+	valueCond     valueCondition
 	ignore        bool
 	useFlattening bool
 	flattenPath   []string
+	nilColl       NilCollectionPolicy
+	emptyMap      EmptyMapPolicy
+	sources       []memberSource
+	zeroTimeNil   bool
+	condSrc       reflect.Type
+	redactSet     bool
+}
+
+// transformsValue reports whether the member's value is produced or
+// altered by configured functions rather than copied from its source field.
+func (mm *MemberMap) transformsValue() bool {
+	return mm.resolver != nil || mm.batch != nil || mm.converter != nil || mm.elemConverter != nil || mm.redactor != nil ||
+		len(mm.transforms) > 0 || len(mm.collectionOps) > 0
 }
 
 // TypeConverter is a function that converts from one type to another.
@@ -80,19 +197,30 @@ type ValueResolver func(src any, dest any) (any, error)
 // CustomMapperFunc is a function that performs custom mapping between types.
 type CustomMapperFunc func(src any, dest any) error
 
+// contextMapperFunc is the internal form of custom mappers; ctx may be nil.
+type contextMapperFunc func(ctx *MappingContext, src any, dest any) error
+
 // BeforeAfterMapFunc is a function called before or after mapping.
 type BeforeAfterMapFunc func(src any, dest any) error
 
 // ConditionFunc determines if a member should be mapped.
 type ConditionFunc func(src any) bool
 
+// MemberPolicy determines if a destination member may be mapped in the
+// given mapping context.
+type MemberPolicy func(ctx *MappingContext, member string) bool
+
 // New creates a new Mapper with default configuration.
 func New() *Mapper {
 	return &Mapper{
 		config: &MapperConfiguration{
 			typeMaps:      make(map[typeMapKey]*TypeMap),
+			bySource:      make(map[reflect.Type][]reflect.Type),
 			typeCache:     newTypeCache(),
 			converters:    make(map[typeMapKey]TypeConverter),
+			versions:      make(map[versionKey]reflect.Type),
+			selectors:     make(map[reflect.Type]destinationSelector),
+			variants:      make(map[discriminatorKey]reflect.Type),
 			optimizedMaps: make(map[typeMapKey]*TypeMapOptimized),
 		},
 	}
@@ -104,9 +232,34 @@ func NewWithConfig(opts ...ConfigOption) *Mapper {
 	for _, opt := range opts {
 		opt(m.config)
 	}
+	if m.config.unsafeDisabled() {
+		m.config.useUnsafe = false
+	}
 	return m
 }
 
+// registerTypeMap stores tm under key, indexing its destination type by
+// source type. Callers hold mu.
+func (c *MapperConfiguration) registerTypeMap(key typeMapKey, tm *TypeMap) {
+	if _, exists := c.typeMaps[key]; !exists {
+		c.bySource[key.srcType] = append(c.bySource[key.srcType], key.destType)
+	}
+	c.typeMaps[key] = tm
+}
+
+// indexTypeMaps rebuilds the source type index from the registered type maps.
+func (c *MapperConfiguration) indexTypeMaps() {
+	c.bySource = make(map[reflect.Type][]reflect.Type, len(c.typeMaps))
+	for key := range c.typeMaps {
+		c.bySource[key.srcType] = append(c.bySource[key.srcType], key.destType)
+	}
+}
+
+// invalidatePlans marks every compiled plan as stale.
+func (c *MapperConfiguration) invalidatePlans() {
+	c.generation.Add(1)
+}
+
 // ConfigOption is a function that configures the mapper.
 type ConfigOption func(*MapperConfiguration)
 
@@ -117,6 +270,53 @@ func WithAllowNullCollections() ConfigOption {
 	}
 }
 
+// WithNilElements keeps nil pointer elements of slices and arrays nil
+// instead of mapping them to pointers to zero values.
+func WithNilElements() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.keepNilElems = true
+	}
+}
+
+// WithSealOnFirstUse seals each type map the first time it is used for
+// mapping. Configuring a sealed map, or registering global converters,
+// selectors and discriminators for its source or destination type, is
+// ignored, returned as an error wrapping ErrMapSealed and reported by
+// ValidateConfiguration, catching configuration that would otherwise only
+// take effect for mappings started after it.
+func WithSealOnFirstUse() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.sealOnUse = true
+	}
+}
+
+// WithoutNestedHooks runs BeforeMap and AfterMap hooks only for the
+// outermost struct of each mapping call. Structs mapped as members, at any
+// optimization level, then skip their type map's hooks.
+func WithoutNestedHooks() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.noNestedHook = true
+	}
+}
+
+// WithStrictTypeMaps disables automatic type map creation: mapping a struct
+// pair without a registered map fails instead of matching fields by name.
+func WithStrictTypeMaps() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.strictMaps = true
+	}
+}
+
+// WithDisableImplicitConversion turns off reflect's conversion fallback, so
+// values of different types (int32 to int64, a named string type to string)
+// only map through registered converters and type maps. Every cross-type
+// conversion then has to be declared explicitly.
+func WithDisableImplicitConversion() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.noConvert = true
+	}
+}
+
 // WithOptimizationLevel sets the optimization level for the mapper.
 func WithOptimizationLevel(level OptimizationLevel) ConfigOption {
 	return func(c *MapperConfiguration) {
@@ -139,6 +339,28 @@ func WithUnsafeOptimizations() ConfigOption {
 	}
 }
 
+// WithUnsafeDisabledIf turns unsafe optimizations off when disabled returns
+// true, evaluated once when the mapper is created, so one binary can run
+// without unsafe code in regulated environments:
+//
+//	automapper.NewWithConfig(
+//		automapper.WithOptimizationLevel(automapper.OptimizationSpecialized),
+//		automapper.WithUnsafeDisabledIf(func() bool { return os.Getenv("AUTOMAPPER_NO_UNSAFE") != "" }),
+//	)
+//
+// Mapping then uses the reflection-based paths of the configured level.
+func WithUnsafeDisabledIf(disabled func() bool) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.unsafeIf = disabled
+	}
+}
+
+// unsafeDisabled reports whether WithUnsafeDisabledIf turned unsafe
+// optimizations off.
+func (c *MapperConfiguration) unsafeDisabled() bool {
+	return c.unsafeIf != nil && c.unsafeIf()
+}
+
 // WithPooling is a configuration option placeholder for future object pooling support.
 // Currently, this option only sets the optimization level but does not enable actual pooling.
 // It is kept for API compatibility and future implementation.
@@ -179,6 +401,15 @@ func CreateMap[TSrc, TDest any](m *Mapper) *TypeMapBuilder[TSrc, TDest] {
 	m.config.mu.Lock()
 	defer m.config.mu.Unlock()
 
+	if existing := m.config.typeMaps[key]; existing != nil && m.config.rejectSealed(existing, key) {
+		return &TypeMapBuilder[TSrc, TDest]{
+			mapper:  m,
+			typeMap: existing,
+			err:     sealedError(key),
+		}
+	}
+	m.config.checkLateConfiguration(m.config.typeMaps[key], key)
+
 	tm := &TypeMap{
 		srcType:      srcType,
 		destType:     destType,
@@ -186,14 +417,16 @@ func CreateMap[TSrc, TDest any](m *Mapper) *TypeMapBuilder[TSrc, TDest] {
 		ignoreFields: make(map[string]bool),
 	}
 
-	// Auto-configure member maps based on field matching
-	tm.autoConfigureMembers(m.config.typeCache)
+	// Auto-configure member maps based on field matching; a failed OnCompile
+	// hook fails the mappings using the type map
+	tm.compileErr = m.config.autoConfigure(tm)
 
-	m.config.typeMaps[key] = tm
+	m.config.registerTypeMap(key, tm)
+	m.config.invalidatePlans()
 
 	// Compile optimized version if optimization is enabled
 	if m.config.optLevel > OptimizationNone {
-		optMap := compileOptimizedTypeMap(tm, m.config.optLevel)
+		optMap := m.config.compileOptimized(tm, m.config.optLevel)
 		m.config.optimizedMaps[key] = optMap
 	}
 
@@ -203,10 +436,109 @@ func CreateMap[TSrc, TDest any](m *Mapper) *TypeMapBuilder[TSrc, TDest] {
 	}
 }
 
+// GetMap returns a builder for an already registered mapping, so packages
+// can extend a map configured elsewhere instead of replacing it. The second
+// result is false if no mapping is registered for the type pair.
+func GetMap[TSrc, TDest any](m *Mapper) (*TypeMapBuilder[TSrc, TDest], bool) {
+	srcType := reflect.TypeOf((*TSrc)(nil)).Elem()
+	destType := reflect.TypeOf((*TDest)(nil)).Elem()
+
+	// Handle pointer types
+	if srcType.Kind() == reflect.Ptr {
+		srcType = srcType.Elem()
+	}
+	if destType.Kind() == reflect.Ptr {
+		destType = destType.Elem()
+	}
+
+	m.config.mu.RLock()
+	tm, ok := m.config.typeMaps[typeMapKey{srcType: srcType, destType: destType}]
+	m.config.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	return &TypeMapBuilder[TSrc, TDest]{
+		mapper:  m,
+		typeMap: tm,
+	}, true
+}
+
+// clone returns a copy of the type map with its own member maps and hook
+// slices, so the copy can be modified without affecting readers of the
+// original. Compiled plans are not copied.
+func (tm *TypeMap) clone() *TypeMap {
+	c := &TypeMap{
+		srcType:      tm.srcType,
+		destType:     tm.destType,
+		memberMaps:   make([]*MemberMap, len(tm.memberMaps)),
+		customMapper: tm.customMapper,
+		beforeMap:    append([]BeforeAfterMapFunc(nil), tm.beforeMap...),
+		afterMap:     append([]BeforeAfterMapFunc(nil), tm.afterMap...),
+		ignoreFields: make(map[string]bool, len(tm.ignoreFields)),
+		memberPolicy: tm.memberPolicy,
+		backRef:      tm.backRef,
+		finalizers:   append([]func(dest any) error(nil), tm.finalizers...),
+		hookPolicy:   tm.hookPolicy,
+		weakTypes:    tm.weakTypes,
+		display:      tm.display,
+		durations:    tm.durations,
+		nilColl:      tm.nilColl,
+		concurrency:  tm.concurrency,
+		recursive:    tm.recursive,
+		unsettable:   tm.unsettable,
+		compileErr:   tm.compileErr,
+	}
+	for i, mm := range tm.memberMaps {
+		cp := *mm
+		c.memberMaps[i] = &cp
+	}
+	for name, ignored := range tm.ignoreFields {
+		c.ignoreFields[name] = ignored
+	}
+	c.sealed.Store(tm.sealed.Load())
+	c.used.Store(tm.used.Load())
+	return c
+}
+
+// autoConfigure configures the members of a new type map by convention,
+// then runs the OnCompile hooks.
+func (c *MapperConfiguration) autoConfigure(tm *TypeMap) error {
+	c.applyConventions(tm)
+	return c.runCompileHooks(tm)
+}
+
+// applyConventions configures the members of tm by convention: matching
+// names and flattening, ent edges, redaction tags, ignored field
+// predicates, the func and chan and unknown kind policies, and flags
+// recursive source types. Unlike autoConfigure it runs no hooks, for type
+// maps that are only inspected.
+func (c *MapperConfiguration) applyConventions(tm *TypeMap) {
+	tm.autoConfigureMembers(c.typeCache)
+	c.configureEdges(tm)
+	c.applyRedactionTag(tm)
+	for _, match := range c.ignoreMatch {
+		tm.ignoreMatching(match, c.typeCache)
+	}
+	c.applyFuncChanPolicy(tm)
+	c.applyUnknownKindPolicy(tm)
+	c.markRecursive(tm)
+	c.markUnsettable(tm)
+	tm.markAuditTimes()
+}
+
 // autoConfigureMembers automatically configures member mappings based on field names.
 func (tm *TypeMap) autoConfigureMembers(cache *typeCache) {
 	destInfo := cache.getTypeInfo(tm.destType)
 
+	// Embedded structs come first so promoted members matched by name
+	// overwrite the members they share with a whole-struct match.
+	for _, destField := range destInfo.embedded {
+		if mm := tm.findEmbeddedMember(destField, cache); mm != nil {
+			tm.memberMaps = append(tm.memberMaps, mm)
+		}
+	}
+
 	for _, destField := range destInfo.fields {
 		mm := tm.findSourceMember(destField, cache)
 		if mm != nil {
@@ -215,6 +547,22 @@ func (tm *TypeMap) autoConfigureMembers(cache *typeCache) {
 	}
 }
 
+// findEmbeddedMember matches an embedded destination struct with a named
+// source member of the same name. Embedded structs on both sides map
+// through their promoted fields instead.
+func (tm *TypeMap) findEmbeddedMember(destField *fieldInfo, cache *typeCache) *MemberMap {
+	srcField, ok := cache.getTypeInfo(tm.srcType).fieldsByName[destField.name]
+	if !ok || derefType(srcField.fieldType).Kind() != reflect.Struct {
+		return nil
+	}
+	return &MemberMap{
+		destField:    destField.name,
+		destFieldIdx: destField.index,
+		srcField:     srcField.name,
+		srcFieldIdx:  srcField.index,
+	}
+}
+
 // findSourceMember finds a matching source member for a destination field.
 func (tm *TypeMap) findSourceMember(destField *fieldInfo, cache *typeCache) *MemberMap {
 	srcInfo := cache.getTypeInfo(tm.srcType)
@@ -229,6 +577,16 @@ func (tm *TypeMap) findSourceMember(destField *fieldInfo, cache *typeCache) *Mem
 		}
 	}
 
+	// Embedded struct mapped as a whole into a named member
+	if srcField, ok := srcInfo.embeddedByName[destField.name]; ok && derefType(destField.fieldType).Kind() == reflect.Struct {
+		return &MemberMap{
+			destField:    destField.name,
+			destFieldIdx: destField.index,
+			srcField:     srcField.name,
+			srcFieldIdx:  srcField.index,
+		}
+	}
+
 	// Try flattening: CustomerName -> Customer.Name
 	flattenPath := splitPascalCase(destField.name)
 	if len(flattenPath) > 1 {
@@ -1,7 +1,21 @@
 package automapper
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unsafe"
 )
 
 // Test types for basic mapping
@@ -818,4 +832,5397 @@ func TestOptimizationLevelConfiguration(t *testing.T) {
 			t.Error("useUnsafe should be true")
 		}
 	})
+
+	t.Run("WithUnsafeDisabledIf", func(t *testing.T) {
+		mapper := NewWithConfig(WithUnsafeDisabledIf(func() bool { return true }), WithSpecializedMappers())
+		if mapper.config.useUnsafe {
+			t.Error("useUnsafe should be false")
+		}
+		CreateMap[OptSource, OptDest](mapper)
+		dest, err := Map[OptDest](mapper, OptSource{ID: 5, Name: "Safe"})
+		if err != nil || dest.ID != 5 || dest.Name != "Safe" {
+			t.Errorf("unexpected result: %+v, %v", dest, err)
+		}
+
+		mapper = NewWithConfig(WithUnsafeOptimizations(), WithUnsafeDisabledIf(func() bool { return false }))
+		if !mapper.config.useUnsafe {
+			t.Error("useUnsafe should be true")
+		}
+	})
+}
+
+// TestFastPathZeroAllocs tests that primitive-only maps avoid heap allocations
+func TestFastPathZeroAllocs(t *testing.T) {
+	mapper := NewWithConfig(WithUnsafeOptimizations())
+	CreateMap[OptSource, OptDest](mapper)
+
+	src := &OptSource{ID: 7, Name: "Fast", Age: 40, Active: true, Score: 12.5}
+	dest, err := Map[OptDest](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.ID != 7 || dest.Name != "Fast" || dest.Age != 40 || !dest.Active || dest.Score != 12.5 {
+		t.Errorf("fast path mismatch: got %+v", dest)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = Map[OptDest](mapper, src)
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocs/op, got %v", allocs)
+	}
+
+	// Members configured after CreateMap must disable the fast path
+	CreateMap[OptSource, OptDest](mapper).
+		ForMemberByName("Name", MapFromFunc(func(src any, dest any) (any, error) {
+			return "resolved", nil
+		}))
+	dest, err = Map[OptDest](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "resolved" {
+		t.Errorf("Name should come from resolver: got %s", dest.Name)
+	}
+}
+
+// TestPlanCompilation tests instruction selection and recompilation after
+// configuration changes
+func TestPlanCompilation(t *testing.T) {
+	type PlanSource struct {
+		Name  string
+		Count int32
+		Items []SourceItem
+	}
+	type PlanDest struct {
+		Name  string
+		Count int64
+		Items []DestItem
+	}
+
+	mapper := New()
+	tm := CreateMap[PlanSource, PlanDest](mapper).typeMap
+
+	plan := mapper.planFor(tm)
+	want := []opCode{opCopy, opConvert, opRecurse}
+	if len(plan.instructions) != len(want) {
+		t.Fatalf("instruction count mismatch: got %d, want %d", len(plan.instructions), len(want))
+	}
+	for i, op := range want {
+		if plan.instructions[i].op != op {
+			t.Errorf("instruction %d op mismatch: got %d, want %d", i, plan.instructions[i].op, op)
+		}
+	}
+	if mapper.planFor(tm) != plan {
+		t.Error("plan should be reused while configuration is unchanged")
+	}
+
+	// Registering a converter afterwards must invalidate the plan
+	ConvertUsing(mapper, func(s string) (string, error) { return "converted_" + s, nil })
+	dest, err := Map[PlanDest](mapper, PlanSource{Name: "x", Count: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "converted_x" {
+		t.Errorf("Name mismatch: got %s, want converted_x", dest.Name)
+	}
+	if dest.Count != 3 {
+		t.Errorf("Count mismatch: got %d, want 3", dest.Count)
+	}
+}
+
+// TestMapWithOptions tests per-call ignores and derived plan caching
+func TestMapWithOptions(t *testing.T) {
+	mapper := NewWithConfig(WithSpecializedMappers())
+	tm := CreateMap[SourceBasic, DestBasic](mapper).typeMap
+
+	src := SourceBasic{Name: "Test", Age: 20, Email: "test@test.com"}
+	dest, err := MapWithOptions[DestBasic](mapper, src, WithIgnoredMembers("Email", "Age"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "Test" {
+		t.Errorf("Name mismatch: got %s, want Test", dest.Name)
+	}
+	if dest.Email != "" || dest.Age != 0 {
+		t.Errorf("ignored members should be zero: got %+v", dest)
+	}
+
+	ctx := mapper.newMappingContext(src, tm.destType, []MapOption{WithIgnoredMembers("Age", "Email")})
+	first := mapper.planForContext(ctx, tm)
+	if second := mapper.planForContext(ctx, tm); second != first {
+		t.Error("derived plan should be cached for identical option signatures")
+	}
+	if len(first.instructions) != 1 {
+		t.Errorf("derived plan should have 1 instruction, got %d", len(first.instructions))
+	}
+
+	// Reconfiguring drops the plans derived for earlier generations
+	mapper.config.invalidatePlans()
+	if third := mapper.planForContext(ctx, tm); third == first {
+		t.Error("derived plan should be recompiled after a configuration change")
+	}
+	count := 0
+	mapper.config.derivedPlans.Load().plans.Range(func(_, _ any) bool {
+		count++
+		return true
+	})
+	if count != 1 {
+		t.Errorf("expected only the current derived plan to be cached, got %d", count)
+	}
+
+	// Plain calls are unaffected by per-call options
+	dest, err = Map[DestBasic](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Email != "test@test.com" {
+		t.Errorf("Email mismatch: got %s, want test@test.com", dest.Email)
+	}
+}
+
+// Test types for embedded field conflicts
+type EmbeddedAudit struct {
+	ID      int
+	Created string
+}
+
+type EmbeddedMeta struct {
+	ID      int
+	Version int
+}
+
+type SourceEmbeddedConflict struct {
+	EmbeddedAudit
+	EmbeddedMeta
+	Version string
+}
+
+type DestEmbeddedConflict struct {
+	ID      int
+	Created string
+	Version string
+}
+
+// TestEmbeddedConflicts tests Go-style field promotion rules
+func TestEmbeddedConflicts(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceEmbeddedConflict, DestEmbeddedConflict](mapper)
+
+	src := SourceEmbeddedConflict{
+		EmbeddedAudit: EmbeddedAudit{ID: 1, Created: "today"},
+		EmbeddedMeta:  EmbeddedMeta{ID: 2, Version: 3},
+		Version:       "v4",
+	}
+
+	dest, err := Map[DestEmbeddedConflict](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if dest.ID != 0 {
+		t.Errorf("ambiguous ID should not be mapped: got %d", dest.ID)
+	}
+	if dest.Created != "today" {
+		t.Errorf("Created mismatch: got %s, want today", dest.Created)
+	}
+	if dest.Version != "v4" {
+		t.Errorf("shallower Version should win: got %s, want v4", dest.Version)
+	}
+
+	issues := mapper.ValidateConfiguration()
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 validation issue, got %d: %v", len(issues), issues)
+	}
+	if issues[0].Member != "ID" || issues[0].Severity != SeverityWarning {
+		t.Errorf("unexpected issue: %v", issues[0])
+	}
+}
+
+// Test map of structs using registered element maps
+type SourceWithStructMap struct {
+	Addresses map[string]Address
+}
+
+type DestWithStructMap struct {
+	Addresses map[string]AddressDTO
+}
+
+func TestMapOfStructsUsesTypeMap(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceWithStructMap, DestWithStructMap](mapper)
+	CreateMap[Address, AddressDTO](mapper).
+		ForMemberByName("City", MapFromFunc(func(src any, dest any) (any, error) {
+			return "City of " + src.(Address).City, nil
+		}))
+
+	src := SourceWithStructMap{
+		Addresses: map[string]Address{"home": {Street: "1 Elm", City: "Boston"}},
+	}
+
+	dest, err := Map[DestWithStructMap](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := dest.Addresses["home"].City; got != "City of Boston" {
+		t.Errorf("City mismatch: got %s, want City of Boston", got)
+	}
+
+	mapped, err := MapMap[string, Address, AddressDTO](mapper, src.Addresses)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mapped["home"]; got.City != "City of Boston" || got.Street != "1 Elm" {
+		t.Errorf("MapMap mismatch: got %+v", got)
+	}
+
+	empty, err := MapMap[string, Address, AddressDTO](mapper, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if empty == nil || len(empty) != 0 {
+		t.Errorf("nil source should produce empty map, got %v", empty)
+	}
+}
+
+// Test types for nested container compositions
+type SourceContainers struct {
+	ByGroup  map[string][]*SourceItem
+	Grid     [][]SourceItem
+	Deep     map[string][]map[int]SourceItem
+	Fixed    [2]SourceItem
+	Sparse   []*SourceItem
+	FromList []SourceItem
+}
+
+type DestContainers struct {
+	ByGroup  map[string][]*DestItem
+	Grid     [][]DestItem
+	Deep     map[string][]map[int]DestItem
+	Fixed    [2]DestItem
+	Sparse   []*DestItem
+	FromList [3]DestItem
+}
+
+func TestNestedContainerMapping(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceContainers, DestContainers](mapper)
+	CreateMap[SourceItem, DestItem](mapper)
+
+	src := SourceContainers{
+		ByGroup:  map[string][]*SourceItem{"a": {{ID: 1, Name: "A1"}, {ID: 2, Name: "A2"}}},
+		Grid:     [][]SourceItem{{{ID: 3}}, {{ID: 4}, {ID: 5}}},
+		Deep:     map[string][]map[int]SourceItem{"x": {{7: {ID: 7, Name: "seven"}}}},
+		Fixed:    [2]SourceItem{{ID: 8}, {ID: 9}},
+		Sparse:   []*SourceItem{nil, {ID: 10}},
+		FromList: []SourceItem{{ID: 11}, {ID: 12}},
+	}
+
+	dest, err := Map[DestContainers](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := dest.ByGroup["a"]; len(got) != 2 || got[1].Name != "A2" {
+		t.Errorf("ByGroup mismatch: got %+v", got)
+	}
+	if len(dest.Grid) != 2 || len(dest.Grid[1]) != 2 || dest.Grid[1][1].ID != 5 {
+		t.Errorf("Grid mismatch: got %+v", dest.Grid)
+	}
+	if got := dest.Deep["x"][0][7]; got.Name != "seven" {
+		t.Errorf("Deep mismatch: got %+v", got)
+	}
+	if dest.Fixed[1].ID != 9 {
+		t.Errorf("Fixed mismatch: got %+v", dest.Fixed)
+	}
+	if dest.Sparse[0] == nil || dest.Sparse[0].ID != 0 {
+		t.Errorf("nil source element should map to a zero value, got %+v", dest.Sparse[0])
+	}
+	if dest.Sparse[1] == nil || dest.Sparse[1].ID != 10 {
+		t.Errorf("Sparse mismatch: got %+v", dest.Sparse[1])
+	}
+	if dest.FromList[1].ID != 12 || dest.FromList[2].ID != 0 {
+		t.Errorf("FromList mismatch: got %+v", dest.FromList)
+	}
+
+	// Arrays too small for the source report an error instead of truncating
+	src.FromList = append(src.FromList, SourceItem{}, SourceItem{})
+	if _, err := Map[DestContainers](mapper, src); err == nil {
+		t.Error("expected error when source exceeds destination array length")
+	}
+
+	keepNil := NewWithConfig(WithNilElements())
+	CreateMap[SourceContainers, DestContainers](keepNil)
+	CreateMap[SourceItem, DestItem](keepNil)
+	kept, err := Map[DestContainers](keepNil, SourceContainers{Sparse: []*SourceItem{nil, {ID: 10}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kept.Sparse[0] != nil || kept.Sparse[1] == nil || kept.Sparse[1].ID != 10 {
+		t.Errorf("nil source element should stay nil, got %+v", kept.Sparse)
+	}
+}
+
+// TestCustomMapCtx tests custom mappers mapping children through the context
+func TestCustomMapCtx(t *testing.T) {
+	mapper := New()
+	CreateMap[Address, AddressDTO](mapper).
+		ForMemberByName("City", MapFromFunc(func(src any, dest any) (any, error) {
+			return "City of " + src.(Address).City, nil
+		}))
+	CreateMap[SourceNested, DestNested](mapper).
+		CustomMapCtx(func(ctx *MappingContext, src SourceNested, dest *DestNested) error {
+			if v, ok := ctx.Item("prefix"); ok {
+				dest.Name = v.(string) + src.Name
+			} else {
+				dest.Name = src.Name
+			}
+			addr, err := MapWithContext[AddressDTO](ctx, src.Address)
+			if err != nil {
+				return err
+			}
+			dest.Address = addr
+			return nil
+		})
+
+	src := SourceNested{Name: "John", Address: Address{City: "Boston"}}
+
+	dest, err := Map[DestNested](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "John" {
+		t.Errorf("Name mismatch: got %s, want John", dest.Name)
+	}
+	if dest.Address.City != "City of Boston" {
+		t.Errorf("City mismatch: got %s, want City of Boston", dest.Address.City)
+	}
+
+	dest, err = MapWithOptions[DestNested](mapper, src, WithItem("prefix", "Mr. "))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "Mr. John" {
+		t.Errorf("Name mismatch: got %s, want Mr. John", dest.Name)
+	}
+
+	var nilCtx *MappingContext
+	if nilCtx.Mapper() != nil {
+		t.Error("expected no mapper for a nil context")
+	}
+	if _, err := MapWithContext[AddressDTO](nilCtx, src.Address); err == nil {
+		t.Error("expected an error for a nil context")
+	}
+}
+
+// TestGetMap tests extending an existing mapping
+func TestGetMap(t *testing.T) {
+	mapper := New()
+
+	if _, ok := GetMap[SourceBasic, DestBasic](mapper); ok {
+		t.Fatal("GetMap should report missing mappings")
+	}
+
+	CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Age", Ignore())
+
+	b, ok := GetMap[SourceBasic, DestBasic](mapper)
+	if !ok {
+		t.Fatal("GetMap should find the registered mapping")
+	}
+	b.AfterMap(func(src *SourceBasic, dest *DestBasic) error {
+		dest.Email = "extended_" + dest.Email
+		return nil
+	})
+
+	dest, err := Map[DestBasic](mapper, SourceBasic{Name: "Test", Age: 20, Email: "a@b.c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Age != 0 {
+		t.Errorf("existing Ignore should be kept: got Age %d", dest.Age)
+	}
+	if dest.Email != "extended_a@b.c" {
+		t.Errorf("Email mismatch: got %s, want extended_a@b.c", dest.Email)
+	}
+}
+
+// TestSealOnFirstUse tests that maps reject configuration after first use
+func TestSealOnFirstUse(t *testing.T) {
+	mapper := NewWithConfig(WithSealOnFirstUse())
+	b := CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Age", Ignore())
+
+	if _, err := Map[DestBasic](mapper, SourceBasic{Name: "Test"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b.ForMemberByName("Email", Ignore())
+	if err := b.Err(); !errors.Is(err, ErrMapSealed) {
+		t.Errorf("expected builder error wrapping ErrMapSealed, got %v", err)
+	}
+	b.AfterMap(func(src *SourceBasic, dest *DestBasic) error { return errors.New("late hook") })
+	recreated := CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Name", Ignore())
+	if err := recreated.Err(); !errors.Is(err, ErrMapSealed) {
+		t.Errorf("expected CreateMap on a sealed map to fail, got %v", err)
+	}
+	err := ConvertUsing(mapper, func(s SourceBasic) (DestBasic, error) { return DestBasic{Name: "converted"}, nil })
+	if !errors.Is(err, ErrMapSealed) {
+		t.Errorf("expected ConvertUsing for the sealed pair to fail, got %v", err)
+	}
+
+	dest, err := Map[DestBasic](mapper, SourceBasic{Name: "Test", Email: "a@b.c"})
+	if err != nil {
+		t.Fatalf("expected sealed configuration to be ignored, got %v", err)
+	}
+	if dest.Name != "Test" || dest.Email != "a@b.c" {
+		t.Errorf("expected the sealed map to be unchanged, got %+v", dest)
+	}
+
+	issues := mapper.ValidateConfiguration()
+	if len(issues) != 5 {
+		t.Fatalf("expected an issue for each ignored call, got %d: %v", len(issues), issues)
+	}
+	if !strings.HasPrefix(issues[2].Message, "CreateMap called") || !strings.HasPrefix(issues[4].Message, "ConvertUsing called") {
+		t.Errorf("issues should name the operation: %v", issues)
+	}
+	for _, issue := range issues {
+		if issue.Severity != SeverityError || !strings.Contains(issue.Message, "sealed") {
+			t.Errorf("unexpected issue: %v", issue)
+		}
+	}
+
+	// Registrations for types no sealed map uses are accepted
+	if err := ConvertUsing(mapper, func(s SourceItem) (DestItem, error) { return DestItem{}, nil }); err != nil {
+		t.Errorf("unexpected error for an unrelated converter: %v", err)
+	}
+	if err := RegisterDiscriminator[DestItem](mapper, "type", "item"); err != nil {
+		t.Errorf("unexpected error for an unrelated discriminator: %v", err)
+	}
+	if err := SelectDestination(mapper, func(SourceBasic) reflect.Type { return nil }); !errors.Is(err, ErrMapSealed) {
+		t.Errorf("expected a selector for the sealed source type to fail, got %v", err)
+	}
+	if issues := mapper.ValidateConfiguration(); len(issues) != 6 {
+		t.Errorf("expected only the rejected selector to be added, got %v", issues)
+	}
+
+	// Explicit sealing works without the option
+	explicit := CreateMap[SourceItem, DestItem](New()).Seal()
+	if err := explicit.ForMemberByName("Name", Ignore()).Err(); !errors.Is(err, ErrMapSealed) {
+		t.Errorf("expected builder error wrapping ErrMapSealed, got %v", err)
+	}
+}
+
+// TestCopyOnWriteConfiguration tests that configuration swaps in a new type
+// map while concurrent mappings keep using the previous version
+func TestCopyOnWriteConfiguration(t *testing.T) {
+	mapper := New()
+	b := CreateMap[SourceBasic, DestBasic](mapper)
+	before := b.typeMap
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			if _, err := Map[DestBasic](mapper, SourceBasic{Name: "Test", Age: i}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		b.ForMemberByName("Email", MapFromFunc(func(src any, dest any) (any, error) {
+			return "resolved", nil
+		})).AfterMap(func(src *SourceBasic, dest *DestBasic) error { return nil })
+	}
+	<-done
+
+	if b.typeMap == before {
+		t.Error("builder should point to the new type map version")
+	}
+	if len(before.afterMap) != 0 || before.memberMaps[2].resolver != nil {
+		t.Error("previous type map version should not be modified")
+	}
+
+	dest, err := Map[DestBasic](mapper, SourceBasic{Email: "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Email != "resolved" {
+		t.Errorf("Email mismatch: got %s, want resolved", dest.Email)
+	}
+}
+
+// TestCheckConcurrency tests detection of configuration after mapping began
+func TestCheckConcurrency(t *testing.T) {
+	mapper := New().CheckConcurrency()
+	b := CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Age", Ignore())
+
+	if issues := mapper.ValidateConfiguration(); len(issues) != 0 {
+		t.Fatalf("configuration before mapping should be fine, got %v", issues)
+	}
+
+	if _, err := Map[DestBasic](mapper, SourceBasic{Name: "Test"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b.ForMemberByName("Email", Ignore())
+	ConvertUsing(mapper, func(s string) (int, error) { return len(s), nil })
+
+	issues := mapper.ValidateConfiguration()
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d: %v", len(issues), issues)
+	}
+	for _, issue := range issues {
+		if issue.Severity != SeverityError {
+			t.Errorf("expected error severity: %v", issue)
+		}
+		if !strings.Contains(issue.Message, "mapper_test.go") {
+			t.Errorf("issue should report the call site: %v", issue)
+		}
+	}
+	if !strings.Contains(issues[0].Message, "ForMemberByName") {
+		t.Errorf("issue should name the operation: %v", issues[0])
+	}
+}
+
+// TestCheckConcurrencyAfterReconfiguring tests that reconfigured maps stay
+// marked as used
+func TestCheckConcurrencyAfterReconfiguring(t *testing.T) {
+	mapper := New().CheckConcurrency()
+	b := CreateMap[SourceBasic, DestBasic](mapper)
+
+	if _, err := Map[DestBasic](mapper, SourceBasic{Name: "Test"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b.ForMemberByName("Age", Ignore())
+	b.ForMemberByName("Email", Ignore())
+
+	if issues := mapper.ValidateConfiguration(); len(issues) != 2 {
+		t.Fatalf("expected an issue for each late call, got %d: %v", len(issues), issues)
+	}
+}
+
+// TestMissingNestedMapDetection tests validation of unregistered nested pairs
+func TestMissingNestedMapDetection(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceNested, DestNested](mapper)
+	CreateMap[SourceWithSlice, DestWithSlice](mapper)
+
+	src := SourceWithSlice{Items: []SourceItem{{ID: 1}}}
+	issues := mapper.ValidateConfiguration()
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d: %v", len(issues), issues)
+	}
+	if issues[0].Member != "Address" || issues[1].Member != "Items" {
+		t.Errorf("issues should name the owning members: %v", issues)
+	}
+	if issues[0].Severity != SeverityError {
+		t.Errorf("unexpected severity: %v", issues[0])
+	}
+
+	// Mapping auto-creates the nested map, which must not hide the issue
+	if _, err := Map[DestWithSlice](mapper, src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if after := mapper.ValidateConfiguration(); len(after) != 2 {
+		t.Errorf("expected 2 issues after mapping, got %d: %v", len(after), after)
+	}
+
+	CreateMap[Address, AddressDTO](mapper)
+	CreateMap[SourceItem, DestItem](mapper)
+	if issues := mapper.ValidateConfiguration(); len(issues) != 0 {
+		t.Errorf("expected no issues after registering the nested map, got %v", issues)
+	}
+
+	strict := NewWithConfig(WithStrictTypeMaps())
+	CreateMap[SourceWithSlice, DestWithSlice](strict)
+	if _, err := Map[DestWithSlice](strict, src); err == nil {
+		t.Error("strict mapper should reject unregistered nested pairs")
+	}
+}
+
+// Test types for projections
+type ProjectionSource struct {
+	ID       int
+	Title    string
+	Customer Customer
+	Address  Address
+	Secret   string
+}
+
+type ProjectionDest struct {
+	ID           int
+	Title        string
+	CustomerName string
+	Address      AddressDTO
+	Secret       string
+	Label        string
+}
+
+func TestProjectFields(t *testing.T) {
+	mapper := New()
+	CreateMap[ProjectionSource, ProjectionDest](mapper).
+		ForMemberByName("Secret", Ignore()).
+		ForMemberByName("Label", MapFromFunc(func(src any, dest any) (any, error) {
+			return "label", nil
+		}))
+	CreateMap[Address, AddressDTO](mapper)
+
+	all, err := ProjectFields[ProjectionDest](mapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Address.City,Address.Street,Address.Zip,Customer.Name,ID,Title"
+	if got := strings.Join(all, ","); got != want {
+		t.Errorf("projection mismatch: got %s, want %s", got, want)
+	}
+
+	some, err := ProjectFields[ProjectionDest](mapper, "CustomerName", "Address.City")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(some, ","); got != "Address.City,Customer.Name" {
+		t.Errorf("projection mismatch: got %s", got)
+	}
+
+	if _, err := ProjectFields[ProjectionDest](mapper, "Missing"); err == nil {
+		t.Error("expected error for unknown destination member")
+	}
+}
+
+func TestMapPartial(t *testing.T) {
+	mapper := NewWithConfig(WithOptimizationLevel(OptimizationSpecialized))
+	CreateMap[ProjectionSource, ProjectionDest](mapper)
+	CreateMap[Address, AddressDTO](mapper)
+
+	src := ProjectionSource{
+		ID:       7,
+		Title:    "Order",
+		Customer: Customer{Name: "John"},
+		Address:  Address{Street: "Main", City: "Springfield", Zip: "12345"},
+		Secret:   "s3cr3t",
+	}
+
+	dest, err := MapPartial[ProjectionDest](mapper, src, []string{"ID", "Address.City"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ProjectionDest{ID: 7, Address: AddressDTO{City: "Springfield"}}
+	if dest != want {
+		t.Errorf("partial mapping mismatch: got %+v, want %+v", dest, want)
+	}
+
+	// A whole nested member selects everything beneath it
+	dest, err = MapPartial[ProjectionDest](mapper, src, []string{"Address.City", "Address"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Address.Street != "Main" || dest.Address.Zip != "12345" || dest.Title != "" {
+		t.Errorf("expected full address only, got %+v", dest)
+	}
+
+	full, err := Map[ProjectionDest](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if full.Title != "Order" || full.Address.Street != "Main" {
+		t.Errorf("field mask leaked into plain mapping: %+v", full)
+	}
+}
+
+type FieldMaskProfile struct {
+	DisplayName string `protobuf:"bytes,1,opt,name=display_name,json=displayName,proto3"`
+	UserID      int
+	Address     *AddressDTO
+	Tags        []string
+}
+
+func TestFieldMaskPaths(t *testing.T) {
+	mapper := New()
+	CreateMap[Address, AddressDTO](mapper)
+
+	before := FieldMaskProfile{DisplayName: "John", UserID: 1, Address: &AddressDTO{City: "Old"}}
+	after := before
+	after.Address = &AddressDTO{City: "Old"}
+	after.DisplayName = "Johnny"
+	if err := MapTo(mapper, Address{City: "New"}, after.Address); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := strings.Join(FieldMaskPaths(before, after), ",")
+	if got != "display_name,address.city" {
+		t.Errorf("unexpected field mask: %s", got)
+	}
+
+	paths, err := FieldMaskFromMembers[FieldMaskProfile]("UserID", "Address.City")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(paths, ","); got != "user_id,address.city" {
+		t.Errorf("unexpected field mask: %s", got)
+	}
+
+	if _, err := FieldMaskFromMembers[FieldMaskProfile]("Missing"); err == nil {
+		t.Error("expected error for unknown member")
+	}
+
+	// A struct that appears or disappears changes every member
+	created := strings.Join(FieldMaskPaths(nil, &FieldMaskProfile{DisplayName: "John"}), ",")
+	if created != "display_name,user_id,address,tags" {
+		t.Errorf("unexpected field mask for a new value: %s", created)
+	}
+	if got := FieldMaskPaths[*FieldMaskProfile](nil, nil); got == nil || len(got) != 0 {
+		t.Errorf("expected no changes between nil values, got %v", got)
+	}
+}
+
+func TestMapToTracked(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceNested, DestNested](mapper)
+	CreateMap[Address, AddressDTO](mapper)
+
+	dest := DestNested{Name: "John", Address: AddressDTO{Street: "Main", City: "Old", Zip: "12345"}}
+	src := SourceNested{Name: "John", Address: Address{Street: "Main", City: "New", Zip: "12345"}}
+
+	changed, err := MapToTracked(mapper, src, &dest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(changed, ","); got != "Address.City" {
+		t.Errorf("unexpected changed fields: %s", got)
+	}
+	if dest.Address.City != "New" {
+		t.Errorf("expected destination to be mapped, got %+v", dest)
+	}
+
+	changed, err = MapToTracked(mapper, src, &dest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("expected no changes on repeated mapping, got %v", changed)
+	}
+}
+
+func TestOnMemberAssigned(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceNested, DestNested](mapper)
+	CreateMap[Address, AddressDTO](mapper)
+
+	dest := DestNested{Name: "John", Address: AddressDTO{City: "Old"}}
+	src := SourceNested{Name: "John", Address: Address{City: "New"}}
+
+	var changes []string
+	err := MapToWithOptions(mapper, src, &dest, OnMemberAssigned(func(path string, old, new any) {
+		changes = append(changes, path+":"+old.(string)+"->"+new.(string))
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(changes, ","); got != "Address.City:Old->New" {
+		t.Errorf("unexpected observed changes: %s", got)
+	}
+	if dest.Address.City != "New" {
+		t.Errorf("expected destination to be mapped, got %+v", dest)
+	}
+}
+
+type PolicyUser struct {
+	Name  string
+	SSN   string
+	Notes string
+}
+
+type PolicyUserDTO struct {
+	Name  string
+	SSN   string
+	Notes string
+}
+
+func TestWithMemberPolicy(t *testing.T) {
+	mapper := NewWithConfig(WithOptimizationLevel(OptimizationSpecialized), WithUnsafeOptimizations())
+	CreateMap[PolicyUser, PolicyUserDTO](mapper).
+		WithMemberPolicy(func(ctx *MappingContext, member string) bool {
+			if member != "SSN" && member != "Notes" {
+				return true
+			}
+			role, _ := ctx.Item("role")
+			return role == "admin"
+		})
+
+	src := PolicyUser{Name: "John", SSN: "123-45-6789", Notes: "internal"}
+
+	public, err := Map[PolicyUserDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if public != (PolicyUserDTO{Name: "John"}) {
+		t.Errorf("expected restricted members to be omitted, got %+v", public)
+	}
+
+	admin, err := MapWithOptions[PolicyUserDTO](mapper, src, WithItem("role", "admin"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if admin != (PolicyUserDTO{Name: "John", SSN: "123-45-6789", Notes: "internal"}) {
+		t.Errorf("expected all members for admin, got %+v", admin)
+	}
+}
+
+type RedactionSource struct {
+	Name  string
+	Email string `pii:"true"`
+	Phone string `pii:"hash"`
+	Age   int    `pii:"true"`
+	Token string
+}
+
+type RedactionDest struct {
+	Name  string
+	Email string
+	Phone string
+	Age   int
+	Token string
+}
+
+func TestRedaction(t *testing.T) {
+	mapper := NewWithConfig(WithRedactionTag("pii"), WithSpecializedMappers())
+	CreateMap[RedactionSource, RedactionDest](mapper).
+		ForMemberByName("Token", Redact())
+
+	src := RedactionSource{Name: "John", Email: "john@example.com", Phone: "555-0100", Age: 42, Token: "secret"}
+	dest, err := Map[RedactionDest](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if dest.Name != "John" {
+		t.Errorf("expected Name to be mapped, got %q", dest.Name)
+	}
+	if dest.Email != "***" || dest.Token != "***" {
+		t.Errorf("expected masked values, got Email=%q Token=%q", dest.Email, dest.Token)
+	}
+	if dest.Age != 0 {
+		t.Errorf("expected non-string redacted member to be zero, got %d", dest.Age)
+	}
+	if dest.Phone != HashValue("555-0100") || dest.Phone == "555-0100" {
+		t.Errorf("expected hashed phone, got %q", dest.Phone)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper)
+	snap := mapper.Snapshot()
+
+	CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Name", MapFromFunc(func(src any, dest any) (any, error) {
+			return "overridden", nil
+		}))
+	ConvertUsing[int, string](mapper, func(i int) (string, error) { return "converted", nil })
+
+	dest, err := Map[DestBasic](mapper, SourceBasic{Name: "John"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "overridden" {
+		t.Fatalf("expected temporary configuration to apply, got %q", dest.Name)
+	}
+
+	mapper.Restore(snap)
+
+	dest, err = Map[DestBasic](mapper, SourceBasic{Name: "John"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "John" {
+		t.Errorf("expected restored configuration, got %q", dest.Name)
+	}
+	if s, err := Map[string](mapper, 1); err == nil && s == "converted" {
+		t.Error("expected converter to be removed by Restore")
+	}
+}
+
+type MemoCustomer struct {
+	ID   int
+	Name string
+}
+
+type MemoCustomerDTO struct {
+	ID      int
+	Name    string
+	Display string
+}
+
+type MemoOrder struct {
+	ID       int
+	Customer *MemoCustomer
+}
+
+type MemoOrderDTO struct {
+	ID       int
+	Customer MemoCustomerDTO
+}
+
+type MemoBatch struct {
+	Orders []MemoOrder
+}
+
+type MemoBatchDTO struct {
+	Orders []MemoOrderDTO
+}
+
+func TestWithMemoization(t *testing.T) {
+	mapper := New()
+	calls := 0
+	CreateMap[MemoCustomer, MemoCustomerDTO](mapper).
+		ForMemberByName("Display", MapFromFunc(func(src any, dest any) (any, error) {
+			calls++
+			return "#" + src.(MemoCustomer).Name, nil
+		}))
+	CreateMap[MemoOrder, MemoOrderDTO](mapper)
+	CreateMap[MemoBatch, MemoBatchDTO](mapper)
+
+	shared := &MemoCustomer{ID: 1, Name: "John"}
+	batch := MemoBatch{Orders: []MemoOrder{
+		{ID: 1, Customer: shared},
+		{ID: 2, Customer: shared},
+		{ID: 3, Customer: &MemoCustomer{ID: 1, Name: "John"}},
+		{ID: 4, Customer: &MemoCustomer{ID: 2, Name: "Jane"}},
+	}}
+
+	dest, err := MapWithOptions[MemoBatchDTO](mapper, batch, WithMemoization(16))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 customer mappings, got %d", calls)
+	}
+	if len(dest.Orders) != 4 || dest.Orders[2].Customer.Display != "#John" || dest.Orders[3].Customer.Display != "#Jane" {
+		t.Errorf("unexpected memoized result: %+v", dest.Orders)
+	}
+
+	calls = 0
+	if _, err := Map[MemoBatchDTO](mapper, batch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("expected memoization to be per call, got %d mappings", calls)
+	}
+}
+
+type GraphNode struct {
+	Name  string
+	Next  *GraphNode
+	Peers []*GraphNode
+}
+
+type GraphNodeDTO struct {
+	Name  string
+	Next  *GraphNodeDTO
+	Peers []*GraphNodeDTO
+}
+
+func TestWithPreserveReferences(t *testing.T) {
+	mapper := New()
+	CreateMap[GraphNode, GraphNodeDTO](mapper)
+
+	shared := &GraphNode{Name: "shared"}
+	root := &GraphNode{Name: "root", Next: shared, Peers: []*GraphNode{shared, shared}}
+	shared.Next = root // cycle back to the root
+
+	dest, err := MapWithOptions[*GraphNodeDTO](mapper, root, WithPreserveReferences())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Next == nil || dest.Next.Name != "shared" {
+		t.Fatalf("unexpected graph: %+v", dest)
+	}
+	if dest.Peers[0] != dest.Next || dest.Peers[1] != dest.Next {
+		t.Error("expected shared source node to map to a single destination node")
+	}
+	if dest.Next.Next != dest {
+		t.Error("expected cycle to be preserved")
+	}
+}
+
+type Category struct {
+	Name     string
+	Parent   *Category
+	Children []*Category
+}
+
+type CategoryDTO struct {
+	Name     string
+	Parent   *CategoryDTO
+	Children []*CategoryDTO
+}
+
+func TestWithBackReference(t *testing.T) {
+	mapper := New()
+	CreateMap[Category, CategoryDTO](mapper).WithBackReference("Parent")
+
+	root := &Category{Name: "root"}
+	child := &Category{Name: "child", Parent: root}
+	leaf := &Category{Name: "leaf", Parent: child}
+	child.Children = []*Category{leaf}
+	root.Children = []*Category{child}
+
+	dest, err := Map[*CategoryDTO](mapper, root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Parent != nil {
+		t.Errorf("expected root to have no parent, got %+v", dest.Parent)
+	}
+	destChild := dest.Children[0]
+	if destChild.Parent != dest {
+		t.Error("expected child to reference the mapped root")
+	}
+	if destChild.Children[0].Parent != destChild || destChild.Children[0].Name != "leaf" {
+		t.Error("expected leaf to reference the mapped child")
+	}
+}
+
+type MenuNode struct {
+	Label string
+	Items []MenuNode
+}
+
+type MenuNodeDTO struct {
+	Label string
+	Owner *MenuNodeDTO
+	Items []MenuNodeDTO
+}
+
+func TestBackReferenceIndex(t *testing.T) {
+	mapper := New()
+	CreateMap[Category, CategoryDTO](mapper).WithBackReference("Parent")
+
+	root := &Category{Name: "root", Children: []*Category{{Name: "child"}}}
+	if _, err := Map[*CategoryDTO](mapper, root); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	index := mapper.config.backRefs.Load()
+	if _, err := Map[*CategoryDTO](mapper, root); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mapper.config.backRefs.Load() != index {
+		t.Error("expected the back-reference index to be reused across mappings")
+	}
+
+	// Configuration changes rebuild the index
+	CreateMap[MenuNode, MenuNodeDTO](mapper).WithBackReference("Owner")
+	menu, err := Map[*MenuNodeDTO](mapper, MenuNode{Label: "root", Items: []MenuNode{{Label: "item"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if menu.Items[0].Owner != menu {
+		t.Error("expected a back-reference configured later to be wired")
+	}
+}
+
+type Money struct {
+	Amount float64
+}
+
+type PriceListSource struct {
+	Prices []int64
+	ByCode map[string]int64
+}
+
+type PriceListDest struct {
+	Prices []Money
+	ByCode map[string]Money
+}
+
+func TestElementConverter(t *testing.T) {
+	centsToMoney := func(src any, destType reflect.Type) (any, error) {
+		cents, ok := src.(int64)
+		if !ok {
+			return nil, errors.New("expected int64 cents")
+		}
+		return Money{Amount: float64(cents) / 100}, nil
+	}
+
+	mapper := New()
+	CreateMap[PriceListSource, PriceListDest](mapper).
+		ForMemberByName("Prices", ElementConverter(centsToMoney)).
+		ForMemberByName("ByCode", ElementConverter(centsToMoney))
+
+	dest, err := Map[PriceListDest](mapper, PriceListSource{
+		Prices: []int64{199, 2500},
+		ByCode: map[string]int64{"A": 50},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dest.Prices) != 2 || dest.Prices[0].Amount != 1.99 || dest.Prices[1].Amount != 25 {
+		t.Errorf("unexpected prices: %+v", dest.Prices)
+	}
+	if dest.ByCode["A"].Amount != 0.5 {
+		t.Errorf("unexpected map values: %+v", dest.ByCode)
+	}
+}
+
+type TagSource struct {
+	Tags []SourceItem
+}
+
+type TagDest struct {
+	Tags []DestItem
+}
+
+func TestSortBy(t *testing.T) {
+	mapper := New()
+	CreateMap[TagSource, TagDest](mapper).
+		ForMemberByName("Tags", SortBy(func(a, b DestItem) bool { return a.Name < b.Name }))
+
+	dest, err := Map[TagDest](mapper, TagSource{Tags: []SourceItem{{Name: "c"}, {Name: "a"}, {Name: "b"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dest.Tags) != 3 || dest.Tags[0].Name != "a" || dest.Tags[2].Name != "c" {
+		t.Errorf("expected sorted tags, got %+v", dest.Tags)
+	}
+
+	sorted, err := MapSliceSorted(mapper, []SourceItem{{Name: "y"}, {Name: "x"}}, func(a, b DestItem) bool {
+		return a.Name < b.Name
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sorted[0].Name != "x" {
+		t.Errorf("expected sorted slice, got %+v", sorted)
+	}
+
+	mismatched := New()
+	CreateMap[SourceItem, DestItem](mismatched)
+	CreateMap[TagSource, TagDest](mismatched).
+		ForMemberByName("Tags", SortBy(func(a, b SourceItem) bool { return a.Name < b.Name }))
+	issues := mismatched.ValidateConfiguration()
+	if len(issues) != 1 || issues[0].Member != "Tags" || issues[0].Severity != SeverityError {
+		t.Errorf("expected the element type mismatch to be reported, got %v", issues)
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	mapper := New()
+	CreateMap[TagSource, TagDest](mapper).
+		ForMemberByName("Tags",
+			Distinct(func(d DestItem) int { return d.ID }),
+			SortBy(func(a, b DestItem) bool { return a.ID < b.ID }))
+
+	src := TagSource{Tags: []SourceItem{{ID: 2, Name: "b"}, {ID: 1, Name: "a"}, {ID: 2, Name: "b (dup)"}}}
+	dest, err := Map[TagDest](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dest.Tags) != 2 || dest.Tags[0].ID != 1 || dest.Tags[1].Name != "b" {
+		t.Errorf("expected first occurrences only, got %+v", dest.Tags)
+	}
+
+	mismatched := New()
+	CreateMap[SourceItem, DestItem](mismatched)
+	CreateMap[TagSource, TagDest](mismatched).
+		ForMemberByName("Tags", Distinct(func(d *DestItem) int { return d.ID }))
+	issues := mismatched.ValidateConfiguration()
+	if len(issues) != 1 || issues[0].Member != "Tags" || issues[0].Severity != SeverityError {
+		t.Errorf("expected the element type mismatch to be reported, got %v", issues)
+	}
+}
+
+func TestMapGroupBy(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceItem, DestItem](mapper)
+
+	src := []SourceItem{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 1, Name: "c"}}
+	groups, err := MapGroupBy[SourceItem, int, DestItem](mapper, src, func(s SourceItem) int { return s.ID })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 2 || len(groups[1]) != 2 || groups[1][1].Name != "c" || groups[2][0].Name != "b" {
+		t.Errorf("unexpected groups: %+v", groups)
+	}
+}
+
+func TestMapPage(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceItem, DestItem](mapper)
+
+	page := Page[SourceItem]{Items: []SourceItem{{ID: 1, Name: "a"}}, Total: 41, Offset: 20, Limit: 10}
+	dest, err := MapPage[SourceItem, DestItem](mapper, page)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Total != 41 || dest.Offset != 20 || dest.Limit != 10 {
+		t.Errorf("expected metadata to be copied, got %+v", dest)
+	}
+	if len(dest.Items) != 1 || dest.Items[0].Name != "a" {
+		t.Errorf("unexpected items: %+v", dest.Items)
+	}
+}
+
+func TestMapResult(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceItem, DestItem](mapper)
+
+	find := func(fail bool) (SourceItem, error) {
+		if fail {
+			return SourceItem{}, errors.New("not found")
+		}
+		return SourceItem{ID: 1, Name: "a"}, nil
+	}
+
+	item, err := find(false)
+	dest, err := MapResult[SourceItem, DestItem](mapper, item, err)
+	if err != nil || dest.Name != "a" {
+		t.Errorf("unexpected result: %+v, %v", dest, err)
+	}
+
+	item, err = find(true)
+	if _, err := MapResult[SourceItem, DestItem](mapper, item, err); err == nil || err.Error() != "not found" {
+		t.Errorf("expected source error to pass through, got %v", err)
+	}
+
+	items, err := MapSliceResult[SourceItem, DestItem](mapper, []SourceItem{{ID: 2}}, nil)
+	if err != nil || len(items) != 1 || items[0].ID != 2 {
+		t.Errorf("unexpected slice result: %+v, %v", items, err)
+	}
+}
+
+// gormDeletedAt and gormModel mirror gorm.DeletedAt and gorm.Model.
+type gormDeletedAt sql.NullTime
+
+type gormModel struct {
+	ID        uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gormDeletedAt
+}
+
+type GormUser struct {
+	gormModel
+	Name     string
+	Nickname sql.NullString
+}
+
+type GormUserDTO struct {
+	ID        uint
+	CreatedAt time.Time
+	DeletedAt *time.Time
+	Deleted   bool
+	Name      string
+	Nickname  string
+}
+
+func TestGormModelMapping(t *testing.T) {
+	mapper := New()
+	CreateMap[GormUser, GormUserDTO](mapper).
+		ForMemberByName("Deleted", MapFrom("DeletedAt"))
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	user := GormUser{gormModel: gormModel{ID: 7, CreatedAt: created}, Name: "John"}
+
+	dest, err := Map[GormUserDTO](mapper, user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.ID != 7 || !dest.CreatedAt.Equal(created) || dest.Name != "John" {
+		t.Errorf("expected promoted model fields to map, got %+v", dest)
+	}
+	if dest.DeletedAt != nil || dest.Deleted || dest.Nickname != "" {
+		t.Errorf("expected invalid nullable values to stay empty, got %+v", dest)
+	}
+
+	deleted := created.Add(time.Hour)
+	user.DeletedAt = gormDeletedAt{Time: deleted, Valid: true}
+	user.Nickname = sql.NullString{String: "Johnny", Valid: true}
+	dest, err = Map[GormUserDTO](mapper, user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.DeletedAt == nil || !dest.DeletedAt.Equal(deleted) || !dest.Deleted || dest.Nickname != "Johnny" {
+		t.Errorf("expected valid nullable values to map, got %+v", dest)
+	}
+}
+
+// EntUser and EntUserEdges mirror the shape of ent-generated entities.
+type EntUser struct {
+	ID    int
+	Name  string
+	Edges EntUserEdges
+}
+
+type EntUserEdges struct {
+	Posts       []*SourceItem
+	loadedTypes [1]bool
+}
+
+func (e EntUserEdges) PostsOrErr() ([]*SourceItem, error) {
+	if e.loadedTypes[0] {
+		return e.Posts, nil
+	}
+	return nil, errors.New("posts edge was not loaded")
+}
+
+type EntUserDTO struct {
+	ID    int
+	Name  string
+	Posts []DestItem
+}
+
+func TestEntEdges(t *testing.T) {
+	mapper := New()
+	CreateMap[EntUser, EntUserDTO](mapper)
+	CreateMap[SourceItem, DestItem](mapper)
+
+	loaded := EntUser{ID: 1, Name: "John", Edges: EntUserEdges{
+		Posts:       []*SourceItem{{ID: 10, Name: "Hello"}},
+		loadedTypes: [1]bool{true},
+	}}
+	dest, err := Map[EntUserDTO](mapper, loaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dest.Posts) != 1 || dest.Posts[0].Name != "Hello" {
+		t.Errorf("expected loaded edge to map, got %+v", dest.Posts)
+	}
+
+	notLoaded := EntUser{ID: 2, Name: "Jane"}
+	dest, err = Map[EntUserDTO](mapper, notLoaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Posts != nil {
+		t.Errorf("expected not-loaded edge to be skipped, got %+v", dest.Posts)
+	}
+
+	strict := NewWithConfig(WithEdgePolicy(EdgeNotLoadedError))
+	CreateMap[EntUser, EntUserDTO](strict)
+	if _, err := Map[EntUserDTO](strict, notLoaded); err == nil {
+		t.Error("expected error for not-loaded edge")
+	}
+
+	empty := NewWithConfig(WithEdgePolicy(EdgeNotLoadedEmpty))
+	CreateMap[EntUser, EntUserDTO](empty)
+	dest, err = Map[EntUserDTO](empty, notLoaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Posts == nil || len(dest.Posts) != 0 {
+		t.Errorf("expected empty posts for not-loaded edge, got %#v", dest.Posts)
+	}
+}
+
+type fakeBinder struct {
+	name string
+	err  error
+}
+
+func (b fakeBinder) Bind(obj any) error {
+	if b.err != nil {
+		return b.err
+	}
+	obj.(*SourceBasic).Name = b.name
+	return nil
+}
+
+func TestBindAndMap(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper)
+
+	cmd, err := BindAndMap[SourceBasic, DestBasic](mapper, fakeBinder{name: "John"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd.Name != "John" {
+		t.Errorf("expected bound request to be mapped, got %+v", cmd)
+	}
+
+	bindErr := errors.New("invalid JSON")
+	_, err = BindAndMap[SourceBasic, DestBasic](mapper, fakeBinder{err: bindErr})
+	if !errors.Is(err, ErrBind) || !errors.Is(err, bindErr) {
+		t.Errorf("expected binding error to wrap ErrBind and the cause, got %v", err)
+	}
+}
+
+type UserRegisteredV1 struct {
+	UserName string
+}
+
+type UserRegisteredV2 struct {
+	FirstName string
+	LastName  string
+}
+
+type RegisterUserCommand struct {
+	Name string
+}
+
+func TestEventRegistry(t *testing.T) {
+	mapper := New()
+	registry := NewEventRegistry(mapper)
+	RegisterEvent[UserRegisteredV1, RegisterUserCommand](registry, "user.registered", "v1").
+		ForMemberByName("Name", MapFrom("UserName"))
+	RegisterEvent[UserRegisteredV2, RegisterUserCommand](registry, "user.registered", "v2").
+		ForMemberByName("Name", MapFromFunc(func(src any, dest any) (any, error) {
+			e := src.(UserRegisteredV2)
+			return e.FirstName + " " + e.LastName, nil
+		}))
+
+	cmd, err := registry.Decode("user.registered", "v1", []byte(`{"UserName":"john"}`), json.Unmarshal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd.(RegisterUserCommand).Name != "john" {
+		t.Errorf("unexpected v1 command: %+v", cmd)
+	}
+
+	cmd, err = registry.MapEvent("user.registered", "v2", UserRegisteredV2{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd.(RegisterUserCommand).Name != "John Doe" {
+		t.Errorf("unexpected v2 command: %+v", cmd)
+	}
+
+	if _, err := registry.MapEvent("user.deleted", "v1", struct{}{}); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("expected ErrUnknownEvent, got %v", err)
+	}
+}
+
+type UserDTOV1 struct {
+	Name string
+}
+
+type UserDTOV2 struct {
+	Name  string
+	Email string
+}
+
+func TestMapVersion(t *testing.T) {
+	mapper := New()
+	CreateMapVersion[SourceBasic, UserDTOV1](mapper, "v1")
+	CreateMapVersion[SourceBasic, UserDTOV2](mapper, "v2")
+
+	src := SourceBasic{Name: "John", Email: "john@example.com"}
+
+	v1, err := MapVersion[any](mapper, "v1", src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v1 != (UserDTOV1{Name: "John"}) {
+		t.Errorf("unexpected v1 result: %#v", v1)
+	}
+
+	v2, err := MapVersion[UserDTOV2](mapper, "v2", &src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v2.Email != "john@example.com" {
+		t.Errorf("unexpected v2 result: %+v", v2)
+	}
+
+	if _, err := MapVersion[UserDTOV2](mapper, "v1", src); err == nil {
+		t.Error("expected error for mismatched destination type")
+	}
+	if _, err := MapVersion[any](mapper, "v3", src); err == nil {
+		t.Error("expected error for unknown version")
+	}
+}
+
+type WeakSource struct {
+	Count   string
+	Enabled int
+	Ratio   string
+	Label   int
+}
+
+type WeakDest struct {
+	Count   int
+	Enabled bool
+	Ratio   float64
+	Label   string
+}
+
+func TestWeakTypeConversion(t *testing.T) {
+	src := WeakSource{Count: "42", Enabled: 1, Ratio: "0.5", Label: 7}
+	want := WeakDest{Count: 42, Enabled: true, Ratio: 0.5, Label: "7"}
+
+	global := NewWithConfig(WithWeakTypeConversion())
+	CreateMap[WeakSource, WeakDest](global)
+	dest, err := Map[WeakDest](global, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest != want {
+		t.Errorf("unexpected result: got %+v, want %+v", dest, want)
+	}
+
+	perMap := NewWithConfig(WithSpecializedMappers())
+	CreateMap[WeakSource, WeakDest](perMap).WithWeakTypeConversion()
+	dest, err = Map[WeakDest](perMap, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest != want {
+		t.Errorf("unexpected per-map result: got %+v, want %+v", dest, want)
+	}
+
+	if _, err := Map[WeakDest](global, WeakSource{Count: "many"}); err == nil {
+		t.Error("expected error for unparsable number")
+	}
+
+	fromMap, err := Map[WeakDest](global, map[string]any{"Count": "3", "Enabled": "true"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fromMap.Count != 3 || !fromMap.Enabled {
+		t.Errorf("unexpected map result: %+v", fromMap)
+	}
+}
+
+type PaymentMethod interface{ paymentKind() string }
+
+type CreditCard struct{ Number string }
+
+func (CreditCard) paymentKind() string { return "card" }
+
+type BankTransfer struct{ IBAN string }
+
+func (BankTransfer) paymentKind() string { return "bank" }
+
+type PaymentDTO interface{ dtoKind() string }
+
+type CreditCardDTO struct{ Number string }
+
+func (CreditCardDTO) dtoKind() string { return "card" }
+
+type BankTransferDTO struct{ IBAN string }
+
+func (*BankTransferDTO) dtoKind() string { return "bank" }
+
+type CheckoutSource struct {
+	Primary  PaymentMethod
+	Card     PaymentMethod
+	Payments []PaymentMethod
+}
+
+type CheckoutDest struct {
+	Primary  PaymentDTO
+	Card     CreditCardDTO
+	Payments []PaymentDTO
+}
+
+func TestInterfaceSourceDispatch(t *testing.T) {
+	mapper := New()
+	CreateMap[CheckoutSource, CheckoutDest](mapper)
+	CreateMap[CreditCard, CreditCardDTO](mapper).
+		ForMemberByName("Number", MapFromFunc(func(src any, dest any) (any, error) {
+			n := src.(CreditCard).Number
+			return "****" + n[len(n)-4:], nil
+		}))
+	CreateMap[BankTransfer, BankTransferDTO](mapper)
+
+	src := CheckoutSource{
+		Primary:  BankTransfer{IBAN: "DE00"},
+		Card:     CreditCard{Number: "4111111111111111"},
+		Payments: []PaymentMethod{CreditCard{Number: "5500000000000004"}, &BankTransfer{IBAN: "FR00"}},
+	}
+	dest, err := Map[CheckoutDest](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if bank, ok := dest.Primary.(*BankTransferDTO); !ok || bank.IBAN != "DE00" {
+		t.Errorf("expected *BankTransferDTO, got %#v", dest.Primary)
+	}
+	if dest.Card.Number != "****1111" {
+		t.Errorf("expected card through registered map, got %+v", dest.Card)
+	}
+	if card, ok := dest.Payments[0].(CreditCardDTO); !ok || card.Number != "****0004" {
+		t.Errorf("expected CreditCardDTO element, got %#v", dest.Payments[0])
+	}
+	if _, ok := dest.Payments[1].(*BankTransferDTO); !ok {
+		t.Errorf("expected *BankTransferDTO element, got %#v", dest.Payments[1])
+	}
+}
+
+type Document struct {
+	Kind   string
+	Number string
+	Total  float64
+}
+
+type InvoiceDTO struct {
+	Number string
+	Total  float64
+}
+
+type ReceiptDTO struct {
+	Total float64
+}
+
+func TestMapDynamic(t *testing.T) {
+	mapper := New()
+	CreateMap[Document, InvoiceDTO](mapper)
+	CreateMap[Document, ReceiptDTO](mapper)
+	SelectDestination(mapper, func(d Document) reflect.Type {
+		if d.Kind == "invoice" {
+			return reflect.TypeOf(InvoiceDTO{})
+		}
+		return reflect.TypeOf(ReceiptDTO{})
+	})
+
+	invoice, err := MapDynamic(mapper, Document{Kind: "invoice", Number: "INV-1", Total: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if invoice != (InvoiceDTO{Number: "INV-1", Total: 10}) {
+		t.Errorf("unexpected invoice result: %#v", invoice)
+	}
+
+	receipt, err := MapDynamic(mapper, &Document{Kind: "receipt", Total: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receipt != (ReceiptDTO{Total: 5}) {
+		t.Errorf("unexpected receipt result: %#v", receipt)
+	}
+
+	if _, err := MapDynamic(mapper, SourceBasic{}); err == nil {
+		t.Error("expected error for source without selector")
+	}
+}
+
+type WebhookDest struct {
+	Event   string
+	Payment PaymentDTO
+}
+
+func TestRegisterDiscriminator(t *testing.T) {
+	mapper := New()
+	RegisterDiscriminator[CreditCardDTO](mapper, "type", "credit_card")
+	RegisterDiscriminator[BankTransferDTO](mapper, "type", "bank_transfer")
+
+	card, err := MapDynamic(mapper, map[string]any{"type": "credit_card", "number": "4111"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if card != (CreditCardDTO{Number: "4111"}) {
+		t.Errorf("unexpected card result: %#v", card)
+	}
+
+	payload := map[string]any{
+		"event":   "payment.created",
+		"payment": map[string]any{"type": "bank_transfer", "IBAN": "DE89"},
+	}
+	dest, err := Map[WebhookDest](mapper, payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transfer, ok := dest.Payment.(*BankTransferDTO)
+	if !ok || transfer.IBAN != "DE89" {
+		t.Errorf("expected *BankTransferDTO payment, got %#v", dest.Payment)
+	}
+
+	if _, err := MapDynamic(mapper, map[string]any{"type": "cash"}); err == nil {
+		t.Error("expected error for unregistered discriminator value")
+	}
+}
+
+type TaggedProfile struct {
+	Name  string
+	Email string
+	SSN   string
+	Notes string
+}
+
+type TaggedProfileDTO struct {
+	Name  string
+	Email string `sensitive:"true"`
+	SSN   string `sensitive:"true"`
+	Notes string
+}
+
+func TestForMembersWithTag(t *testing.T) {
+	mapper := New()
+	CreateMap[TaggedProfile, TaggedProfileDTO](mapper).
+		ForMembersWithTag("sensitive", Ignore())
+
+	src := TaggedProfile{Name: "John", Email: "john@example.com", SSN: "123-45-6789", Notes: "vip"}
+	dest, err := Map[TaggedProfileDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest != (TaggedProfileDTO{Name: "John", Notes: "vip"}) {
+		t.Errorf("expected tagged members to be ignored, got %+v", dest)
+	}
+}
+
+type ProtoLikeSource struct {
+	Name             string
+	XXX_unrecognized []byte
+	XXX_sizecache    int32
+}
+
+type ProtoLikeDest struct {
+	Name             string
+	XXX_unrecognized []byte
+	XXX_sizecache    int32
+}
+
+type GuardedSource struct {
+	Mu    sync.Mutex
+	Count int
+}
+
+type GuardedDest struct {
+	Mu    sync.Mutex
+	Count int
+}
+
+func TestIgnoreFieldsMatching(t *testing.T) {
+	mapper := NewWithConfig(IgnoreFieldsMatching(func(f reflect.StructField) bool {
+		return strings.HasPrefix(f.Name, "XXX_")
+	}))
+	CreateMap[ProtoLikeSource, ProtoLikeDest](mapper)
+
+	proto, err := Map[ProtoLikeDest](mapper, ProtoLikeSource{Name: "John", XXX_unrecognized: []byte{1}, XXX_sizecache: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proto.Name != "John" || proto.XXX_unrecognized != nil || proto.XXX_sizecache != 0 {
+		t.Errorf("expected XXX_ fields to be ignored, got %+v", proto)
+	}
+
+	mutexType := reflect.TypeOf(sync.Mutex{})
+	CreateMap[GuardedSource, GuardedDest](mapper).
+		IgnoreFieldsMatching(func(f reflect.StructField) bool { return f.Type == mutexType })
+
+	src := &GuardedSource{Count: 3}
+	src.Mu.Lock()
+	defer src.Mu.Unlock()
+
+	var dest GuardedDest
+	if err := MapTo(mapper, src, &dest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Count != 3 || !dest.Mu.TryLock() {
+		t.Error("expected mutex field to be ignored")
+	}
+}
+
+type HandlerSource struct {
+	Name     string
+	OnChange func(string)
+	Events   chan string
+}
+
+type HandlerDest struct {
+	Name     string
+	OnChange func(string)
+	Events   chan string
+}
+
+func TestFuncChanPolicy(t *testing.T) {
+	src := HandlerSource{Name: "John", OnChange: func(string) {}, Events: make(chan string)}
+
+	mapper := New()
+	dest, err := Map[HandlerDest](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "John" || dest.OnChange != nil || dest.Events != nil {
+		t.Errorf("expected func and chan members to be skipped, got %+v", dest)
+	}
+	if issues := mapper.ValidateConfiguration(); len(issues) != 0 {
+		t.Errorf("unexpected validation issues: %v", issues)
+	}
+
+	strict := NewWithConfig(WithFuncChanPolicy(FuncChanError))
+	if _, err := Map[HandlerDest](strict, src); err == nil {
+		t.Error("expected error for func member")
+	}
+
+	CreateMap[HandlerSource, HandlerDest](strict).
+		ForMemberByName("OnChange", Ignore()).
+		ForMemberByName("Events", Ignore())
+	if _, err := Map[HandlerDest](strict, src); err != nil {
+		t.Errorf("unexpected error for ignored members: %v", err)
+	}
+}
+
+type AuditedOrder struct {
+	AuditFields
+	Number string
+}
+
+type AuditedOrderDTO struct {
+	Number    string
+	CreatedAt string
+	UpdatedAt *time.Time
+	DeletedAt string
+}
+
+type SoftDeletedRow struct {
+	CreatedAt time.Time
+	DeletedAt sql.NullTime
+}
+
+type SoftDeletedRowDTO struct {
+	CreatedAt *time.Time
+	DeletedAt string
+}
+
+func TestAuditFields(t *testing.T) {
+	mapper := NewWithConfig(WithTimeFormat(time.DateOnly))
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	deleted := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+
+	order := AuditedOrder{AuditFields: AuditFields{CreatedAt: created, DeletedAt: &deleted}, Number: "A-1"}
+	dto, err := Map[AuditedOrderDTO](mapper, order)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dto.CreatedAt != "2024-03-01" || dto.DeletedAt != "2024-04-02" {
+		t.Errorf("unexpected formatted timestamps: %+v", dto)
+	}
+	if dto.UpdatedAt != nil {
+		t.Errorf("expected zero UpdatedAt to stay nil, got %v", dto.UpdatedAt)
+	}
+
+	back, err := Map[AuditFields](mapper, dto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !back.CreatedAt.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || back.DeletedAt == nil {
+		t.Errorf("unexpected parsed timestamps: %+v", back)
+	}
+
+	row, err := Map[SoftDeletedRowDTO](mapper, SoftDeletedRow{CreatedAt: created})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if row.CreatedAt == nil || !row.CreatedAt.Equal(created) || row.DeletedAt != "" {
+		t.Errorf("unexpected row result: %+v", row)
+	}
+
+	if _, err := Map[AuditFields](mapper, AuditedOrderDTO{CreatedAt: "yesterday"}); err == nil {
+		t.Error("expected error for unparsable time")
+	}
+}
+
+type SalesReport struct {
+	Region  string
+	Revenue float64
+	Units   int
+	Closed  time.Time
+}
+
+type SalesReportView struct {
+	Region  string
+	Revenue string
+	Units   string
+	Closed  string
+}
+
+func TestDisplayLocale(t *testing.T) {
+	mapper := NewWithConfig(WithLocaleFormat("sv", LocaleFormat{DecimalSeparator: ",", GroupSeparator: " ", DateLayout: "2006-01-02"}))
+	CreateMap[SalesReport, SalesReportView](mapper).AsDisplay()
+
+	src := SalesReport{Region: "EU", Revenue: 1234567.5, Units: -12000, Closed: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		locale string
+		want   SalesReportView
+	}{
+		{"", SalesReportView{Region: "EU", Revenue: "1,234,567.5", Units: "-12,000", Closed: "03/01/2024"}},
+		{"de-DE", SalesReportView{Region: "EU", Revenue: "1.234.567,5", Units: "-12.000", Closed: "01.03.2024"}},
+		{"sv-SE", SalesReportView{Region: "EU", Revenue: "1 234 567,5", Units: "-12 000", Closed: "2024-03-01"}},
+	}
+	for _, tt := range tests {
+		got, err := MapWithOptions[SalesReportView](mapper, src, WithLocale(tt.locale))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("locale %q: expected %+v, got %+v", tt.locale, tt.want, got)
+		}
+	}
+}
+
+type PriceMinor struct {
+	Amount   int64
+	Currency string
+}
+
+type PriceDTO struct {
+	Amount   string
+	Currency string
+}
+
+type LineItemModel struct {
+	SKU   string
+	Price PriceMinor
+}
+
+type LineItemDTO struct {
+	SKU   string
+	Price PriceDTO
+}
+
+func TestConvertMoney(t *testing.T) {
+	mapper := New()
+	ConvertMoney[PriceMinor, PriceDTO](mapper)
+	ConvertMoney[PriceDTO, PriceMinor](mapper)
+	ConvertMoney[string, PriceMinor](mapper, WithRounding(RoundHalfUp))
+
+	item, err := Map[LineItemDTO](mapper, LineItemModel{SKU: "A", Price: PriceMinor{Amount: -1205, Currency: "EUR"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Price != (PriceDTO{Amount: "-12.05", Currency: "EUR"}) {
+		t.Errorf("unexpected price: %+v", item.Price)
+	}
+
+	tests := []struct {
+		src  PriceDTO
+		want int64
+	}{
+		{PriceDTO{Amount: "10.005", Currency: "USD"}, 1000},
+		{PriceDTO{Amount: "10.015", Currency: "USD"}, 1002},
+		{PriceDTO{Amount: "1234", Currency: "JPY"}, 1234},
+		{PriceDTO{Amount: "1.2345", Currency: "KWD"}, 1234},
+	}
+	for _, tt := range tests {
+		got, err := Map[PriceMinor](mapper, tt.src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Amount != tt.want || got.Currency != tt.src.Currency {
+			t.Errorf("%+v: expected %d minor units, got %+v", tt.src, tt.want, got)
+		}
+	}
+
+	parsed, err := Map[PriceMinor](mapper, "10.005 USD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed != (PriceMinor{Amount: 1001, Currency: "USD"}) {
+		t.Errorf("expected half-up rounding, got %+v", parsed)
+	}
+
+	if _, err := Map[PriceMinor](mapper, PriceDTO{Amount: "ten"}); err == nil {
+		t.Error("expected error for invalid amount")
+	}
+}
+
+type UploadModel struct {
+	SizeBytes  int64
+	PriceCents int
+	TimeoutMs  int
+}
+
+type UploadDTO struct {
+	SizeMB    float64
+	Price     float64
+	Timeout   time.Duration
+	SizeKB    uint
+	SizeBytes int64
+}
+
+func TestScale(t *testing.T) {
+	mapper := New()
+	CreateMap[UploadModel, UploadDTO](mapper).
+		ForMemberByName("SizeMB", MapFrom("SizeBytes"), Scale(1.0/(1<<20))).
+		ForMemberByName("SizeKB", MapFrom("SizeBytes"), Scale(1.0/1024)).
+		ForMemberByName("Price", MapFrom("PriceCents"), Scale(0.01)).
+		ForMemberByName("Timeout", MapFrom("TimeoutMs"), Scale(float64(time.Millisecond)))
+
+	dest, err := Map[UploadDTO](mapper, UploadModel{SizeBytes: 3 << 20, PriceCents: 1999, TimeoutMs: 1500})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := UploadDTO{SizeMB: 3, Price: 19.99, Timeout: 1500 * time.Millisecond, SizeKB: 3072, SizeBytes: 3 << 20}
+	if dest != want {
+		t.Errorf("expected %+v, got %+v", want, dest)
+	}
+
+	CreateMap[UploadDTO, UploadModel](mapper).
+		ForMemberByName("PriceCents", MapFrom("Price"), Scale(100))
+	model, err := Map[UploadModel](mapper, UploadDTO{Price: 0.29})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if model.PriceCents != 29 {
+		t.Errorf("expected rounded cents, got %d", model.PriceCents)
+	}
+
+	CreateMap[SourceBasic, UploadDTO](mapper).
+		ForMemberByName("SizeMB", MapFrom("Name"), Scale(2))
+	if _, err := Map[UploadDTO](mapper, SourceBasic{Name: "John"}); err == nil {
+		t.Error("expected error scaling a string")
+	}
+}
+
+type SignupForm struct {
+	Email    string
+	Country  string
+	Bio      string
+	Nickname *string
+}
+
+type SignupCommand struct {
+	Email    string
+	Country  string
+	Bio      string
+	Nickname string
+}
+
+func TestStringTransforms(t *testing.T) {
+	mapper := New()
+	CreateMap[SignupForm, SignupCommand](mapper).
+		ForMemberByName("Email", Trim(), Lower()).
+		ForMemberByName("Country", Upper()).
+		ForMemberByName("Bio", Trim(), Truncate(5)).
+		ForMemberByName("Nickname", Trim())
+
+	nickname := "  jd "
+	src := SignupForm{Email: "  John@Example.COM ", Country: "de", Bio: " héllo world", Nickname: &nickname}
+	dest, err := Map[SignupCommand](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := SignupCommand{Email: "john@example.com", Country: "DE", Bio: "héllo", Nickname: "jd"}
+	if dest != want {
+		t.Errorf("expected %+v, got %+v", want, dest)
+	}
+
+	negative := New()
+	CreateMap[SignupForm, SignupCommand](negative).
+		ForMemberByName("Bio", Truncate(-1))
+	dest, err = Map[SignupCommand](negative, src)
+	if err != nil || dest.Bio != "" {
+		t.Errorf("expected a negative length to truncate to empty, got %q, %v", dest.Bio, err)
+	}
+}
+
+type OrderSummarySource struct {
+	Customer string
+	Total    float64
+}
+
+type OrderSummaryDest struct {
+	Summary  string
+	Label    string
+	Customer string
+	Total    float64
+}
+
+func TestAfterOrdersMembers(t *testing.T) {
+	mapper := New()
+	CreateMap[OrderSummarySource, OrderSummaryDest](mapper).
+		ForMemberByName("Summary", After("Label", "Total"), MapFromFunc(func(src, dest any) (any, error) {
+			d := dest.(OrderSummaryDest)
+			return fmt.Sprintf("%s (%.2f)", d.Label, d.Total), nil
+		})).
+		ForMemberByName("Label", After("Customer"), MapFromFunc(func(src, dest any) (any, error) {
+			return "Order for " + dest.(OrderSummaryDest).Customer, nil
+		}))
+
+	dest, err := Map[OrderSummaryDest](mapper, OrderSummarySource{Customer: "John", Total: 12.5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Summary != "Order for John (12.50)" {
+		t.Errorf("unexpected summary: %q", dest.Summary)
+	}
+	if issues := mapper.ValidateConfiguration(); len(issues) != 0 {
+		t.Errorf("unexpected validation issues: %v", issues)
+	}
+
+	CreateMap[OrderSummarySource, OrderSummaryDest](mapper).
+		ForMemberByName("Customer", After("Total")).
+		ForMemberByName("Total", After("Customer")).
+		ForMemberByName("Label", After("Unknown"))
+	if issues := mapper.ValidateConfiguration(); len(issues) != 2 {
+		t.Errorf("expected cycle and unknown dependency issues, got %v", issues)
+	}
+}
+
+type PathNodeDTO struct {
+	Name     string
+	Path     string
+	Parent   *PathNodeDTO
+	Children []*PathNodeDTO
+}
+
+func TestFinalize(t *testing.T) {
+	for _, level := range []OptimizationLevel{OptimizationNone, OptimizationUnsafe, OptimizationSpecialized} {
+		mapper := NewWithConfig(WithOptimizationLevel(level))
+		var afterMapSawParent bool
+		CreateMap[Category, PathNodeDTO](mapper).
+			WithBackReference("Parent").
+			AfterMap(func(src *Category, dest *PathNodeDTO) error {
+				afterMapSawParent = afterMapSawParent || dest.Parent != nil
+				return nil
+			}).
+			Finalize(func(dest *PathNodeDTO) error {
+				dest.Path = dest.Name
+				if dest.Parent != nil {
+					// Parents finalize after their children, so use the name
+					dest.Path = dest.Parent.Name + "/" + dest.Name
+				}
+				return nil
+			})
+
+		root := &Category{Name: "root"}
+		root.Children = []*Category{{Name: "child", Parent: root}}
+
+		dest, err := Map[*PathNodeDTO](mapper, root)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if afterMapSawParent {
+			t.Error("expected AfterMap to run before back-references are wired")
+		}
+		if dest.Path != "root" || dest.Children[0].Path != "root/child" {
+			t.Errorf("level %d: unexpected paths %q, %q", level, dest.Path, dest.Children[0].Path)
+		}
+	}
+
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper).
+		Finalize(func(dest *DestBasic) error { return errors.New("invalid") })
+	if _, err := Map[DestBasic](mapper, SourceBasic{}); err == nil {
+		t.Error("expected finalizer error")
+	}
+}
+
+type HookInner struct{ Value int }
+
+type HookInnerDTO struct{ Value int }
+
+type HookOuter struct {
+	Inner  HookInner
+	Ptr    *HookInner
+	Inners []HookInner
+}
+
+type HookOuterDTO struct {
+	Inner  HookInnerDTO
+	Ptr    *HookInnerDTO
+	Inners []HookInnerDTO
+}
+
+func TestNestedHooks(t *testing.T) {
+	src := HookOuter{Ptr: &HookInner{}, Inners: []HookInner{{}, {}}}
+
+	for _, level := range []OptimizationLevel{OptimizationNone, OptimizationUnsafe, OptimizationSpecialized} {
+		for _, nested := range []bool{true, false} {
+			opts := []ConfigOption{WithOptimizationLevel(level)}
+			if !nested {
+				opts = append(opts, WithoutNestedHooks())
+			}
+			mapper := NewWithConfig(opts...)
+
+			var before, after, outer int
+			CreateMap[HookInner, HookInnerDTO](mapper).
+				BeforeMap(func(src *HookInner, dest *HookInnerDTO) error { before++; return nil }).
+				AfterMap(func(src *HookInner, dest *HookInnerDTO) error { after++; return nil })
+			CreateMap[HookOuter, HookOuterDTO](mapper).
+				AfterMap(func(src *HookOuter, dest *HookOuterDTO) error { outer++; return nil })
+
+			if _, err := Map[HookOuterDTO](mapper, src); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := 4
+			if !nested {
+				want = 0
+			}
+			if before != want || after != want || outer != 1 {
+				t.Errorf("level %d, nested hooks %v: expected %d nested and 1 outer hook calls, got before=%d after=%d outer=%d",
+					level, nested, want, before, after, outer)
+			}
+
+			// A top-level mapping of the nested type still runs its hooks
+			before, after = 0, 0
+			if _, err := Map[HookInnerDTO](mapper, HookInner{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if before != 1 || after != 1 {
+				t.Errorf("level %d: expected top-level hooks to run, got before=%d after=%d", level, before, after)
+			}
+		}
+	}
+}
+
+func TestBeforeMapWorkingCopy(t *testing.T) {
+	for _, level := range []OptimizationLevel{OptimizationNone, OptimizationUnsafe, OptimizationSpecialized} {
+		mapper := NewWithConfig(WithOptimizationLevel(level))
+		CreateMap[HookInner, HookInnerDTO](mapper).
+			BeforeMap(func(src *HookInner, dest *HookInnerDTO) error {
+				src.Value *= 10
+				return nil
+			}).
+			AfterMap(func(src *HookInner, dest *HookInnerDTO) error {
+				if src.Value != dest.Value {
+					t.Errorf("level %d: AfterMap saw source %d, dest %d", level, src.Value, dest.Value)
+				}
+				return nil
+			})
+		CreateMap[HookOuter, HookOuterDTO](mapper)
+
+		src := HookOuter{Inner: HookInner{Value: 1}, Ptr: &HookInner{Value: 2}, Inners: []HookInner{{Value: 3}}}
+		dest, err := Map[HookOuterDTO](mapper, &src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest.Inner.Value != 10 || dest.Ptr.Value != 20 || dest.Inners[0].Value != 30 {
+			t.Errorf("level %d: expected BeforeMap mutations to be mapped, got %+v", level, dest)
+		}
+		if src.Inner.Value != 1 || src.Ptr.Value != 2 || src.Inners[0].Value != 3 {
+			t.Errorf("level %d: expected caller's source to be unchanged, got %+v", level, src)
+		}
+	}
+}
+
+func TestHookErrorPolicy(t *testing.T) {
+	failing := func(src *HookInner, dest *HookInnerDTO) error {
+		return fmt.Errorf("bad value %d", src.Value)
+	}
+	src := HookOuter{Inner: HookInner{Value: 1}, Inners: []HookInner{{Value: 2}, {Value: 3}}}
+
+	abort := New()
+	CreateMap[HookInner, HookInnerDTO](abort).AfterMap(failing)
+	if _, err := Map[HookOuterDTO](abort, src); err == nil || err.Error() != "bad value 1" {
+		t.Errorf("expected the first hook error, got %v", err)
+	}
+
+	var logged []error
+	logging := NewWithConfig(WithHookErrorLogger(func(err error) { logged = append(logged, err) }))
+	CreateMap[HookInner, HookInnerDTO](logging).AfterMap(failing).WithHookErrorPolicy(HookErrorLog)
+	dest, err := Map[HookOuterDTO](logging, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(logged) != 3 || dest.Inners[1].Value != 3 {
+		t.Errorf("expected 3 logged errors and a complete mapping, got %v, %+v", logged, dest)
+	}
+
+	collecting := NewWithConfig(WithOptimizationLevel(OptimizationUnsafe))
+	CreateMap[HookInner, HookInnerDTO](collecting).AfterMap(failing).WithHookErrorPolicy(HookErrorCollect)
+	dest, err = Map[HookOuterDTO](collecting, src)
+	if err == nil {
+		t.Fatal("expected collected hook errors")
+	}
+	for _, want := range []string{"bad value 1", "bad value 2", "bad value 3"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	if dest.Inner.Value != 1 || dest.Inners[1].Value != 3 {
+		t.Errorf("expected a complete mapping, got %+v", dest)
+	}
+	var mappingErr *MappingError
+	if !errors.As(err, &mappingErr) {
+		t.Errorf("expected MappingError in %v", err)
+	}
+
+	if _, err := Map[HookOuterDTO](collecting, HookOuter{}); err == nil {
+		t.Error("expected hook error of a later call")
+	} else if strings.Count(err.Error(), "bad value") != 1 {
+		t.Errorf("expected errors of earlier calls to be discarded, got %v", err)
+	}
+}
+
+func TestResetMember(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Name", MapFromFunc(func(src, dest any) (any, error) { return "computed", nil })).
+		ForMemberByName("Email", Ignore(), Upper())
+
+	// Another package extends the shared map and restores the convention
+	builder, ok := GetMap[SourceBasic, DestBasic](mapper)
+	if !ok {
+		t.Fatal("expected registered map")
+	}
+	builder.ResetMember("Name").ResetMember("Email")
+
+	src := SourceBasic{Name: "John", Email: "john@example.com", Age: 30}
+	dest, err := Map[DestBasic](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "John" || dest.Email != "john@example.com" || dest.Age != 30 {
+		t.Errorf("expected conventional mapping after reset, got %+v", dest)
+	}
+}
+
+type BaseEntity struct {
+	ID        int
+	Code      string
+	CreatedBy string
+}
+
+type BaseEntityDTO struct {
+	ID        int
+	Code      string
+	CreatedBy string
+}
+
+type CustomerEntity struct {
+	BaseEntity
+	Name string
+}
+
+type CustomerEntityDTO struct {
+	BaseEntityDTO
+	Name string
+}
+
+func TestIncludeBase(t *testing.T) {
+	mapper := New()
+	CreateMap[BaseEntity, BaseEntityDTO](mapper).
+		ForMemberByName("Code", MapFromFunc(func(src, dest any) (any, error) {
+			return "E-" + src.(BaseEntity).Code, nil
+		})).
+		ForMemberByName("CreatedBy", Ignore()).
+		ForMemberByName("ID", Condition(func(src any) bool { return src.(BaseEntity).ID > 0 }))
+
+	IncludeBase[BaseEntity, BaseEntityDTO](CreateMap[CustomerEntity, CustomerEntityDTO](mapper))
+
+	src := CustomerEntity{BaseEntity: BaseEntity{ID: 7, Code: "42", CreatedBy: "admin"}, Name: "Acme"}
+	dest, err := Map[CustomerEntityDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.ID != 7 || dest.Code != "E-42" || dest.CreatedBy != "" || dest.Name != "Acme" {
+		t.Errorf("expected inherited base configuration, got %+v", dest)
+	}
+
+	src.ID = -1
+	dest, err = Map[CustomerEntityDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.ID != 0 {
+		t.Errorf("expected inherited condition to skip ID, got %d", dest.ID)
+	}
+
+	unregistered := New()
+	b := IncludeBase[BaseEntity, BaseEntityDTO](CreateMap[CustomerEntity, CustomerEntityDTO](unregistered))
+	var mappingErr *MappingError
+	if err := b.Err(); !errors.As(err, &mappingErr) || mappingErr.Code != CodeNoTypeMap {
+		t.Errorf("expected an error for an unregistered base map, got %v", err)
+	}
+	if issues := unregistered.ValidateConfiguration(); len(issues) != 1 || issues[0].Severity != SeverityError {
+		t.Errorf("expected the unregistered base map to be reported, got %v", issues)
+	}
+}
+
+type TenantEntity struct {
+	BaseEntity
+}
+
+type TenantUser struct {
+	TenantEntity
+	Name string
+}
+
+type TenantEntityDTO struct {
+	BaseEntityDTO
+}
+
+type TenantUserDTO struct {
+	TenantEntityDTO
+	Name string
+}
+
+func TestIncludeBaseEmbeddedDeeper(t *testing.T) {
+	mapper := New()
+	CreateMap[BaseEntity, BaseEntityDTO](mapper).
+		ForMemberByName("ID", MapFromFunc(func(src, dest any) (any, error) {
+			return src.(BaseEntity).ID * 2, nil
+		}))
+	IncludeBase[BaseEntity, BaseEntityDTO](CreateMap[TenantUser, TenantUserDTO](mapper))
+
+	dest, err := Map[TenantUserDTO](mapper, TenantUser{TenantEntity: TenantEntity{BaseEntity{ID: 7}}, Name: "Ann"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.ID != 14 || dest.Name != "Ann" {
+		t.Errorf("expected the resolver to receive the nested base, got %+v", dest)
+	}
+}
+
+type AuditInfo struct {
+	CreatedBy string
+	Revision  int
+}
+
+type AuditInfoDTO struct {
+	CreatedBy string
+	Revision  int
+}
+
+type AuditedDocument struct {
+	AuditInfo
+	Title string
+}
+
+type AuditedDocumentDTO struct {
+	Title     string
+	AuditInfo AuditInfoDTO
+	Meta      AuditInfoDTO
+}
+
+func TestEmbeddedToNamedMember(t *testing.T) {
+	mapper := New()
+	CreateMap[AuditInfo, AuditInfoDTO](mapper)
+	CreateMap[AuditInfoDTO, AuditInfo](mapper)
+	CreateMap[AuditedDocument, AuditedDocumentDTO](mapper).
+		ForMemberByName("Meta", MapFrom("AuditInfo"))
+	CreateMap[AuditedDocumentDTO, AuditedDocument](mapper).
+		ForMemberByName("AuditInfo", MapFrom("Meta"))
+
+	src := AuditedDocument{AuditInfo: AuditInfo{CreatedBy: "admin", Revision: 3}, Title: "Spec"}
+	dest, err := Map[AuditedDocumentDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := AuditInfoDTO{CreatedBy: "admin", Revision: 3}
+	if dest.Title != "Spec" || dest.AuditInfo != want || dest.Meta != want {
+		t.Errorf("expected embedded struct in named members, got %+v", dest)
+	}
+
+	back, err := Map[AuditedDocument](mapper, AuditedDocumentDTO{Title: "Spec", Meta: want})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if back.Title != "Spec" || back.CreatedBy != "admin" || back.Revision != 3 {
+		t.Errorf("expected named member in embedded struct, got %+v", back)
+	}
+}
+
+type PersonName struct {
+	FirstName string
+	LastName  string
+	Title     *string
+}
+
+type PersonNameDTO struct {
+	FullName string
+}
+
+func TestMapFromFields(t *testing.T) {
+	mapper := New()
+	CreateMap[PersonName, PersonNameDTO](mapper).
+		ForMemberByName("FullName", MapFromFields([]string{"Title", "FirstName", "LastName"},
+			func(vals ...any) (any, error) {
+				name := vals[1].(string) + " " + vals[2].(string)
+				if title := vals[0].(*string); title != nil {
+					name = *title + " " + name
+				}
+				return name, nil
+			}))
+
+	dest, err := Map[PersonNameDTO](mapper, PersonName{FirstName: "Ada", LastName: "Lovelace"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.FullName != "Ada Lovelace" {
+		t.Errorf("expected 'Ada Lovelace', got %q", dest.FullName)
+	}
+
+	paths, err := ProjectFields[PersonNameDTO](mapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(paths, []string{"FirstName", "LastName", "Title"}) {
+		t.Errorf("expected declared source fields, got %v", paths)
+	}
+	if issues := mapper.ValidateConfiguration(); len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+
+	CreateMap[PersonName, PersonNameDTO](mapper).
+		ForMemberByName("FullName", MapFromFields([]string{"FirstName", "Surname"},
+			func(vals ...any) (any, error) { return nil, nil }))
+	issues := mapper.ValidateConfiguration()
+	if len(issues) != 1 || issues[0].Severity != SeverityError || !strings.Contains(issues[0].Message, "Surname") {
+		t.Errorf("expected missing source field error, got %v", issues)
+	}
+}
+
+type ContactProfile struct {
+	NickName  *string
+	FirstName string
+	Email     string
+}
+
+type ContactProfileDTO struct {
+	DisplayName string
+}
+
+func TestCoalesce(t *testing.T) {
+	mapper := New()
+	CreateMap[ContactProfile, ContactProfileDTO](mapper).
+		ForMemberByName("DisplayName", Coalesce("NickName", "FirstName", "Email"))
+
+	nick := "Ace"
+	tests := []struct {
+		src  ContactProfile
+		want string
+	}{
+		{ContactProfile{NickName: &nick, FirstName: "Alice", Email: "a@example.com"}, "Ace"},
+		{ContactProfile{FirstName: "Alice", Email: "a@example.com"}, "Alice"},
+		{ContactProfile{Email: "a@example.com"}, "a@example.com"},
+		{ContactProfile{}, ""},
+	}
+	for _, tt := range tests {
+		dest, err := Map[ContactProfileDTO](mapper, tt.src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest.DisplayName != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, dest.DisplayName)
+		}
+	}
+}
+
+type ShippingAddress struct {
+	City string
+}
+
+type ShippingAddressDTO struct {
+	City string
+}
+
+type ShippingCustomer struct {
+	Addresses []ShippingAddress
+	Phones    [2]string
+}
+
+type ShippingCustomerDTO struct {
+	PrimaryCity    string
+	PrimaryAddress ShippingAddressDTO
+	BackupPhone    string
+}
+
+func TestMapFromIndexedPath(t *testing.T) {
+	mapper := New()
+	CreateMap[ShippingAddress, ShippingAddressDTO](mapper)
+	CreateMap[ShippingCustomer, ShippingCustomerDTO](mapper).
+		ForMemberByName("PrimaryCity", MapFrom("Addresses[0].City")).
+		ForMemberByName("PrimaryAddress", First("Addresses", "")).
+		ForMemberByName("BackupPhone", MapFrom("Phones[1]"))
+
+	src := ShippingCustomer{
+		Addresses: []ShippingAddress{{City: "Berlin"}, {City: "Paris"}},
+		Phones:    [2]string{"111", "222"},
+	}
+	dest, err := Map[ShippingCustomerDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.PrimaryCity != "Berlin" || dest.PrimaryAddress.City != "Berlin" || dest.BackupPhone != "222" {
+		t.Errorf("expected primary elements, got %+v", dest)
+	}
+
+	// Empty collections leave the members unchanged
+	dest, err = Map[ShippingCustomerDTO](mapper, ShippingCustomer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.PrimaryCity != "" || dest.PrimaryAddress.City != "" {
+		t.Errorf("expected empty members, got %+v", dest)
+	}
+	if issues := mapper.ValidateConfiguration(); len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+}
+
+type BillingLine struct {
+	SKU   string
+	Price int
+	Card  string `pii:"true"`
+}
+
+type BillingLineDTO struct {
+	SKU   string
+	Price string
+}
+
+type Billing struct {
+	Lines []BillingLine
+}
+
+type BillingDTO struct {
+	Lines []BillingLineDTO
+}
+
+func TestResolverErrorPath(t *testing.T) {
+	mapper := NewWithConfig(WithErrorSourceSnapshot(), WithRedactionTag("pii"))
+	CreateMap[BillingLine, BillingLineDTO](mapper).
+		ForMemberByName("Price", MapFromFunc(func(src, dest any) (any, error) {
+			line := src.(BillingLine)
+			if line.Price < 0 {
+				return nil, errors.New("negative price")
+			}
+			return fmt.Sprint(line.Price), nil
+		}))
+	CreateMap[Billing, BillingDTO](mapper)
+
+	src := Billing{Lines: []BillingLine{{SKU: "A", Price: 1}, {SKU: "B", Price: -5, Card: "4111"}}}
+	_, err := Map[BillingDTO](mapper, src)
+
+	var mappingErr *MappingError
+	if !errors.As(err, &mappingErr) {
+		t.Fatalf("expected MappingError, got %v", err)
+	}
+	if mappingErr.Path != "Lines[1].Price" {
+		t.Errorf("expected path 'Lines[1].Price', got %q", mappingErr.Path)
+	}
+	if !strings.Contains(err.Error(), "Lines[1].Price") {
+		t.Errorf("expected path in error message, got %q", err.Error())
+	}
+
+	var resolverErr *MappingError
+	for e := error(mappingErr); e != nil; e = errors.Unwrap(e) {
+		if me, ok := e.(*MappingError); ok && me.Message == "resolver error" {
+			resolverErr = me
+		}
+	}
+	if resolverErr == nil {
+		t.Fatalf("expected resolver error in chain, got %v", err)
+	}
+	want := map[string]any{"SKU": "B", "Price": -5, "Card": "***"}
+	if !reflect.DeepEqual(resolverErr.Source, want) {
+		t.Errorf("expected redacted snapshot %v, got %v", want, resolverErr.Source)
+	}
+
+	data, jsonErr := json.Marshal(resolverErr)
+	if jsonErr != nil {
+		t.Fatalf("unexpected error: %v", jsonErr)
+	}
+	if strings.Contains(string(data), "source\"") || strings.Contains(string(data), "SKU") {
+		t.Errorf("expected JSON without the source snapshot, got %s", data)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	calls := 0
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Name", MapFromFunc(func(src, dest any) (any, error) {
+			calls++
+			if calls < 3 {
+				return nil, errors.New("lookup unavailable")
+			}
+			return "enriched", nil
+		}), WithRetry(3, time.Millisecond))
+
+	dest, err := Map[DestBasic](mapper, SourceBasic{Name: "John"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "enriched" || calls != 3 {
+		t.Errorf("expected success on third attempt, got %q after %d calls", dest.Name, calls)
+	}
+
+	// Retries stop once the call's context is done
+	mapper = New()
+	CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Name", MapFromFunc(func(src, dest any) (any, error) {
+			return nil, errors.New("lookup unavailable")
+		}), WithRetry(5, time.Second))
+
+	goCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = MapWithOptions[DestBasic](mapper, SourceBasic{Name: "John"}, WithContext(goCtx))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected retries to stop at the deadline, took %v", elapsed)
+	}
+}
+
+type regionBatcher struct {
+	calls int
+}
+
+func (r *regionBatcher) ResolveBatch(_ context.Context, srcs []any) ([]any, error) {
+	r.calls++
+	regions := make([]any, len(srcs))
+	for i, src := range srcs {
+		regions[i] = "region-" + src.(SourceBasic).Name
+	}
+	return regions, nil
+}
+
+func TestBatchResolver(t *testing.T) {
+	batcher := &regionBatcher{}
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Email", MapFromBatch(batcher))
+
+	src := []SourceBasic{{Name: "a", Age: 1}, {Name: "b", Age: 2}, {Name: "c", Age: 3}}
+	dests, err := MapSlice[SourceBasic, DestBasic](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if batcher.calls != 1 {
+		t.Errorf("expected one batch call, got %d", batcher.calls)
+	}
+	for i, dest := range dests {
+		if dest.Email != "region-"+src[i].Name || dest.Age != src[i].Age {
+			t.Errorf("unexpected element %d: %+v", i, dest)
+		}
+	}
+
+	// Single mappings resolve a batch of one
+	dest, err := Map[DestBasic](mapper, SourceBasic{Name: "d"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Email != "region-d" || batcher.calls != 2 {
+		t.Errorf("expected single batch call, got %+v after %d calls", dest, batcher.calls)
+	}
+}
+
+func TestConcurrentResolvers(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	slow := func(value string) ValueResolver {
+		return func(src, dest any) (any, error) {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return value, nil
+		}
+	}
+
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Name", MapFromFunc(slow("name"))).
+		ForMemberByName("Email", MapFromFunc(slow("email"))).
+		WithConcurrentResolvers(2)
+
+	dest, err := Map[DestBasic](mapper, SourceBasic{Age: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "name" || dest.Email != "email" || dest.Age != 5 {
+		t.Errorf("unexpected result: %+v", dest)
+	}
+	if peak != 2 {
+		t.Errorf("expected resolvers to run concurrently, peak was %d", peak)
+	}
+
+	failing := New()
+	CreateMap[SourceBasic, DestBasic](failing).
+		ForMemberByName("Name", MapFromFunc(slow("name"))).
+		ForMemberByName("Email", MapFromFunc(func(src, dest any) (any, error) {
+			return nil, errors.New("lookup failed")
+		})).
+		WithConcurrentResolvers(2)
+
+	_, err = Map[DestBasic](failing, SourceBasic{})
+	var mappingErr *MappingError
+	if !errors.As(err, &mappingErr) || mappingErr.FieldName != "Email" {
+		t.Errorf("expected resolver error for Email, got %v", err)
+	}
+
+	// A panicking resolver fails the call, and members not yet resolved
+	// are skipped rather than resolved one by one
+	var emailCalls atomic.Int32
+	panicking := New()
+	CreateMap[SourceBasic, DestBasic](panicking).
+		ForMemberByName("Name", MapFromFunc(func(src, dest any) (any, error) {
+			panic("lookup crashed")
+		})).
+		ForMemberByName("Age", MapFromFunc(func(src, dest any) (any, error) {
+			time.Sleep(20 * time.Millisecond)
+			return 1, nil
+		})).
+		ForMemberByName("Email", MapFromFunc(func(src, dest any) (any, error) {
+			emailCalls.Add(1)
+			return "email", nil
+		})).
+		WithConcurrentResolvers(2)
+
+	_, err = Map[DestBasic](panicking, SourceBasic{})
+	if !errors.As(err, &mappingErr) || mappingErr.FieldName != "Name" {
+		t.Errorf("expected resolver error for Name, got %v", err)
+	}
+	if n := emailCalls.Load(); n != 0 {
+		t.Errorf("expected Email to be skipped, resolved %d times", n)
+	}
+}
+
+func TestPlanExecute(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Email", Upper())
+
+	plan, err := PlanOf[SourceBasic, DestBasic](mapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := map[string]Op{}
+	for _, ins := range plan.Instructions {
+		ops[ins.Member] = ins.Op
+	}
+	if ops["Name"] != OpCopy || ops["Age"] != OpCopy || ops["Email"] != OpResolve {
+		t.Errorf("unexpected plan operations: %v", ops)
+	}
+
+	// Plans survive a round trip through JSON
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var restored Plan
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var dest DestBasic
+	if err := mapper.Execute(&restored, SourceBasic{Name: "John", Age: 30, Email: "j@example.com"}, &dest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest != (DestBasic{Name: "John", Age: 30, Email: "J@EXAMPLE.COM"}) {
+		t.Errorf("unexpected result: %+v", dest)
+	}
+
+	restored.Instructions[0].Member = "Missing"
+	if err := mapper.Execute(&restored, SourceBasic{}, &dest); err == nil {
+		t.Error("expected error for unknown plan member")
+	}
+}
+
+type TenantRecord struct {
+	TenantID string
+	Name     string
+}
+
+type TenantRecordDTO struct {
+	TenantID string
+	Name     string
+}
+
+func TestOnCompile(t *testing.T) {
+	mapper := New()
+	mapper.OnCompile(func(tm *TypeMapView) error {
+		for _, name := range tm.Members() {
+			if name == "TenantID" {
+				if err := tm.ForMember(name, Ignore()); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	CreateMap[TenantRecord, TenantRecordDTO](mapper)
+
+	dest, err := Map[TenantRecordDTO](mapper, TenantRecord{TenantID: "t1", Name: "Widget"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.TenantID != "" || dest.Name != "Widget" {
+		t.Errorf("expected hook to ignore TenantID, got %+v", dest)
+	}
+
+	// Hook errors fail automatically created type maps
+	failing := New()
+	failing.OnCompile(func(tm *TypeMapView) error {
+		if tm.DestType() == reflect.TypeOf(TenantRecordDTO{}) {
+			return errors.New("tenant maps must be registered explicitly")
+		}
+		return nil
+	})
+	if _, err := Map[TenantRecordDTO](failing, TenantRecord{}); err == nil {
+		t.Error("expected compile hook error")
+	}
+}
+
+type Envelope[T any] struct {
+	Data  T
+	Items []T
+	Trace string
+}
+
+type AuditedEnvelope[T any] struct {
+	Envelope[T]
+	Version int
+}
+
+func TestCreateWrapperMap(t *testing.T) {
+	mapper := New()
+	CreateWrapperMap[Envelope[SourceBasic], Envelope[DestBasic], SourceBasic, DestBasic](mapper).
+		ForMemberByName("Trace", Ignore())
+	CreateWrapperMap[Envelope[Category], Envelope[CategoryDTO], Category, CategoryDTO](mapper)
+
+	// Distinct instantiations get distinct type maps
+	src := Envelope[SourceBasic]{Data: SourceBasic{Name: "John"}, Items: []SourceBasic{{Age: 3}}, Trace: "abc"}
+	dest, err := Map[Envelope[DestBasic]](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Data.Name != "John" || len(dest.Items) != 1 || dest.Items[0].Age != 3 || dest.Trace != "" {
+		t.Errorf("unexpected envelope: %+v", dest)
+	}
+	other, err := Map[Envelope[CategoryDTO]](mapper, Envelope[Category]{Trace: "xyz"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other.Trace != "xyz" {
+		t.Errorf("expected Trace mapped for other instantiation, got %+v", other)
+	}
+
+	// Embedded generic bases are found by type
+	CreateMap[Envelope[SourceBasic], Envelope[DestBasic]](mapper).
+		ForMemberByName("Trace", MapFromFunc(func(src, dest any) (any, error) { return "base", nil }))
+	IncludeBase[Envelope[SourceBasic], Envelope[DestBasic]](CreateMap[AuditedEnvelope[SourceBasic], AuditedEnvelope[DestBasic]](mapper))
+	audited, err := Map[AuditedEnvelope[DestBasic]](mapper, AuditedEnvelope[SourceBasic]{Version: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if audited.Trace != "base" || audited.Version != 2 {
+		t.Errorf("expected inherited configuration, got %+v", audited)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for envelope without payload member")
+		}
+	}()
+	CreateWrapperMap[SourceBasic, DestBasic, Category, CategoryDTO](mapper)
+}
+
+func TestNilSourcePolicy(t *testing.T) {
+	var nilSrc *SourceBasic
+
+	dest, err := Map[DestBasic](New(), nilSrc)
+	if err != nil || dest != (DestBasic{}) {
+		t.Errorf("expected zero destination by default, got %+v, %v", dest, err)
+	}
+
+	nilMapper := NewWithConfig(WithNilSourcePolicy(NilSourceNil))
+	ptr, err := MapPtr[DestBasic](nilMapper, nilSrc)
+	if err != nil || ptr != nil {
+		t.Errorf("expected nil destination, got %+v, %v", ptr, err)
+	}
+	ptr, err = MapPtr[DestBasic](nilMapper, &SourceBasic{Name: "John"})
+	if err != nil || ptr == nil || ptr.Name != "John" {
+		t.Errorf("expected mapped destination, got %+v, %v", ptr, err)
+	}
+
+	errMapper := NewWithConfig(WithNilSourcePolicy(NilSourceError))
+	if _, err := Map[DestBasic](errMapper, nilSrc); !errors.Is(err, ErrNilSource) {
+		t.Errorf("expected ErrNilSource, got %v", err)
+	}
+	var existing DestBasic
+	if err := MapTo(errMapper, nil, &existing); !errors.Is(err, ErrNilSource) {
+		t.Errorf("expected ErrNilSource from MapTo, got %v", err)
+	}
+	CreateMapVersion[SourceBasic, DestBasic](errMapper, "v1")
+	if _, err := MapVersion[any](errMapper, "v1", nilSrc); !errors.Is(err, ErrNilSource) {
+		t.Errorf("expected ErrNilSource from MapVersion, got %v", err)
+	}
+	if _, err := MapToTracked(errMapper, nilSrc, &existing); !errors.Is(err, ErrNilSource) {
+		t.Errorf("expected ErrNilSource from MapToTracked, got %v", err)
+	}
+	registry := NewEventRegistry(errMapper)
+	RegisterEvent[SourceBasic, DestBasic](registry, "basic.created", "v1")
+	if _, err := registry.MapEvent("basic.created", "v1", nilSrc); !errors.Is(err, ErrNilSource) {
+		t.Errorf("expected ErrNilSource from MapEvent, got %v", err)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Name", MapFromFunc(func(src, dest any) (any, error) {
+			return nil, errors.New("lookup failed")
+		}))
+
+	_, err := MapSlice[SourceBasic, DestBasic](mapper, []SourceBasic{{}})
+	var mappingErr *MappingError
+	if !errors.As(err, &mappingErr) {
+		t.Fatalf("expected MappingError, got %v", err)
+	}
+	if code := mappingErr.ErrorCode(); code != CodeResolver {
+		t.Errorf("expected code %q from wrapped resolver error, got %q", CodeResolver, code)
+	}
+
+	_, err = Map[DestBasic](mapper, SourceBasic{})
+	data, jsonErr := json.Marshal(err)
+	if jsonErr != nil {
+		t.Fatalf("unexpected error: %v", jsonErr)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body["code"] != "resolver" || body["path"] != "Name" || body["cause"] != "lookup failed" {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+
+	strict := NewWithConfig(WithStrictTypeMaps())
+	_, err = Map[DestBasic](strict, SourceBasic{})
+	if !errors.As(err, &mappingErr) || mappingErr.ErrorCode() != CodeNoTypeMap {
+		t.Errorf("expected %q, got %v", CodeNoTypeMap, err)
+	}
+
+	err = MapToAny(New(), SourceBasic{}, nil)
+	if !errors.As(err, &mappingErr) || mappingErr.Code != CodeInvalidArgument {
+		t.Errorf("expected %q, got %v", CodeInvalidArgument, err)
+	}
+
+	elements := New()
+	CreateMap[SourceBasic, DestBasic](elements).
+		ForMemberByName("Name", ElementConverter(func(src any, destType reflect.Type) (any, error) {
+			return src, nil
+		}))
+	_, err = Map[DestBasic](elements, SourceBasic{Name: "John"})
+	var inner *MappingError
+	if !errors.As(err, &mappingErr) || !errors.As(mappingErr.InnerError, &inner) || inner.Code != CodeIncompatibleTypes {
+		t.Errorf("expected a cause with code %q, got %v", CodeIncompatibleTypes, err)
+	}
+}
+
+type ListNode struct {
+	Value int
+	Next  *ListNode
+}
+
+type ListNodeDTO struct {
+	Value int
+	Next  *ListNodeDTO
+}
+
+func TestRecursiveTypeGuard(t *testing.T) {
+	mapper := New()
+
+	// Long acyclic lists map normally
+	var head *ListNode
+	for i := 0; i < 500; i++ {
+		head = &ListNode{Value: i, Next: head}
+	}
+	dest, err := Map[ListNodeDTO](mapper, head)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Value != 499 || dest.Next == nil || dest.Next.Value != 498 {
+		t.Errorf("unexpected list head: %+v", dest)
+	}
+
+	// Cycles fail with a clear error instead of overflowing the stack
+	cyclic := &ListNode{Value: 1}
+	cyclic.Next = &ListNode{Value: 2, Next: cyclic}
+	_, err = Map[ListNodeDTO](mapper, cyclic)
+	var mappingErr *MappingError
+	if !errors.As(err, &mappingErr) || mappingErr.ErrorCode() != CodeRecursion {
+		t.Errorf("expected recursion error, got %v", err)
+	}
+
+	shallow := NewWithConfig(WithMaxDepth(10))
+	if _, err := Map[ListNodeDTO](shallow, head); err == nil {
+		t.Error("expected error beyond the configured depth")
+	}
+}
+
+type CredentialEntity struct {
+	ID       int
+	Username string
+	Password string
+}
+
+type credentialRecord struct {
+	ID       int
+	Username string
+	password string
+}
+
+func TestErrorOnUnsettableDest(t *testing.T) {
+	src := CredentialEntity{ID: 1, Username: "ada", Password: "secret"}
+
+	// By default the unexported field is skipped
+	lenient := New()
+	dest, err := Map[credentialRecord](lenient, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Username != "ada" || dest.password != "" {
+		t.Errorf("unexpected result: %+v", dest)
+	}
+
+	strict := NewWithConfig(WithErrorOnUnsettableDest())
+	CreateMap[CredentialEntity, credentialRecord](strict)
+	_, err = Map[credentialRecord](strict, src)
+	var mappingErr *MappingError
+	if !errors.As(err, &mappingErr) || mappingErr.ErrorCode() != CodeUnsettable || mappingErr.FieldName != "password" {
+		t.Fatalf("expected unsettable error for 'password', got %v", err)
+	}
+
+	issues := strict.ValidateConfiguration()
+	if len(issues) != 1 || issues[0].Member != "password" || issues[0].Severity != SeverityError {
+		t.Errorf("expected one error issue for 'password', got %v", issues)
+	}
+
+	// Acknowledged fields are not reported
+	acknowledged := NewWithConfig(WithErrorOnUnsettableDest(), IgnoreFieldsMatching(func(f reflect.StructField) bool {
+		return f.Name == "password"
+	}))
+	if _, err := Map[credentialRecord](acknowledged, src); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Without the option the dropped field is only a warning
+	CreateMap[CredentialEntity, credentialRecord](lenient)
+	issues = lenient.ValidateConfiguration()
+	if len(issues) != 1 || issues[0].Severity != SeverityWarning {
+		t.Errorf("expected one warning for 'password', got %v", issues)
+	}
+}
+
+type lockedCounter struct {
+	mu    sync.Mutex
+	Count int
+}
+
+type lockedCounterDTO struct {
+	mu    sync.Mutex
+	Count int
+}
+
+func TestUnsettableIgnoresUnexportedSources(t *testing.T) {
+	strict := NewWithConfig(WithErrorOnUnsettableDest())
+	CreateMap[lockedCounter, lockedCounterDTO](strict)
+
+	dest, err := Map[lockedCounterDTO](strict, &lockedCounter{Count: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Count != 3 {
+		t.Errorf("unexpected result: %+v", dest.Count)
+	}
+	if issues := strict.ValidateConfiguration(); len(issues) != 0 {
+		t.Errorf("expected no issues for unexported source fields, got %v", issues)
+	}
+}
+
+type MeasurementEntity struct {
+	Count int32
+	Unit  string
+}
+
+type MeasurementUnit string
+
+type MeasurementDTO struct {
+	Count int64
+	Unit  MeasurementUnit
+}
+
+func TestDisableImplicitConversion(t *testing.T) {
+	src := MeasurementEntity{Count: 3, Unit: "kg"}
+
+	dest, err := Map[MeasurementDTO](New(), src)
+	if err != nil || dest.Count != 3 || dest.Unit != "kg" {
+		t.Fatalf("expected implicit conversion, got %+v, %v", dest, err)
+	}
+
+	mapper := NewWithConfig(WithDisableImplicitConversion())
+	CreateMap[MeasurementEntity, MeasurementDTO](mapper)
+	_, err = Map[MeasurementDTO](mapper, src)
+	var mappingErr *MappingError
+	if !errors.As(err, &mappingErr) || mappingErr.ErrorCode() != CodeIncompatibleTypes {
+		t.Fatalf("expected incompatible types error, got %v", err)
+	}
+
+	// Registered converters still apply
+	ConvertUsing(mapper, func(n int32) (int64, error) { return int64(n), nil })
+	ConvertUsing(mapper, func(s string) (MeasurementUnit, error) { return MeasurementUnit(s), nil })
+	dest, err = Map[MeasurementDTO](mapper, src)
+	if err != nil || dest.Count != 3 || dest.Unit != "kg" {
+		t.Errorf("expected converted result, got %+v, %v", dest, err)
+	}
+}
+
+type GaugeReading struct {
+	Level  sql.NullInt32
+	Phase  complex64
+	Labels map[string]string
+}
+
+type GaugeReadingDTO struct {
+	Level  int64
+	Phase  complex128
+	Labels map[string]MeasurementUnit
+}
+
+func TestDisableImplicitConversionEverywhere(t *testing.T) {
+	src := GaugeReading{Level: sql.NullInt32{Int32: 4, Valid: true}, Phase: 1, Labels: map[string]string{"a": "kg"}}
+
+	dest, err := Map[GaugeReadingDTO](New(), src)
+	if err != nil || dest.Level != 4 || dest.Phase != 1 || dest.Labels["a"] != "kg" {
+		t.Fatalf("expected implicit conversion, got %+v, %v", dest, err)
+	}
+
+	mapper := NewWithConfig(WithDisableImplicitConversion())
+	for _, member := range []string{"Level", "Phase", "Labels"} {
+		ignored := []string{"Level", "Phase", "Labels"}
+		_, err := MapWithOptions[GaugeReadingDTO](mapper, src, WithIgnoredMembers(without(ignored, member)...))
+		if err == nil {
+			t.Errorf("expected %s to need a registered converter", member)
+		}
+	}
+}
+
+func without(names []string, name string) []string {
+	var out []string
+	for _, n := range names {
+		if n != name {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+type ApprovalAddress struct {
+	City string
+}
+
+type ApprovalRequest struct {
+	Amount  int32
+	Address ApprovalAddress
+	Tags    []string
+	First   string
+	Last    string
+}
+
+type ApprovalAddressDTO struct {
+	City    string
+	Country string
+}
+
+type ApprovalRequestDTO struct {
+	Amount   int64
+	Address  ApprovalAddressDTO
+	Tags     []string
+	FullName string
+}
+
+func TestDryRunMap(t *testing.T) {
+	mapper := New()
+	CreateMap[ApprovalRequest, ApprovalRequestDTO](mapper).
+		ForMemberByName("FullName", MapFromFunc(func(src any, dest any) (any, error) {
+			r := src.(ApprovalRequest)
+			return r.First + " " + r.Last, nil
+		}))
+
+	src := ApprovalRequest{Amount: 250, Address: ApprovalAddress{City: "Oslo"}, Tags: []string{"urgent"}, First: "Ada", Last: "Lovelace"}
+	report, err := DryRunMap[ApprovalRequestDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.DestType != "automapper.ApprovalRequestDTO" {
+		t.Errorf("unexpected destination type %q", report.DestType)
+	}
+
+	got := make(map[string]Assignment)
+	for _, a := range report.Assignments {
+		got[a.Path] = a
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected 4 assignments, got %+v", report.Assignments)
+	}
+	if a := got["Amount"]; a.Op != OpConvert || a.Source != "Amount" || a.Value != int64(250) {
+		t.Errorf("unexpected Amount assignment: %+v", a)
+	}
+	if a := got["Address.City"]; a.Value != "Oslo" {
+		t.Errorf("unexpected Address.City assignment: %+v", a)
+	}
+	if a := got["Tags"]; !reflect.DeepEqual(a.Value, []string{"urgent"}) {
+		t.Errorf("unexpected Tags assignment: %+v", a)
+	}
+	if a := got["FullName"]; a.Op != OpResolve || a.Source != "" || a.Value != "Ada Lovelace" {
+		t.Errorf("unexpected FullName assignment: %+v", a)
+	}
+}
+
+type GoldenCustomer struct {
+	Name  string
+	Score int32
+}
+
+type GoldenOrder struct {
+	ID       int
+	Customer GoldenCustomer
+}
+
+type GoldenOrderDTO struct {
+	ID           int
+	CustomerName string
+	Label        string
+}
+
+type GoldenCustomerDTO struct {
+	Name  string
+	Score int64
+}
+
+func TestPlanSnapshot(t *testing.T) {
+	mapper := New()
+	CreateMap[GoldenOrder, GoldenOrderDTO](mapper).
+		ForMemberByName("Label", MapFromFunc(func(src any, _ any) (any, error) {
+			return "order", nil
+		}))
+	CreateMap[GoldenCustomer, GoldenCustomerDTO](mapper)
+
+	want := "automapper.GoldenCustomer -> automapper.GoldenCustomerDTO\n" +
+		"\tName: copy Name\n" +
+		"\tScore: convert Score\n" +
+		"\n" +
+		"automapper.GoldenOrder -> automapper.GoldenOrderDTO\n" +
+		"\tID: copy ID\n" +
+		"\tCustomerName: copy Customer.Name\n" +
+		"\tLabel: resolve\n"
+	if got := mapper.PlanSnapshot(); got != want {
+		t.Errorf("unexpected snapshot:\n%s\nwant:\n%s", got, want)
+	}
+	if got := mapper.PlanSnapshot(); got != want {
+		t.Error("snapshot is not deterministic")
+	}
+}
+
+func TestPlanSnapshotFieldOrder(t *testing.T) {
+	mapper := New()
+	CreateMap[GoldenOrder, GoldenOrderDTO](mapper).
+		ForMemberByName("ID", After("Label")).
+		ForMemberByName("Label", MapFromFunc(func(src any, _ any) (any, error) {
+			return "order", nil
+		}))
+
+	plan, err := PlanOf[GoldenOrder, GoldenOrderDTO](mapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Instructions[0].Member == "ID" {
+		t.Fatalf("expected ID to run after Label, got %+v", plan.Instructions)
+	}
+	want := "automapper.GoldenOrder -> automapper.GoldenOrderDTO\n" +
+		"\tID: copy ID\n" +
+		"\tCustomerName: copy Customer.Name\n" +
+		"\tLabel: resolve\n"
+	if got := plan.String(); got != want {
+		t.Errorf("expected destination field order:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBenchmarkLevels(t *testing.T) {
+	mapper := New()
+	CreateMap[BenchSource, BenchDest](mapper)
+
+	results, err := BenchmarkLevels(mapper, 5*time.Millisecond, benchSource)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected one result per level, got %+v", results)
+	}
+	for i, level := range []OptimizationLevel{OptimizationNone, OptimizationPooled, OptimizationUnsafe, OptimizationSpecialized} {
+		r := results[i]
+		if r.Level != level || r.DestType != "automapper.BenchDest" || r.NsPerOp <= 0 {
+			t.Errorf("unexpected result for %s: %+v", level, r)
+		}
+	}
+	if mapper.config.optLevel != OptimizationNone || mapper.config.useUnsafe {
+		t.Error("expected the mapper's optimization level to be restored")
+	}
+
+	if _, err := BenchmarkLevels(mapper, time.Millisecond, nil); err == nil {
+		t.Error("expected error for a nil sample")
+	}
+}
+
+func TestVerifyOptimizations(t *testing.T) {
+	mapper := NewWithConfig(WithUnsafeOptimizations(), WithVerifyOptimizations())
+	CreateMap[OptSource, OptDest](mapper)
+	src := OptSource{ID: 1, Name: "Verified", Age: 30, Active: true, Score: 1.5}
+
+	dest, err := Map[OptDest](mapper, src)
+	if err != nil || dest.Age != 30 || dest.Name != "Verified" {
+		t.Fatalf("unexpected result: %+v, %v", dest, err)
+	}
+	if issues := mapper.ValidateConfiguration(); len(issues) != 0 {
+		t.Fatalf("expected no divergence, got %v", issues)
+	}
+
+	// Simulate a faulty layout: Age is copied from the offset of ID
+	opt := mapper.config.optimizedMaps[typeMapKey{srcType: reflect.TypeOf(OptSource{}), destType: reflect.TypeOf(OptDest{})}]
+	for _, mm := range opt.optimizedMembers {
+		if mm.destField == "Age" {
+			mm.srcOffset = reflect.TypeOf(OptSource{}).Field(0).Offset
+		}
+	}
+
+	dest, err = Map[OptDest](mapper, &src)
+	if err != nil || dest.Age != 30 {
+		t.Fatalf("expected the standard result, got %+v, %v", dest, err)
+	}
+	issues := mapper.ValidateConfiguration()
+	if len(issues) != 1 || issues[0].Member != "Age" || issues[0].Severity != SeverityError {
+		t.Errorf("expected one divergence for 'Age', got %v", issues)
+	}
+
+	// Each type map is reported once
+	_, _ = Map[OptDest](mapper, &src)
+	if issues := mapper.ValidateConfiguration(); len(issues) != 1 {
+		t.Errorf("expected a single report, got %v", issues)
+	}
+}
+
+func TestVerifyReferenceContext(t *testing.T) {
+	mapper := NewWithConfig(WithUnsafeOptimizations(), WithVerifyOptimizations())
+	ctx := mapper.newMappingContext(OptSource{}, reflect.TypeOf(OptDest{}), []MapOption{
+		WithPreserveReferences(),
+		WithMemoization(8),
+		WithLocale("de-DE"),
+		OnMemberAssigned(func(path string, old, new any) {}),
+	})
+	ctx.finalizers = append(ctx.finalizers, finalizer{})
+	ctx.hookErrors = append(ctx.hookErrors, errors.New("hook failed"))
+
+	ref := ctx.referenceContext(mapper)
+	if !ref.verifying || ref.locale != "de-DE" || reflect.ValueOf(ref.refs).Pointer() != reflect.ValueOf(ctx.refs).Pointer() {
+		t.Errorf("expected the reference run to share references and call options, got %+v", ref)
+	}
+	if ref.memo != nil || ref.observer != nil || ref.finalizers != nil || ref.hookErrors != nil {
+		t.Errorf("expected the reference run not to share call side effects, got %+v", ref)
+	}
+}
+
+func TestLayoutMismatch(t *testing.T) {
+	type layouts struct {
+		A int32
+		B int64
+		C uint64
+		D int32
+	}
+	lt := reflect.TypeOf(layouts{})
+	a, b, c, d := lt.Field(0), lt.Field(1), lt.Field(2), lt.Field(3)
+
+	if reason := layoutMismatch(a, d); reason != "" {
+		t.Errorf("expected matching layouts, got %q", reason)
+	}
+	if reason := layoutMismatch(b, c); !strings.Contains(reason, "kinds differ") {
+		t.Errorf("expected kind mismatch, got %q", reason)
+	}
+	if reason := layoutMismatch(a, reflect.StructField{Type: reflect.TypeOf(int32(0)), Offset: 2}); !strings.Contains(reason, "not aligned") {
+		t.Errorf("expected misaligned offset, got %q", reason)
+	}
+
+	// Members whose layout matches keep the unsafe path and report nothing
+	mapper := NewWithConfig(WithUnsafeOptimizations())
+	CreateMap[OptSource, OptDest](mapper)
+	opt := mapper.config.optimizedMaps[typeMapKey{srcType: reflect.TypeOf(OptSource{}), destType: reflect.TypeOf(OptDest{})}]
+	if len(opt.fallbacks) != 0 || !opt.canFastMap() {
+		t.Errorf("expected every member to be copied unsafely, got fallbacks %v", opt.fallbacks)
+	}
+	if issues := mapper.ValidateConfiguration(); len(issues) != 0 {
+		t.Errorf("unexpected issues: %v", issues)
+	}
+
+	// Fallbacks are reported as warnings
+	opt.fallbacks = append(opt.fallbacks, layoutFallback{member: "Age", reason: "sizes differ (4, 8 bytes)"})
+	issues := mapper.ValidateConfiguration()
+	if len(issues) != 1 || issues[0].Member != "Age" || issues[0].Severity != SeverityWarning {
+		t.Errorf("expected a warning for 'Age', got %v", issues)
+	}
+}
+
+type ShipmentRow struct {
+	ID      int
+	Country string
+}
+
+type ShipmentDTO struct {
+	ID      int
+	Country string
+}
+
+func TestStringInterning(t *testing.T) {
+	rows := make([]ShipmentRow, 3)
+	for i := range rows {
+		// Equal values in distinct allocations, as decoded rows hold them
+		rows[i] = ShipmentRow{ID: i, Country: string(append([]byte(nil), "NO"...))}
+	}
+	if unsafe.StringData(rows[0].Country) == unsafe.StringData(rows[1].Country) {
+		t.Fatal("test rows should not share strings")
+	}
+
+	mapper := NewWithConfig(WithStringInterning())
+	CreateMap[ShipmentRow, ShipmentDTO](mapper)
+	dtos, err := MapSlice[ShipmentRow, ShipmentDTO](mapper, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dtos) != 3 || dtos[2].ID != 2 || dtos[2].Country != "NO" {
+		t.Fatalf("unexpected result: %+v", dtos)
+	}
+	for _, dto := range dtos[1:] {
+		if unsafe.StringData(dto.Country) != unsafe.StringData(dtos[0].Country) {
+			t.Error("expected equal strings to share one copy")
+		}
+	}
+}
+
+type EtlAddress struct {
+	City string
+}
+
+type EtlRow struct {
+	ID      int
+	Address *EtlAddress
+}
+
+type EtlAddressDTO struct {
+	City string
+}
+
+type EtlRowDTO struct {
+	ID      int
+	Address *EtlAddressDTO
+}
+
+func TestArena(t *testing.T) {
+	rows := make([]EtlRow, 100)
+	for i := range rows {
+		rows[i] = EtlRow{ID: i, Address: &EtlAddress{City: fmt.Sprintf("city-%d", i)}}
+	}
+	rows[5].Address = nil
+
+	plain := New()
+	CreateMap[EtlRow, EtlRowDTO](plain)
+	CreateMap[EtlAddress, EtlAddressDTO](plain)
+	arena := NewWithConfig(WithArena())
+	CreateMap[EtlRow, EtlRowDTO](arena)
+	CreateMap[EtlAddress, EtlAddressDTO](arena)
+
+	want, err := MapSlice[EtlRow, EtlRowDTO](plain, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := MapSlice[EtlRow, EtlRowDTO](arena, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatal("arena mapping differs from regular mapping")
+	}
+	if got[5].Address != nil || got[99].Address.City != "city-99" {
+		t.Errorf("unexpected result: %+v, %+v", got[5], got[99])
+	}
+
+	// Nested destinations are carved out of shared slabs
+	plainAllocs := testing.AllocsPerRun(10, func() { _, _ = MapSlice[EtlRow, EtlRowDTO](plain, rows) })
+	arenaAllocs := testing.AllocsPerRun(10, func() { _, _ = MapSlice[EtlRow, EtlRowDTO](arena, rows) })
+	if arenaAllocs >= plainAllocs-50 {
+		t.Errorf("expected fewer allocations with an arena: %v vs %v", arenaAllocs, plainAllocs)
+	}
+}
+
+type ReadingList []float64
+
+type SensorReadings struct {
+	Values ReadingList
+	Last   [3]float64
+}
+
+type SensorReadingsDTO struct {
+	Values []float64
+	Last   []float64
+}
+
+type SensorSeries []float64
+
+type SensorSeriesSource struct {
+	Values ReadingList
+}
+
+type SensorSeriesDTO struct {
+	Values SensorSeries
+}
+
+func TestShareIdenticalSlices(t *testing.T) {
+	src := &SensorReadings{Values: ReadingList{1, 2}, Last: [3]float64{3, 4, 5}}
+
+	copied, err := Map[SensorReadingsDTO](New(), src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if &copied.Last[0] == &src.Last[0] {
+		t.Error("arrays should be copied by default")
+	}
+
+	mapper := NewWithConfig(ShareIdenticalSlices())
+	shared, err := Map[SensorReadingsDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if &shared.Values[0] != &src.Values[0] || &shared.Last[0] != &src.Last[0] || len(shared.Last) != 3 {
+		t.Errorf("expected shared backing arrays, got %+v", shared)
+	}
+
+	// Named slice types are shared even without implicit conversion
+	strict := NewWithConfig(ShareIdenticalSlices(), WithDisableImplicitConversion())
+	seriesSrc := SensorSeriesSource{Values: ReadingList{7}}
+	series, err := Map[SensorSeriesDTO](strict, seriesSrc)
+	if err != nil || len(series.Values) != 1 || &series.Values[0] != &seriesSrc.Values[0] {
+		t.Errorf("unexpected result: %+v, %v", series, err)
+	}
+}
+
+type ResourceLabels map[string]string
+type ResourceCounters map[string]int
+type ResourceAttributes map[string]any
+
+type LabelSet map[string]string
+type CounterSet map[string]int
+type AttributeSet map[string]any
+
+type Resource struct {
+	Labels     ResourceLabels
+	Counters   ResourceCounters
+	Attributes ResourceAttributes
+}
+
+type ResourceDTO struct {
+	Labels     LabelSet
+	Counters   CounterSet
+	Attributes AttributeSet
+}
+
+func TestCommonMapShapes(t *testing.T) {
+	mapper := NewWithConfig(WithDisableImplicitConversion())
+	CreateMap[Resource, ResourceDTO](mapper)
+
+	tm := mapper.config.typeMaps[typeMapKey{srcType: reflect.TypeOf(Resource{}), destType: reflect.TypeOf(ResourceDTO{})}]
+	for _, ins := range mapper.planFor(tm).instructions {
+		if srcType := fieldTypeByIndex(tm.srcType, ins.srcIdx); mapper.mapCopierFor(srcType, ins.destType) == nil {
+			t.Errorf("expected a typed copy loop for %s", ins.member.destField)
+		}
+	}
+
+	src := Resource{
+		Labels:     ResourceLabels{"env": "prod"},
+		Counters:   ResourceCounters{"hits": 3},
+		Attributes: ResourceAttributes{"owner": "ops", "limit": 2},
+	}
+	dest, err := Map[ResourceDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ResourceDTO{
+		Labels:     LabelSet{"env": "prod"},
+		Counters:   CounterSet{"hits": 3},
+		Attributes: AttributeSet{"owner": "ops", "limit": 2},
+	}
+	if !reflect.DeepEqual(dest, want) {
+		t.Errorf("unexpected result: %+v", dest)
+	}
+	dest.Labels["env"] = "dev"
+	if src.Labels["env"] != "prod" {
+		t.Error("destination map should be a copy")
+	}
+
+	// Struct values take the generic path
+	src.Attributes["address"] = Address{City: "Oslo"}
+	dest, err = Map[ResourceDTO](mapper, src)
+	if err != nil || dest.Attributes["address"] != (Address{City: "Oslo"}) {
+		t.Errorf("unexpected attributes: %+v, %v", dest.Attributes, err)
+	}
+	var nilSrc Resource
+	if dest, err = Map[ResourceDTO](mapper, nilSrc); err != nil || dest.Labels == nil {
+		t.Errorf("expected an empty map for a nil source map, got %+v, %v", dest, err)
+	}
+}
+
+type SignalSample struct {
+	Name    string
+	Phase   complex128
+	Gain    complex128
+	Handle  uintptr
+	Address uintptr
+}
+
+type SignalSampleDTO struct {
+	Name    string
+	Phase   complex128
+	Gain    string
+	Handle  uint64
+	Address string
+}
+
+func TestUnknownKindPolicy(t *testing.T) {
+	src := SignalSample{Name: "a", Phase: complex(1, 2), Gain: complex(0.5, -1), Handle: 42, Address: 4096}
+
+	dest, err := Map[SignalSampleDTO](New(), src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := SignalSampleDTO{Name: "a", Phase: complex(1, 2), Gain: "(0.5-1i)", Handle: 42, Address: "4096"}
+	if dest != want {
+		t.Errorf("expected %+v, got %+v", want, dest)
+	}
+
+	skip := NewWithConfig(WithUnknownKindPolicy(UnknownKindSkip))
+	dest, err = Map[SignalSampleDTO](skip, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest != (SignalSampleDTO{Name: "a"}) {
+		t.Errorf("expected unknown kinds to be skipped, got %+v", dest)
+	}
+
+	strict := NewWithConfig(WithUnknownKindPolicy(UnknownKindError))
+	_, err = Map[SignalSampleDTO](strict, src)
+	var resolveErr, cause *MappingError
+	if !errors.As(err, &resolveErr) || !errors.As(resolveErr.Unwrap(), &cause) || cause.Code != CodeUnsupported {
+		t.Fatalf("expected an unsupported member error, got %v", err)
+	}
+
+	CreateMap[SignalSample, SignalSampleDTO](strict).
+		ForMemberByName("Phase", Ignore()).
+		ForMemberByName("Gain", Ignore()).
+		ForMemberByName("Handle", Ignore()).
+		ForMemberByName("Address", Ignore())
+	if _, err := Map[SignalSampleDTO](strict, src); err != nil {
+		t.Errorf("unexpected error for ignored members: %v", err)
+	}
+}
+
+type SignalPointers struct {
+	Handle *int
+	Gain   *complex128
+}
+
+type SignalPointersDTO struct {
+	Handle uintptr
+	Gain   string
+}
+
+func TestUnknownKindPointerSources(t *testing.T) {
+	mapper := New()
+	dest, err := Map[SignalPointersDTO](mapper, SignalPointers{})
+	if err != nil {
+		t.Fatalf("unexpected error for nil pointers: %v", err)
+	}
+	if dest != (SignalPointersDTO{}) {
+		t.Errorf("expected nil pointers to leave members unchanged, got %+v", dest)
+	}
+
+	handle, gain := 3, complex(1, -1)
+	dest, err = Map[SignalPointersDTO](mapper, SignalPointers{Handle: &handle, Gain: &gain})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest != (SignalPointersDTO{Handle: 3, Gain: "(1-1i)"}) {
+		t.Errorf("expected dereferenced values, got %+v", dest)
+	}
+}
+
+type SessionSettings struct {
+	Timeout   time.Duration
+	KeepAlive time.Duration
+	Retention time.Duration
+}
+
+type SessionSettingsDTO struct {
+	Timeout   string
+	KeepAlive int64
+	Retention time.Duration
+}
+
+func TestDurationFormat(t *testing.T) {
+	mapper := New()
+	CreateMap[SessionSettings, SessionSettingsDTO](mapper).WithDurationFormat(DurationString)
+	CreateMap[SessionSettingsDTO, SessionSettings](mapper).
+		WithDurationFormat(DurationMillis).
+		ForMemberByName("Timeout", Ignore())
+
+	src := SessionSettings{Timeout: 90 * time.Minute, KeepAlive: 30 * time.Second, Retention: time.Hour}
+	dto, err := Map[SessionSettingsDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// KeepAlive is an integer, so DurationString leaves it in nanoseconds
+	want := SessionSettingsDTO{Timeout: "1h30m0s", KeepAlive: int64(30 * time.Second), Retention: time.Hour}
+	if dto != want {
+		t.Errorf("expected %+v, got %+v", want, dto)
+	}
+
+	back, err := Map[SessionSettings](mapper, SessionSettingsDTO{Timeout: "1h30m", KeepAlive: 1500})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if back.KeepAlive != 1500*time.Millisecond {
+		t.Errorf("expected KeepAlive from milliseconds, got %v", back.KeepAlive)
+	}
+
+	parse := New()
+	CreateMap[SessionSettingsDTO, SessionSettings](parse).WithDurationFormat(DurationString)
+	back, err = Map[SessionSettings](parse, SessionSettingsDTO{Timeout: "1h30m", KeepAlive: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if back.Timeout != 90*time.Minute {
+		t.Errorf("expected Timeout parsed from string, got %v", back.Timeout)
+	}
+	if _, err := Map[SessionSettings](parse, SessionSettingsDTO{Timeout: "soon"}); err == nil {
+		t.Error("expected error for an invalid duration string")
+	}
+}
+
+type SessionEnvelopeDTO struct {
+	Settings SessionSettingsDTO
+}
+
+type SessionEnvelope struct {
+	Settings SessionSettings
+}
+
+type PortSpec struct {
+	Port string
+}
+
+type PortSpecDTO struct {
+	Port int
+}
+
+type PortHolder struct {
+	Spec PortSpec
+}
+
+type PortHolderDTO struct {
+	Spec PortSpecDTO
+}
+
+func TestWeakAndDurationErrorPaths(t *testing.T) {
+	mapper := New()
+	CreateMap[SessionSettingsDTO, SessionSettings](mapper).WithDurationFormat(DurationString)
+	CreateMap[SessionEnvelopeDTO, SessionEnvelope](mapper)
+	_, err := Map[SessionEnvelope](mapper, SessionEnvelopeDTO{Settings: SessionSettingsDTO{Timeout: "soon"}})
+	var mappingErr *MappingError
+	if !errors.As(err, &mappingErr) || mappingErr.Path != "Settings.Timeout" {
+		t.Errorf("expected the duration error path, got %v", err)
+	}
+
+	weak := NewWithConfig(WithWeakTypeConversion())
+	CreateMap[PortSpec, PortSpecDTO](weak)
+	CreateMap[PortHolder, PortHolderDTO](weak)
+	_, err = Map[PortHolderDTO](weak, PortHolder{Spec: PortSpec{Port: "http"}})
+	if !errors.As(err, &mappingErr) || mappingErr.Path != "Spec.Port" {
+		t.Errorf("expected the weak conversion error path, got %v", err)
+	}
+}
+
+type ServiceEndpoint struct {
+	Host     netip.Addr
+	Gateway  net.IP
+	Subnet   netip.Prefix
+	Listen   netip.AddrPort
+	Callback *url.URL
+	Peer     net.IP
+}
+
+type ServiceEndpointDTO struct {
+	Host     string
+	Gateway  string
+	Subnet   string
+	Listen   string
+	Callback string
+	Peer     netip.Addr
+}
+
+func TestNetworkTypes(t *testing.T) {
+	mapper := New()
+	CreateMap[ServiceEndpoint, ServiceEndpointDTO](mapper).ReverseMap()
+
+	callback, _ := url.Parse("https://example.com/hooks?id=1")
+	src := ServiceEndpoint{
+		Host:     netip.MustParseAddr("10.0.0.5"),
+		Gateway:  net.ParseIP("10.0.0.1"),
+		Subnet:   netip.MustParsePrefix("10.0.0.0/24"),
+		Listen:   netip.MustParseAddrPort("[::1]:8080"),
+		Callback: callback,
+		Peer:     net.ParseIP("192.168.1.9"),
+	}
+	dto, err := Map[ServiceEndpointDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ServiceEndpointDTO{
+		Host:     "10.0.0.5",
+		Gateway:  "10.0.0.1",
+		Subnet:   "10.0.0.0/24",
+		Listen:   "[::1]:8080",
+		Callback: "https://example.com/hooks?id=1",
+		Peer:     netip.MustParseAddr("192.168.1.9"),
+	}
+	if dto != want {
+		t.Errorf("expected %+v, got %+v", want, dto)
+	}
+
+	back, err := Map[ServiceEndpoint](mapper, dto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if back.Host != src.Host || !back.Gateway.Equal(src.Gateway) || back.Subnet != src.Subnet ||
+		back.Listen != src.Listen || back.Callback.String() != callback.String() || !back.Peer.Equal(src.Peer) {
+		t.Errorf("expected %+v, got %+v", src, back)
+	}
+
+	// Zero values map to empty strings and back
+	empty, err := Map[ServiceEndpointDTO](mapper, ServiceEndpoint{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if empty != (ServiceEndpointDTO{}) {
+		t.Errorf("expected empty strings, got %+v", empty)
+	}
+
+	if _, err := Map[ServiceEndpoint](mapper, ServiceEndpointDTO{Gateway: "not-an-ip"}); err == nil {
+		t.Error("expected error for an invalid IP")
+	}
+}
+
+type Permission uint8
+
+type ShareGrant struct {
+	User        string
+	Permissions Permission
+}
+
+type ShareGrantDTO struct {
+	User        string
+	Permissions []string
+}
+
+func TestRegisterFlags(t *testing.T) {
+	mapper := New()
+	RegisterFlags(mapper, map[Permission]string{1: "read", 2: "write", 4: "execute"})
+	CreateMap[ShareGrant, ShareGrantDTO](mapper).ReverseMap()
+
+	dto, err := Map[ShareGrantDTO](mapper, ShareGrant{User: "ann", Permissions: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dto.Permissions, []string{"read", "execute"}) {
+		t.Errorf("expected [read execute], got %v", dto.Permissions)
+	}
+
+	grant, err := Map[ShareGrant](mapper, ShareGrantDTO{Permissions: []string{"write", "read"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if grant.Permissions != 3 {
+		t.Errorf("expected mask 3, got %d", grant.Permissions)
+	}
+
+	if _, err := Map[ShareGrantDTO](mapper, ShareGrant{Permissions: 9}); err == nil {
+		t.Error("expected error for an unknown bit")
+	}
+	if _, err := Map[ShareGrant](mapper, ShareGrantDTO{Permissions: []string{"admin"}}); err == nil {
+		t.Error("expected error for an unknown flag name")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for a multi-bit flag")
+		}
+	}()
+	RegisterFlags(New(), map[Permission]string{3: "readwrite"})
+}
+
+type FeedItem struct{ Title string }
+
+type FeedItemDTO struct{ Title string }
+
+type Feed struct {
+	Tags   []string
+	Items  []FeedItem
+	Meta   map[string]string
+	Cursor []string
+}
+
+type FeedDTO struct {
+	Tags   []string
+	Items  []FeedItemDTO
+	Meta   map[string]string
+	Cursor []string
+}
+
+func TestNilCollectionPolicy(t *testing.T) {
+	for _, level := range []OptimizationLevel{OptimizationNone, OptimizationUnsafe} {
+		mapper := NewWithConfig(WithAllowNullCollections(), WithOptimizationLevel(level))
+		CreateMap[FeedItem, FeedItemDTO](mapper)
+		CreateMap[Feed, FeedDTO](mapper).
+			WithNilCollections(NilCollectionsEmpty).
+			ForMemberByName("Cursor", NilCollections(NilCollectionsNull))
+
+		dto, err := Map[FeedDTO](mapper, Feed{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dto.Tags == nil || dto.Items == nil || dto.Meta == nil {
+			t.Errorf("level %v: expected empty collections, got %+v", level, dto)
+		}
+		if dto.Cursor != nil {
+			t.Errorf("level %v: expected nil Cursor, got %#v", level, dto.Cursor)
+		}
+
+		// Non-nil sources are mapped as usual
+		dto, err = Map[FeedDTO](mapper, Feed{Items: []FeedItem{{Title: "a"}}, Cursor: []string{}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(dto.Items) != 1 || dto.Cursor == nil {
+			t.Errorf("level %v: expected mapped collections, got %+v", level, dto)
+		}
+	}
+
+	// A member policy applies without a type map policy
+	mapper := New()
+	CreateMap[Feed, FeedDTO](mapper).ForMemberByName("Tags", NilCollections(NilCollectionsEmpty))
+	dto, err := Map[FeedDTO](mapper, Feed{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dto.Tags == nil || dto.Cursor != nil {
+		t.Errorf("expected only Tags to be empty, got %#v", dto)
+	}
+}
+
+type ObjectMeta struct {
+	Labels      map[string]string
+	Annotations map[string]string
+	Selectors   map[string]string
+}
+
+type ObjectMetaDTO struct {
+	Labels      map[string]string
+	Annotations map[string]string
+	Selectors   map[string]string
+}
+
+func TestEmptyMapPolicy(t *testing.T) {
+	mapper := New()
+	CreateMap[ObjectMeta, ObjectMetaDTO](mapper).
+		ForMemberByName("Labels", EmptyMaps(EmptyMapNil)).
+		ForMemberByName("Annotations", EmptyMaps(EmptyMapEmpty)).
+		ForMemberByName("Selectors", EmptyMaps(EmptyMapPreserve))
+
+	dest := ObjectMetaDTO{Selectors: map[string]string{"app": "web"}}
+	src := ObjectMeta{Labels: map[string]string{}, Selectors: map[string]string{}}
+	if err := MapTo(mapper, src, &dest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Labels != nil {
+		t.Errorf("expected nil Labels, got %#v", dest.Labels)
+	}
+	if dest.Annotations == nil || len(dest.Annotations) != 0 {
+		t.Errorf("expected empty Annotations, got %#v", dest.Annotations)
+	}
+	if dest.Selectors["app"] != "web" {
+		t.Errorf("expected Selectors to be preserved, got %#v", dest.Selectors)
+	}
+
+	// Maps with entries are mapped as usual
+	src = ObjectMeta{Labels: map[string]string{"tier": "db"}, Selectors: map[string]string{"app": "api"}}
+	if err := MapTo(mapper, src, &dest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Labels["tier"] != "db" || dest.Selectors["app"] != "api" {
+		t.Errorf("expected mapped maps, got %+v", dest)
+	}
+}
+
+func TestMapCollectionDestinations(t *testing.T) {
+	batcher := &regionBatcher{}
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Email", MapFromBatch(batcher))
+
+	src := []SourceBasic{{Name: "a", Age: 1}, {Name: "b", Age: 2}}
+	dests, err := Map[[]DestBasic](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dests) != 2 || dests[1].Email != "region-b" || dests[1].Age != 2 {
+		t.Errorf("unexpected result: %+v", dests)
+	}
+	if batcher.calls != 1 {
+		t.Errorf("expected one batch call, got %d", batcher.calls)
+	}
+
+	byID, err := Map[map[string]DestBasic](mapper, map[string]*SourceBasic{"x": {Name: "x"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if byID["x"].Name != "x" {
+		t.Errorf("unexpected result: %+v", byID)
+	}
+
+	empty, err := Map[[]DestBasic](mapper, []SourceBasic(nil))
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("expected an empty slice, got %#v (%v)", empty, err)
+	}
+
+	strict := NewWithConfig(WithNilSourcePolicy(NilSourceError))
+	_, err = Map[[]DestBasic](strict, []*SourceBasic{{Name: "a"}, nil})
+	if err == nil || !strings.Contains(err.Error(), "index 1") {
+		t.Errorf("expected an error for element 1, got %v", err)
+	}
+}
+
+func TestMapSliceToAppendAndReplace(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper).
+		ForMemberByName("Email", MapFromFunc(func(src, dest any) (any, error) {
+			if src.(SourceBasic).Age < 0 {
+				return nil, errors.New("invalid age")
+			}
+			return src.(SourceBasic).Name + "@example.com", nil
+		}))
+
+	var dests []DestBasic
+	for _, batch := range [][]SourceBasic{{{Name: "a"}, {Name: "b"}}, {{Name: "c"}}} {
+		if err := MapSliceToAppend(mapper, batch, &dests); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(dests) != 3 || dests[2].Email != "c@example.com" {
+		t.Errorf("expected three accumulated results, got %+v", dests)
+	}
+
+	// A failing batch leaves the accumulated results alone
+	if err := MapSliceToAppend(mapper, []SourceBasic{{Name: "d"}, {Age: -1}}, &dests); err == nil {
+		t.Error("expected error for invalid element")
+	}
+	if len(dests) != 3 {
+		t.Errorf("expected destination to be unchanged, got %+v", dests)
+	}
+
+	if err := MapSliceToReplace(mapper, []SourceBasic{{Name: "e"}}, &dests); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dests) != 1 || dests[0].Name != "e" {
+		t.Errorf("expected replaced results, got %+v", dests)
+	}
+}
+
+type EventEnvelope struct {
+	Kind    string
+	Payload any
+	Tags    []string
+}
+
+type EventEnvelopeDTO struct {
+	Kind    any
+	Payload any
+	Tags    any
+}
+
+func TestInterfacePolicy(t *testing.T) {
+	src := EventEnvelope{Kind: "created", Payload: map[string]int{"n": 1}, Tags: []string{"a"}}
+
+	aliased, err := Map[EventEnvelopeDTO](New(), src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	src.Tags[0] = "changed"
+	if aliased.Tags.([]string)[0] != "changed" {
+		t.Errorf("expected default policy to share the source slice")
+	}
+
+	copying := NewWithConfig(WithInterfacePolicy(InterfaceDeepCopy))
+	copied, err := Map[EventEnvelopeDTO](copying, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	src.Tags[0] = "again"
+	src.Payload.(map[string]int)["n"] = 2
+	if copied.Kind != "created" || copied.Tags.([]string)[0] != "changed" || copied.Payload.(map[string]int)["n"] != 1 {
+		t.Errorf("expected deep copies, got %+v", copied)
+	}
+
+	strict := NewWithConfig(WithInterfacePolicy(InterfaceError))
+	if _, err := Map[EventEnvelopeDTO](strict, src); err == nil {
+		t.Error("expected error for a slice assigned to an interface member")
+	}
+	if _, err := Map[EventEnvelopeDTO](strict, EventEnvelope{Kind: "created"}); err != nil {
+		t.Errorf("unexpected error for scalar and nil values: %v", err)
+	}
+}
+
+type Celsius float64
+
+type LabelKey string
+type LabelValue string
+
+type LabeledHost struct {
+	Labels map[LabelKey]string
+}
+
+type LabeledHostDTO struct {
+	Labels map[string]LabelValue
+}
+
+func TestNamedMapShapesCopyWithTypedLoops(t *testing.T) {
+	mapper := New()
+	copier := mapper.mapCopierFor(reflect.TypeOf(map[LabelKey]string(nil)), reflect.TypeOf(map[string]LabelValue(nil)))
+	if copier == nil {
+		t.Fatal("expected a typed copy loop for maps differing in named key and value types")
+	}
+
+	src := LabeledHost{Labels: map[LabelKey]string{"env": "prod", "tier": "web"}}
+	dest, err := Map[LabeledHostDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dest.Labels, map[string]LabelValue{"env": "prod", "tier": "web"}) {
+		t.Errorf("unexpected labels: %+v", dest.Labels)
+	}
+	dest.Labels["env"] = "dev"
+	if src.Labels["env"] != "prod" {
+		t.Error("destination map should be a copy")
+	}
+
+	values, err := MapMap[string, string, LabelValue](mapper, map[string]string{"a": "x"})
+	if err != nil || values["a"] != "x" {
+		t.Errorf("unexpected MapMap result: %v, %v", values, err)
+	}
+
+	ConvertUsing[string, LabelValue](mapper, func(s string) (LabelValue, error) {
+		return LabelValue(strings.ToUpper(s)), nil
+	})
+	if mapper.mapCopierFor(reflect.TypeOf(map[string]string(nil)), reflect.TypeOf(map[string]LabelValue(nil))) != nil {
+		t.Error("expected a converter into the value type to keep the generic path")
+	}
+	values, err = MapMap[string, string, LabelValue](mapper, map[string]string{"a": "x"})
+	if err != nil || values["a"] != "X" {
+		t.Errorf("expected the converter applied, got %v, %v", values, err)
+	}
+}
+
+type ReadingAttributes struct {
+	Taken   any
+	Window  any
+	Source  any
+	Reading any
+	Count   any
+}
+
+func TestJSONSafeInterfaces(t *testing.T) {
+	mapper := NewWithConfig(WithJSONSafeInterfaces())
+	ConvertForJSON(mapper, func(c Celsius) (any, error) {
+		return fmt.Sprintf("%.1f°C", float64(c)), nil
+	})
+
+	src := struct {
+		Taken   time.Time
+		Window  time.Duration
+		Source  netip.Addr
+		Reading Celsius
+		Count   int
+	}{
+		Taken:   time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Window:  90 * time.Second,
+		Source:  netip.MustParseAddr("10.0.0.7"),
+		Reading: 21.5,
+		Count:   3,
+	}
+	dest, err := Map[ReadingAttributes](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ReadingAttributes{Taken: "2024-03-01T12:00:00Z", Window: "1m30s", Source: "10.0.0.7", Reading: "21.5°C", Count: 3}
+	if dest != want {
+		t.Errorf("expected %+v, got %+v", want, dest)
+	}
+
+	// Without the option values are assigned as they are
+	plain, err := Map[ReadingAttributes](New(), src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := plain.Taken.(time.Time); !ok {
+		t.Errorf("expected time.Time without the option, got %T", plain.Taken)
+	}
+}
+
+type JobResult struct {
+	ID      int
+	Err     error
+	Failure error
+	Last    error
+}
+
+type JobResultDTO struct {
+	ID      int
+	Err     string
+	Failure *ErrorDTO
+	Last    ErrorDTO
+}
+
+func TestErrorMembers(t *testing.T) {
+	cause := &MappingError{Message: "no type map registered", Code: CodeNoTypeMap}
+	failure := fmt.Errorf("step 2: %w", cause)
+
+	mapper := New()
+	dto, err := Map[JobResultDTO](mapper, JobResult{ID: 1, Err: failure, Failure: failure, Last: errors.New("boom")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dto.Err != failure.Error() || dto.Last.Message != "boom" {
+		t.Errorf("unexpected messages: %+v", dto)
+	}
+	if dto.Failure == nil || dto.Failure.Cause == nil || dto.Failure.Cause.Code != CodeNoTypeMap {
+		t.Errorf("expected the cause chain with its code, got %+v", dto.Failure)
+	}
+	if issues := mapper.ValidateConfiguration(); len(issues) != 0 {
+		t.Errorf("unexpected validation issues: %v", issues)
+	}
+
+	// Nil errors leave the destination alone
+	dto, err = Map[JobResultDTO](mapper, JobResult{ID: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dto.Err != "" || dto.Failure != nil {
+		t.Errorf("expected empty members for nil errors, got %+v", dto)
+	}
+
+	redacting := NewWithConfig(WithErrorMessage(func(error) string { return "internal error" }))
+	dto, err = Map[JobResultDTO](redacting, JobResult{Err: failure, Failure: failure})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dto.Err != "internal error" || dto.Failure.Cause.Message != "internal error" {
+		t.Errorf("expected formatted messages, got %+v", dto)
+	}
+
+	if NewErrorDTO(nil) != nil || NewErrorDTO(failure).Cause.Message != cause.Error() {
+		t.Error("unexpected NewErrorDTO result")
+	}
+}
+
+type ProfilePatch struct {
+	Name   string
+	Age    int
+	Tags   []string
+	Labels map[string]string
+}
+
+type Profile struct {
+	Name   string
+	Age    int
+	Tags   []string
+	Labels map[string]string
+}
+
+func TestValueConditions(t *testing.T) {
+	for _, level := range []OptimizationLevel{OptimizationNone, OptimizationSpecialized} {
+		mapper := NewWithConfig(WithOptimizationLevel(level))
+		CreateMap[ProfilePatch, Profile](mapper).
+			ForMemberByName("Name", IfNotZero()).
+			ForMemberByName("Age", IfNotZero()).
+			ForMemberByName("Tags", IfNotEmpty()).
+			ForMemberByName("Labels", IfChanged())
+
+		labels := map[string]string{"team": "core"}
+		dest := Profile{Name: "Ann", Age: 40, Tags: []string{"admin"}, Labels: labels}
+		patch := ProfilePatch{Age: 41, Tags: []string{}, Labels: map[string]string{"team": "core"}}
+		if err := MapTo(mapper, &patch, &dest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest.Name != "Ann" || dest.Age != 41 || len(dest.Tags) != 1 {
+			t.Errorf("level %v: unexpected result %+v", level, dest)
+		}
+		if reflect.ValueOf(dest.Labels).Pointer() != reflect.ValueOf(labels).Pointer() {
+			t.Errorf("level %v: expected unchanged Labels to keep their map", level)
+		}
+
+		patch = ProfilePatch{Name: "Bea", Tags: []string{"ops"}, Labels: map[string]string{"team": "infra"}}
+		if err := MapTo(mapper, &patch, &dest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest.Name != "Bea" || dest.Age != 41 || dest.Tags[0] != "ops" || dest.Labels["team"] != "infra" {
+			t.Errorf("level %v: unexpected result %+v", level, dest)
+		}
+	}
+}
+
+type ParcelSource struct {
+	Carrier  string
+	Tracking string
+	Shipped  bool
+	Weight   int
+}
+
+type ParcelDTO struct {
+	Carrier  string
+	Tracking string
+	Weight   int
+}
+
+func TestConditionT(t *testing.T) {
+	levels := []OptimizationLevel{OptimizationNone, OptimizationSpecialized, OptimizationUnsafe}
+	for _, level := range levels {
+		mapper := NewWithConfig(WithOptimizationLevel(level))
+		CreateMap[ParcelSource, ParcelDTO](mapper).
+			ForMemberByName("Tracking", ConditionT(func(src ParcelSource) bool {
+				return src.Shipped
+			})).
+			ForMemberByName("Weight", ConditionT(func(src ParcelSource) bool {
+				return src.Weight > 0
+			}))
+
+		src := ParcelSource{Carrier: "DHL", Tracking: "JD0142", Weight: 0}
+		dest := ParcelDTO{Tracking: "pending", Weight: 7}
+		if err := MapTo(mapper, &src, &dest); err != nil {
+			t.Fatalf("level %v: unexpected error: %v", level, err)
+		}
+		if dest.Carrier != "DHL" || dest.Tracking != "pending" || dest.Weight != 7 {
+			t.Errorf("level %v: conditions not applied: %+v", level, dest)
+		}
+
+		src.Shipped, src.Weight = true, 3
+		got, err := Map[ParcelDTO](mapper, src)
+		if err != nil {
+			t.Fatalf("level %v: unexpected error: %v", level, err)
+		}
+		if got.Tracking != "JD0142" || got.Weight != 3 {
+			t.Errorf("level %v: expected conditional members mapped, got %+v", level, got)
+		}
+	}
+}
+
+type ArchiveSource struct {
+	Name    string
+	Payload [4096]byte
+	Sealed  bool
+}
+
+type ArchiveDTO struct {
+	Name    string
+	Payload [4096]byte
+}
+
+func TestConditionPtr(t *testing.T) {
+	src := &ArchiveSource{Name: "logs", Payload: [4096]byte{1}}
+
+	for _, level := range []OptimizationLevel{OptimizationNone, OptimizationSpecialized, OptimizationUnsafe} {
+		var seen *ArchiveSource
+		mapper := NewWithConfig(WithOptimizationLevel(level))
+		CreateMap[ArchiveSource, ArchiveDTO](mapper).
+			ForMemberByName("Payload", ConditionPtr(func(src *ArchiveSource) bool {
+				seen = src
+				return !src.Sealed
+			}))
+
+		dto, err := Map[ArchiveDTO](mapper, src)
+		if err != nil {
+			t.Fatalf("level %v: unexpected error: %v", level, err)
+		}
+		if seen != src {
+			t.Errorf("level %v: expected the condition to receive the source pointer", level)
+		}
+		if dto.Name != "logs" || dto.Payload[0] != 1 {
+			t.Errorf("level %v: unexpected result %+v", level, dto.Name)
+		}
+	}
+
+	// Conditions observe BeforeMap mutations of the working copy
+	mapper := New()
+	CreateMap[ArchiveSource, ArchiveDTO](mapper).
+		BeforeMap(func(src *ArchiveSource, _ *ArchiveDTO) error {
+			src.Sealed = true
+			return nil
+		}).
+		ForMemberByName("Payload", ConditionPtr(func(src *ArchiveSource) bool {
+			return !src.Sealed
+		}))
+
+	dto, err := Map[ArchiveDTO](mapper, *src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dto.Name != "logs" || dto.Payload[0] != 0 {
+		t.Errorf("expected Payload skipped after BeforeMap sealed the source")
+	}
+	if src.Sealed {
+		t.Errorf("expected the caller's source to be unchanged")
+	}
+}
+
+type BillCustomer struct {
+	Name string
+}
+
+type BillSource struct {
+	Number   string
+	Customer BillCustomer
+	Total    int
+	Void     bool
+}
+
+type BillDTO struct {
+	Number       string
+	CustomerName string
+	Total        string
+	Status       string
+}
+
+func TestUsageStats(t *testing.T) {
+	mapper := NewWithConfig(WithUsageStats())
+	CreateMap[BillSource, BillDTO](mapper).
+		ForMemberByName("Total", UseConverter(func(src any, _ reflect.Type) (any, error) {
+			return fmt.Sprint(src), nil
+		})).
+		ForMemberByName("Status", MapFromFunc(func(src, dest any) (any, error) { return "void", nil }),
+			ConditionT(func(src BillSource) bool { return src.Void }))
+
+	for i := 0; i < 3; i++ {
+		if _, err := Map[BillDTO](mapper, BillSource{Number: "A-1", Customer: BillCustomer{Name: "Acme"}, Total: 12}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	counts := make(map[string]MemberUsage)
+	for _, usage := range mapper.UsageStats() {
+		counts[usage.Member] = usage
+	}
+	if counts["Number"].Count != 3 || counts["Total"].Count != 3 || !counts["Total"].Converter {
+		t.Errorf("unexpected usage %+v", counts)
+	}
+	if !counts["CustomerName"].Flattened || counts["CustomerName"].Count != 3 {
+		t.Errorf("expected flattened member counted, got %+v", counts["CustomerName"])
+	}
+
+	unused := mapper.UnusedConfiguration()
+	if len(unused) != 1 || unused[0].Member != "Status" || !unused[0].Resolver {
+		t.Errorf("expected the Status resolver reported unused, got %+v", unused)
+	}
+
+	mapper.ResetUsageStats()
+	if stats := mapper.UsageStats(); stats[0].Count != 0 {
+		t.Errorf("expected counts reset, got %+v", stats)
+	}
+	if New().UsageStats() != nil {
+		t.Error("expected no stats without WithUsageStats")
+	}
+}
+
+type LedgerEntry struct {
+	Amount int
+	Memo   string
+	Ref    string
+}
+
+type LedgerEntryDTO struct {
+	Amount int
+	Memo   string
+	Ref    string
+	Note   string
+}
+
+func TestValidateShadowedOptions(t *testing.T) {
+	mapper := New()
+	CreateMap[LedgerEntry, LedgerEntryDTO](mapper).
+		ForMemberByName("Amount", MapFrom("Amount"), Ignore()).
+		ForMemberByName("Memo", MapFromFunc(func(src, dest any) (any, error) { return "fixed", nil })).
+		ForMemberByName("Memo", MapFrom("Ref")).
+		ForMemberByName("Ref", MapFromFunc(func(src, dest any) (any, error) { return "a", nil }),
+			Coalesce("Ref", "Memo")).
+		ForMemberByName("Note", MapFrom("Memo"), MapFrom("Ref"))
+
+	messages := make(map[string]string)
+	for _, issue := range mapper.ValidateConfiguration() {
+		if issue.Severity == SeverityWarning {
+			messages[issue.Member] = issue.Message
+		}
+	}
+	want := map[string]string{
+		"Amount": `Ignore takes precedence over MapFrom("Amount"); the member is not mapped`,
+		"Memo":   `MapFrom("Ref") is shadowed by MapFromFunc; resolvers take precedence over source fields`,
+		"Ref":    `MapFromFunc is overridden by Coalesce; the last resolver applies`,
+		"Note":   `MapFrom("Memo") is overridden by MapFrom("Ref"); the last source field applies`,
+	}
+	for member, msg := range want {
+		if messages[member] != msg {
+			t.Errorf("member %s: expected %q, got %q", member, msg, messages[member])
+		}
+	}
+
+	// The reported precedence is the one mapping applies
+	dto, err := Map[LedgerEntryDTO](mapper, LedgerEntry{Amount: 5, Memo: "m", Ref: "r"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dto.Amount != 0 || dto.Memo != "fixed" || dto.Ref != "r" || dto.Note != "r" {
+		t.Errorf("unexpected result %+v", dto)
+	}
+}
+
+type RankedSource struct {
+	Scores []int
+}
+
+type RankedDest struct {
+	Scores []int
+}
+
+func TestSortByKeepsSourceOrder(t *testing.T) {
+	for _, opts := range [][]ConfigOption{nil, {ShareIdenticalSlices()}} {
+		mapper := NewWithConfig(opts...)
+		CreateMap[RankedSource, RankedDest](mapper).
+			ForMemberByName("Scores", SortBy(func(a, b int) bool { return a < b }))
+
+		src := RankedSource{Scores: []int{3, 1, 2}}
+		dest, err := Map[RankedDest](mapper, src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(dest.Scores, []int{1, 2, 3}) {
+			t.Errorf("expected sorted scores, got %v", dest.Scores)
+		}
+		if !reflect.DeepEqual(src.Scores, []int{3, 1, 2}) {
+			t.Errorf("expected the source order unchanged, got %v", src.Scores)
+		}
+	}
+}
+
+type TaskSource struct {
+	Name string
+}
+
+type TaskDTO struct {
+	Name string
+	Done bool
+}
+
+func (t TaskDTO) Finished() bool { return t.Done }
+
+type Finished interface {
+	Finished() bool
+}
+
+type BoardSource struct {
+	Items map[string]TaskSource
+	One   TaskSource
+}
+
+type BoardDTO struct {
+	Items map[string]TaskDTO
+	One   Finished
+}
+
+func TestFinalizeDetachedDestinations(t *testing.T) {
+	mapper := New()
+	CreateMap[TaskSource, TaskDTO](mapper).
+		Finalize(func(dest *TaskDTO) error {
+			dest.Done = true
+			return nil
+		})
+	CreateMap[BoardSource, BoardDTO](mapper)
+
+	dto, err := Map[BoardDTO](mapper, BoardSource{
+		Items: map[string]TaskSource{"a": {Name: "a"}},
+		One:   TaskSource{Name: "one"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !dto.Items["a"].Done {
+		t.Errorf("expected map values finalized, got %+v", dto.Items)
+	}
+	if dto.One == nil || !dto.One.Finished() {
+		t.Errorf("expected interface member finalized, got %+v", dto.One)
+	}
+}
+
+type TaskListSource struct {
+	Tasks []TaskSource
+	Refs  []TaskSource
+}
+
+type TaskListDTO struct {
+	Tasks []TaskDTO
+	Refs  []*TaskDTO
+}
+
+func TestFinalizeSortedAndDistinctElements(t *testing.T) {
+	mapper := New()
+	CreateMap[TaskSource, TaskDTO](mapper).
+		Finalize(func(dest *TaskDTO) error {
+			dest.Done = true
+			return nil
+		})
+	CreateMap[TaskListSource, TaskListDTO](mapper).
+		ForMemberByName("Tasks",
+			SortBy(func(a, b TaskDTO) bool { return a.Name < b.Name }),
+			Distinct(func(t TaskDTO) string { return t.Name })).
+		ForMemberByName("Refs", SortBy(func(a, b *TaskDTO) bool { return a.Name < b.Name }))
+
+	src := TaskListSource{
+		Tasks: []TaskSource{{Name: "b"}, {Name: "a"}, {Name: "b"}},
+		Refs:  []TaskSource{{Name: "y"}, {Name: "x"}},
+	}
+	dto, err := Map[TaskListDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dto.Tasks) != 2 || dto.Tasks[0].Name != "a" || !dto.Tasks[0].Done || !dto.Tasks[1].Done {
+		t.Errorf("expected sorted, distinct and finalized tasks, got %+v", dto.Tasks)
+	}
+	if dto.Refs[0].Name != "x" || !dto.Refs[0].Done || !dto.Refs[1].Done {
+		t.Errorf("expected sorted and finalized pointer elements, got %+v, %+v", dto.Refs[0], dto.Refs[1])
+	}
+}
+
+type AnyHolder struct {
+	V any
+}
+
+type AnyHolderDTO struct {
+	V any
+}
+
+func TestInterfaceDispatchKeepsEmptyInterfaces(t *testing.T) {
+	mapper := New()
+	CreateMap[CreditCard, CreditCardDTO](mapper)
+
+	dest, err := Map[AnyHolderDTO](mapper, AnyHolder{V: CreditCard{Number: "4111"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := dest.V.(CreditCard); !ok {
+		t.Errorf("expected CreditCard kept in an any member, got %#v", dest.V)
+	}
+
+	top, err := Map[any](mapper, CreditCard{Number: "4111"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := top.(CreditCard); !ok {
+		t.Errorf("expected Map[any] to return the CreditCard, got %#v", top)
+	}
+
+	// Sources implementing the destination interface are assigned as they are
+	holder, err := Map[struct{ P PaymentMethod }](mapper, struct{ P PaymentMethod }{P: CreditCard{Number: "1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := holder.P.(CreditCard); !ok {
+		t.Errorf("expected CreditCard kept, got %#v", holder.P)
+	}
+}
+
+type CounterSource struct {
+	Count string
+	Label string
+}
+
+type CounterDest struct {
+	Count int
+	Label string
+}
+
+func TestWeakTypesWithOptimizations(t *testing.T) {
+	for _, level := range []OptimizationLevel{OptimizationSpecialized, OptimizationUnsafe} {
+		mapper := NewWithConfig(WithWeakTypeConversion(), WithOptimizationLevel(level))
+		CreateMap[CounterSource, CounterDest](mapper)
+
+		dest, err := Map[CounterDest](mapper, CounterSource{Count: "42", Label: "x"})
+		if err != nil {
+			t.Fatalf("level %v: unexpected error: %v", level, err)
+		}
+		if dest.Count != 42 || dest.Label != "x" {
+			t.Errorf("level %v: unexpected result %+v", level, dest)
+		}
+	}
+}
+
+func TestPlanExecuteValidatesOps(t *testing.T) {
+	mapper := New()
+	CreateMap[SourceBasic, DestBasic](mapper).
+		WithMemberPolicy(func(ctx *MappingContext, member string) bool { return member != "Name" })
+
+	for _, op := range []Op{OpFormat, OpWeak, OpDuration} {
+		plan, err := PlanOf[SourceBasic, DestBasic](mapper)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i := range plan.Instructions {
+			if plan.Instructions[i].Member == "Age" {
+				plan.Instructions[i].Op = op
+			}
+		}
+		var dest DestBasic
+		if err := mapper.Execute(plan, SourceBasic{Age: 30}, &dest); err == nil {
+			t.Errorf("expected %s on an int member to be rejected", op)
+		}
+	}
+
+	plan, err := PlanOf[SourceBasic, DestBasic](mapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var dest DestBasic
+	if err := mapper.Execute(plan, SourceBasic{Name: "John", Age: 30}, &dest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Name != "" || dest.Age != 30 {
+		t.Errorf("expected the member policy applied, got %+v", dest)
+	}
+}
+
+type AttributeBag map[string]any
+
+type AssetAttributes map[string]any
+
+type TaggedAsset struct {
+	Attrs AssetAttributes
+}
+
+type TaggedAssetDTO struct {
+	Attrs AttributeBag
+}
+
+func TestStringKeyedAnyMapsApplyInterfacePolicies(t *testing.T) {
+	src := TaggedAsset{Attrs: AssetAttributes{"sizes": []int{1, 2}, "ttl": 90 * time.Second}}
+
+	deep := NewWithConfig(WithDisableImplicitConversion(), WithInterfacePolicy(InterfaceDeepCopy))
+	CreateMap[TaggedAsset, TaggedAssetDTO](deep)
+	dto, err := Map[TaggedAssetDTO](deep, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dto.Attrs["sizes"].([]int)[0] = 9
+	if src.Attrs["sizes"].([]int)[0] != 1 {
+		t.Error("expected InterfaceDeepCopy to copy slices held by the map")
+	}
+
+	strict := NewWithConfig(WithDisableImplicitConversion(), WithInterfacePolicy(InterfaceError))
+	CreateMap[TaggedAsset, TaggedAssetDTO](strict)
+	if _, err := Map[TaggedAssetDTO](strict, src); err == nil {
+		t.Error("expected InterfaceError for a slice held by the map")
+	}
+
+	safe := NewWithConfig(WithDisableImplicitConversion(), WithJSONSafeInterfaces())
+	CreateMap[TaggedAsset, TaggedAssetDTO](safe)
+	dto, err = Map[TaggedAssetDTO](safe, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dto.Attrs["ttl"] != "1m30s" {
+		t.Errorf("expected a JSON-safe duration, got %#v", dto.Attrs["ttl"])
+	}
+}
+
+type AssetPayload struct {
+	Attrs map[string]any
+	Items []any
+}
+
+type AssetPayloadDTO struct {
+	Attrs map[string]any
+	Items []any
+}
+
+func TestInterfacePoliciesReachCollectionElements(t *testing.T) {
+	newSrc := func() AssetPayload {
+		return AssetPayload{Attrs: map[string]any{"sizes": []int{1, 2}}, Items: []any{[]string{"a"}}}
+	}
+
+	deep := NewWithConfig(WithInterfacePolicy(InterfaceDeepCopy))
+	src := newSrc()
+	dto, err := Map[AssetPayloadDTO](deep, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dto.Attrs["sizes"].([]int)[0] = 9
+	dto.Items[0].([]string)[0] = "z"
+	if src.Attrs["sizes"].([]int)[0] != 1 || src.Items[0].([]string)[0] != "a" {
+		t.Error("expected InterfaceDeepCopy to copy values held by same-typed collections")
+	}
+
+	converted, err := Map[TaggedAssetDTO](deep, TaggedAsset{Attrs: AssetAttributes{"sizes": []int{1}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if converted.Attrs["sizes"].([]int)[0] != 1 {
+		t.Errorf("expected converted map values, got %+v", converted)
+	}
+
+	strict := NewWithConfig(WithInterfacePolicy(InterfaceError))
+	if _, err := Map[AssetPayloadDTO](strict, newSrc()); err == nil {
+		t.Error("expected InterfaceError for slices held by same-typed collections")
+	}
+	if _, err := Map[TaggedAssetDTO](strict, TaggedAsset{Attrs: AssetAttributes{"sizes": []int{1}}}); err == nil {
+		t.Error("expected InterfaceError for slices held by a converted map")
+	}
+}
+
+type AuditEnvelope struct {
+	Meta  map[string]any
+	Stamp []any
+}
+
+type AuditEnvelopeDTO struct {
+	Meta  map[string]any
+	Stamp []any
+}
+
+func TestJSONSafeCollectionElements(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	src := AuditEnvelope{Meta: map[string]any{"at": at}, Stamp: []any{at, 90 * time.Second}}
+
+	mapper := NewWithConfig(WithJSONSafeInterfaces())
+	dto, err := Map[AuditEnvelopeDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := dto.Meta["at"].(string); !ok {
+		t.Errorf("expected a JSON-safe time in the map, got %#v", dto.Meta["at"])
+	}
+	if _, ok := dto.Stamp[0].(string); !ok || dto.Stamp[1] != "1m30s" {
+		t.Errorf("expected JSON-safe values in the slice, got %#v", dto.Stamp)
+	}
+	if _, ok := src.Meta["at"].(time.Time); !ok {
+		t.Error("expected the source map to be unchanged")
+	}
+}
+
+type ShelfItem struct {
+	Title string
+}
+
+type ShelfItemDTO struct {
+	Title string
+}
+
+type Shelf struct {
+	Name string
+	Top  ShelfItem
+}
+
+type ShelfDTO struct {
+	Name string
+	Top  ShelfItemDTO
+}
+
+func TestOnCompileRunsOncePerTypeMap(t *testing.T) {
+	mapper := New()
+	calls := 0
+	mapper.OnCompile(func(tm *TypeMapView) error {
+		calls++
+		return nil
+	})
+	CreateMap[Shelf, ShelfDTO](mapper).ResetMember("Name")
+	if _, err := ProjectFields[ShelfDTO](mapper); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the hook to run once, ran %d times", calls)
+	}
+}
+
+func TestOnCompileErrorsFailMappings(t *testing.T) {
+	mapper := New()
+	mapper.OnCompile(func(tm *TypeMapView) error {
+		return errors.New("missing tenant column")
+	})
+	CreateMap[Shelf, ShelfDTO](mapper)
+
+	_, err := Map[ShelfDTO](mapper, Shelf{Name: "a"})
+	var mappingErr *MappingError
+	if !errors.As(err, &mappingErr) || mappingErr.ErrorCode() != CodeHook {
+		t.Errorf("expected the hook error, got %v", err)
+	}
+
+	issues := mapper.ValidateConfiguration()
+	if len(issues) == 0 || issues[0].Severity != SeverityError {
+		t.Errorf("expected the hook error reported, got %v", issues)
+	}
+}
+
+type ReminderSource struct {
+	DueAt     time.Time
+	UpdatedAt time.Time
+}
+
+type ReminderDTO struct {
+	DueAt     *time.Time
+	UpdatedAt *time.Time
+}
+
+func TestZeroTimesNilOnlyForAuditMembers(t *testing.T) {
+	mapper := New()
+	CreateMap[ReminderSource, ReminderDTO](mapper)
+
+	dest, err := Map[ReminderDTO](mapper, ReminderSource{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest.DueAt == nil || !dest.DueAt.IsZero() {
+		t.Errorf("DueAt = %v, want pointer to the zero time", dest.DueAt)
+	}
+	if dest.UpdatedAt != nil {
+		t.Errorf("UpdatedAt = %v, want nil", dest.UpdatedAt)
+	}
+}
+
+type VoucherSource struct {
+	Code   string
+	Amount int
+}
+
+type VoucherDTO struct {
+	Code   string
+	Amount int
+}
+
+func TestTypedConditionsCheckSourceType(t *testing.T) {
+	mapper := New()
+	CreateMap[VoucherSource, VoucherDTO](mapper).
+		ForMemberByName("Code", ConditionT(func(src SourceBasic) bool { return true })).
+		ForMemberByName("Amount", ConditionPtr(func(src *SourceBasic) bool { return true }))
+
+	dest, err := Map[VoucherDTO](mapper, &VoucherSource{Code: "X", Amount: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest != (VoucherDTO{}) {
+		t.Errorf("expected mismatched conditions to skip members, got %+v", dest)
+	}
+
+	issues := 0
+	for _, issue := range mapper.ValidateConfiguration() {
+		if issue.Severity == SeverityError && issue.SrcType == reflect.TypeOf(VoucherSource{}) {
+			issues++
+		}
+	}
+	if issues != 2 {
+		t.Errorf("expected 2 condition errors, got %d", issues)
+	}
+
+	matching := New()
+	CreateMap[VoucherSource, VoucherDTO](matching).
+		ForMemberByName("Code", ConditionT(func(src *VoucherSource) bool { return src.Amount > 0 })).
+		ForMemberByName("Amount", ConditionPtr(func(src *VoucherSource) bool { return src.Amount > 0 }))
+	dest, err = Map[VoucherDTO](matching, VoucherSource{Code: "X", Amount: 5})
+	if err != nil || dest != (VoucherDTO{Code: "X", Amount: 5}) {
+		t.Errorf("expected mapped voucher, got %+v, %v", dest, err)
+	}
+	for _, issue := range matching.ValidateConfiguration() {
+		if issue.SrcType == reflect.TypeOf(VoucherSource{}) {
+			t.Errorf("unexpected issue: %v", issue.Message)
+		}
+	}
+}
+
+type PatientContact struct {
+	Phone string `pii:"true"`
+}
+
+type PatientRecord struct {
+	Name    string
+	SSN     string `pii:"true"`
+	Card    int    `pii:"hash"`
+	Contact PatientContact
+}
+
+type PatientDTO struct {
+	Name    string
+	TaxID   string
+	Card    int
+	Phone   string
+	Display string
+}
+
+func TestRedactionFollowsSourceFields(t *testing.T) {
+	mapper := NewWithConfig(WithRedactionTag("pii"))
+	CreateMap[PatientRecord, PatientDTO](mapper).
+		ForMemberByName("TaxID", MapFrom("SSN")).
+		ForMemberByName("Phone", MapFrom("Contact.Phone")).
+		ForMemberByName("Display", MapFrom("SSN"), RedactWith(func(value any) any { return "hidden" })).
+		ForMemberByName("Name", MapFrom("SSN")).
+		ForMemberByName("Name", MapFrom("Name"))
+
+	src := PatientRecord{Name: "Ann", SSN: "123-45-6789", Card: 4111, Contact: PatientContact{Phone: "555-0100"}}
+	dest, err := Map[PatientDTO](mapper, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := PatientDTO{Name: "Ann", TaxID: "***", Card: 0, Phone: "***", Display: "hidden"}
+	if dest != want {
+		t.Errorf("expected %+v, got %+v", want, dest)
+	}
+}
+
+type MemoAuthor struct {
+	Name string
+	Tags []string
+}
+
+type MemoAuthorDTO struct {
+	Name string
+	Tags []string
+}
+
+type MemoPost struct {
+	Author *MemoAuthor
+}
+
+type MemoPostDTO struct {
+	Author MemoAuthorDTO
+}
+
+type MemoFeed struct {
+	Posts []MemoPost
+}
+
+type MemoFeedDTO struct {
+	Posts []MemoPostDTO
+}
+
+func TestMemoizedResultsAreIndependent(t *testing.T) {
+	mapper := New()
+	CreateMap[MemoAuthor, MemoAuthorDTO](mapper)
+	CreateMap[MemoPost, MemoPostDTO](mapper)
+	CreateMap[MemoFeed, MemoFeedDTO](mapper)
+
+	author := &MemoAuthor{Name: "Ann", Tags: []string{"go"}}
+	feed := MemoFeed{Posts: []MemoPost{{Author: author}, {Author: author}, {Author: author}}}
+	out, err := MapWithOptions[MemoFeedDTO](mapper, feed, WithMemoization(4))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dest := out.Posts
+
+	dest[1].Author.Tags[0] = "edited"
+	if dest[0].Author.Tags[0] != "go" || dest[2].Author.Tags[0] != "go" {
+		t.Errorf("expected memoized results not to share slices, got %v and %v", dest[0].Author.Tags, dest[2].Author.Tags)
+	}
+}
+
+func TestTrackingCyclicDestinations(t *testing.T) {
+	mapper := New()
+	CreateMap[Category, CategoryDTO](mapper).WithBackReference("Parent")
+
+	root := &Category{Name: "root", Children: []*Category{{Name: "child"}}}
+	dest, err := Map[*CategoryDTO](mapper, root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Children[0].Parent != dest {
+		t.Fatal("expected a cyclic destination")
+	}
+
+	copied := deepCopyValue(reflect.ValueOf(dest)).Interface().(*CategoryDTO)
+	if copied == dest || copied.Children[0].Parent != copied {
+		t.Error("expected the copy to keep its cycle without sharing the original")
+	}
+
+	root.Name = "renamed"
+	changed, err := MapToTracked(mapper, root, dest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed) == 0 || changed[0] != "Name" {
+		t.Errorf("expected Name to change, got %v", changed)
+	}
+
+	before := &GraphNodeDTO{Name: "a"}
+	before.Next = before
+	after := &GraphNodeDTO{Name: "b"}
+	after.Next = after
+	if paths := FieldMaskPaths(before, after); !reflect.DeepEqual(paths, []string{"name"}) {
+		t.Errorf("expected name to differ, got %v", paths)
+	}
+
+	var observed []string
+	root.Name = "again"
+	err = MapToWithOptions(mapper, root, dest, OnMemberAssigned(func(path string, old, new any) {
+		observed = append(observed, path)
+	}))
+	if err != nil || len(observed) == 0 || observed[0] != "Name" {
+		t.Errorf("expected Name to be observed, got %v, %v", observed, err)
+	}
+}
+
+type MemoLeaf struct {
+	Name string
+}
+
+type MemoLeafDTO struct {
+	Name  string
+	Final bool
+	After int
+}
+
+type MemoPair struct {
+	A MemoLeaf
+	B MemoLeaf
+}
+
+type MemoPairDTO struct {
+	A MemoLeafDTO
+	B MemoLeafDTO
+}
+
+func TestMemoizationWithCyclesAndHooks(t *testing.T) {
+	mapper := New()
+	CreateMap[Category, CategoryDTO](mapper).WithBackReference("Parent")
+
+	shared := &Category{Name: "shared"}
+	root := &Category{Name: "root", Children: []*Category{shared, shared}}
+	tree, err := MapWithOptions[*CategoryDTO](mapper, root, WithMemoization(100))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tree.Children[0].Parent != tree || tree.Children[1].Parent != tree {
+		t.Error("expected memoized children to reference the mapped root")
+	}
+
+	a := &GraphNode{Name: "a"}
+	b := &GraphNode{Name: "b", Next: a}
+	a.Next = b
+	a.Peers = []*GraphNode{b, b}
+	graph, err := MapWithOptions[*GraphNodeDTO](mapper, a, WithPreserveReferences(), WithMemoization(100))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if graph.Next.Name != "b" || graph.Next.Next.Name != "a" {
+		t.Errorf("unexpected graph: %+v", graph)
+	}
+
+	hooked := New()
+	CreateMap[MemoLeaf, MemoLeafDTO](hooked).
+		AfterMap(func(src *MemoLeaf, dest *MemoLeafDTO) error {
+			dest.After++
+			return nil
+		}).
+		Finalize(func(dest *MemoLeafDTO) error {
+			dest.Final = true
+			return nil
+		})
+	CreateMap[MemoPair, MemoPairDTO](hooked)
+
+	leaf := MemoLeaf{Name: "x"}
+	pair, err := MapWithOptions[MemoPairDTO](hooked, MemoPair{A: leaf, B: leaf}, WithMemoization(8))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !pair.A.Final || !pair.B.Final || pair.A.After != 1 || pair.B.After != 1 {
+		t.Errorf("expected hooks and finalizers for reused results, got %+v", pair)
+	}
+}
+
+type FolderSource struct {
+	Name   string
+	Parent *FolderSource
+	Kids   []*FolderSource
+}
+
+type FolderDTO struct {
+	Name string
+}
+
+func TestErrorSnapshotOfCyclicSource(t *testing.T) {
+	mapper := NewWithConfig(WithErrorSourceSnapshot())
+	CreateMap[FolderSource, FolderDTO](mapper).
+		ForMemberByName("Name", MapFromFunc(func(src, dest any) (any, error) {
+			return nil, errors.New("lookup failed")
+		}))
+
+	root := &FolderSource{Name: "root"}
+	root.Kids = []*FolderSource{{Name: "kid", Parent: root}}
+	_, err := Map[FolderDTO](mapper, root)
+
+	var mappingErr *MappingError
+	if !errors.As(err, &mappingErr) || mappingErr.Source == nil {
+		t.Fatalf("expected an error with a snapshot, got %v", err)
+	}
+	if !strings.Contains(fmt.Sprint(mappingErr.Source), "<cycle>") {
+		t.Errorf("expected the back-reference to be marked, got %v", mappingErr.Source)
+	}
+
+	deep := &FolderSource{Name: "leaf"}
+	for i := 0; i < 100; i++ {
+		deep = &FolderSource{Name: "level", Parent: deep}
+	}
+	_, err = Map[FolderDTO](mapper, deep)
+	if !errors.As(err, &mappingErr) || !strings.Contains(fmt.Sprint(mappingErr.Source), "<max depth>") {
+		t.Errorf("expected the snapshot depth to be capped, got %v", err)
+	}
+}
+
+type ShipPartA struct{ A int }
+type ShipPartB struct{ B int }
+type ShipPartC struct{ C int }
+type ShipPartD struct{ D int }
+
+type ShipmentParts struct {
+	ShipPartA ShipPartA
+	ShipPartB ShipPartB
+	ShipPartC ShipPartC
+	ShipPartD ShipPartD
+}
+
+type ShipmentEmbedded struct {
+	ShipPartA
+	ShipPartB
+	ShipPartC
+	ShipPartD
+}
+
+func TestEmbeddedMembersInFieldOrder(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		mapper := New()
+		CreateMap[ShipmentParts, ShipmentEmbedded](mapper)
+		plan, err := PlanOf[ShipmentParts, ShipmentEmbedded](mapper)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var members []string
+		for _, ins := range plan.Instructions {
+			members = append(members, ins.Member)
+		}
+		want := []string{"ShipPartA", "ShipPartB", "ShipPartC", "ShipPartD"}
+		if !reflect.DeepEqual(members, want) {
+			t.Fatalf("expected embedded members in field order, got %v", members)
+		}
+	}
 }
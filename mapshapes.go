@@ -0,0 +1,150 @@
+package automapper
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// mapCopier copies a map of a common shape with a typed loop instead of
+// per-entry reflection, and reports whether it did. Callers fall back to
+// per-entry mapping when it does not.
+type mapCopier func(src, dest reflect.Value) bool
+
+// mapCopierIndex caches the typed copy loops selected for map type pairs
+// at one configuration generation.
+type mapCopierIndex struct {
+	generation uint64
+	copiers    sync.Map // map[typeMapKey]mapCopier
+}
+
+// mapCopierFor returns the typed copy loop for maps from srcType to
+// destType, or nil. It is selected once per configuration generation.
+func (m *Mapper) mapCopierFor(srcType, destType reflect.Type) mapCopier {
+	gen := m.config.generation.Load()
+	index := m.config.mapCopiers.Load()
+	if index == nil || index.generation != gen {
+		index = &mapCopierIndex{generation: gen}
+		m.config.mapCopiers.Store(index)
+	}
+
+	key := typeMapKey{srcType: srcType, destType: destType}
+	if copier, ok := index.copiers.Load(key); ok {
+		return copier.(mapCopier)
+	}
+	m.config.mu.RLock()
+	copier := m.selectMapCopier(srcType, destType)
+	m.config.mu.RUnlock()
+	index.copiers.Store(key, copier)
+	return copier
+}
+
+// copiesMapValues copies src into dest with a typed loop for MapMap, and
+// reports whether it did. NilSourceError checks every value, so it keeps
+// the generic path.
+func (m *Mapper) copiesMapValues(srcType, destType reflect.Type, src, dest reflect.Value) bool {
+	if m.config.nilSource == NilSourceError {
+		return false
+	}
+	copier := m.mapCopierFor(srcType, destType)
+	return copier != nil && copier(src, dest)
+}
+
+// selectMapCopier returns the typed copy loop for maps from srcType to
+// destType if both have the layout of map[string]string, map[string]int
+// or map[string]any, such as named map types or maps of named string
+// keys or values, or nil. Values that a registered converter or type map
+// could change keep the generic path, and so do interface values under
+// WithInterfacePolicy or WithJSONSafeInterfaces. The caller must hold
+// config.mu.
+func (m *Mapper) selectMapCopier(srcType, destType reflect.Type) mapCopier {
+	if srcType.Kind() != reflect.Map || destType.Kind() != reflect.Map {
+		return nil
+	}
+	for _, conv := range []struct {
+		shape reflect.Type
+		copy  mapCopier
+	}{
+		{reflect.TypeOf(map[string]string(nil)), copyMapShape[string]},
+		{reflect.TypeOf(map[string]int(nil)), copyMapShape[int]},
+		{reflect.TypeOf(map[string]any(nil)), copyAnyMap},
+	} {
+		if !hasMapShape(srcType, conv.shape) || !hasMapShape(destType, conv.shape) {
+			continue
+		}
+		// Keys and values of other types need implicit conversion
+		if !m.sameOrConvertible(srcType.Key(), destType.Key()) || !m.sameOrConvertible(srcType.Elem(), destType.Elem()) {
+			return nil
+		}
+		if m.hasConverterTo(conv.shape.Elem()) || m.hasConverterTo(destType.Elem()) {
+			return nil
+		}
+		if conv.shape.Elem().Kind() == reflect.Interface && (m.config.ifacePolicy != InterfaceAssign || m.config.jsonSafe) {
+			return nil
+		}
+		return conv.copy
+	}
+	return nil
+}
+
+// sameOrConvertible reports whether srcType is destType or converts to it.
+func (m *Mapper) sameOrConvertible(srcType, destType reflect.Type) bool {
+	return srcType == destType || m.convertible(srcType, destType)
+}
+
+// hasMapShape reports whether map type t is laid out like shape: named
+// string and int types are represented as string and int, and empty
+// interfaces as any.
+func hasMapShape(t, shape reflect.Type) bool {
+	key, elem := t.Key(), t.Elem()
+	if key.Kind() != shape.Key().Kind() || elem.Kind() != shape.Elem().Kind() {
+		return false
+	}
+	return elem.Kind() != reflect.Interface || elem.NumMethod() == 0
+}
+
+// hasConverterTo reports whether a converter into destType is registered.
+// The caller must hold config.mu.
+func (m *Mapper) hasConverterTo(destType reflect.Type) bool {
+	for key := range m.config.converters {
+		if key.destType == destType {
+			return true
+		}
+	}
+	return false
+}
+
+// copyMapShape copies a map laid out like map[string]V. Both maps are
+// viewed through that type, which shares their layout.
+func copyMapShape[V any](src, dest reflect.Value) bool {
+	if src.IsNil() {
+		return false
+	}
+	s := viewMap[V](src)
+	d := make(map[string]V, len(s))
+	for k, v := range s {
+		d[k] = v
+	}
+	dest.Set(reflect.NewAt(dest.Type(), unsafe.Pointer(&d)).Elem())
+	return true
+}
+
+// viewMap returns map v, laid out like map[string]V, as a map[string]V.
+func viewMap[V any](v reflect.Value) map[string]V {
+	ptr := v.UnsafePointer()
+	return *(*map[string]V)(unsafe.Pointer(&ptr))
+}
+
+// copyAnyMap copies a map laid out like map[string]any, unless a value is
+// a struct, which may map through a registered type map.
+func copyAnyMap(src, dest reflect.Value) bool {
+	if src.IsNil() {
+		return false
+	}
+	for _, v := range viewMap[any](src) {
+		if t := reflect.TypeOf(v); t != nil && derefType(t).Kind() == reflect.Struct {
+			return false
+		}
+	}
+	return copyMapShape[any](src, dest)
+}
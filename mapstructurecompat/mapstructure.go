@@ -0,0 +1,42 @@
+// Package mapstructurecompat provides the Decode function of
+// github.com/mitchellh/mapstructure on top of automapper, so projects can
+// migrate call sites incrementally while gaining its reflection caching and
+// registered type maps.
+package mapstructurecompat
+
+import automapper "github.com/csmart-libs/go-automapper"
+
+// TagName is the struct tag naming the map key of a field, as in
+// mapstructure.
+const TagName = "mapstructure"
+
+// defaultMapper backs Decode and weakMapper backs WeakDecode.
+var (
+	defaultMapper = NewMapper()
+	weakMapper    = NewMapper(automapper.WithWeakTypeConversion())
+)
+
+// NewMapper returns a mapper that decodes map keys using the mapstructure
+// tag, for callers that want to register their own type maps or converters
+// and decode with DecodeWith.
+func NewMapper(opts ...automapper.ConfigOption) *automapper.Mapper {
+	return automapper.NewWithConfig(append([]automapper.ConfigOption{automapper.WithMapKeyTag(TagName)}, opts...)...)
+}
+
+// Decode decodes input, typically a map[string]any, into the value output
+// points to. Keys match the mapstructure tag or, without one, the field name
+// ignoring case.
+func Decode(input any, output any) error {
+	return DecodeWith(defaultMapper, input, output)
+}
+
+// WeakDecode is Decode with weak typing, as mapstructure.WeakDecode:
+// strings, numbers and bools convert into each other.
+func WeakDecode(input any, output any) error {
+	return DecodeWith(weakMapper, input, output)
+}
+
+// DecodeWith is Decode using m.
+func DecodeWith(m *automapper.Mapper, input any, output any) error {
+	return automapper.MapToAny(m, input, output)
+}
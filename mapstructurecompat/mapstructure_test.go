@@ -0,0 +1,49 @@
+package mapstructurecompat
+
+import "testing"
+
+type address struct {
+	City string
+}
+
+type person struct {
+	Name    string `mapstructure:"full_name"`
+	Age     int
+	Emails  []string
+	Address address
+	Secret  string `mapstructure:"-"`
+}
+
+func TestDecode(t *testing.T) {
+	input := map[string]any{
+		"full_name": "John Doe",
+		"age":       30,
+		"Emails":    []any{"a@example.com", "b@example.com"},
+		"address":   map[string]any{"city": "Springfield"},
+		"Secret":    "hidden",
+	}
+
+	var p person
+	if err := Decode(input, &p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name != "John Doe" || p.Age != 30 || p.Address.City != "Springfield" {
+		t.Errorf("unexpected decode result: %+v", p)
+	}
+	if len(p.Emails) != 2 || p.Emails[1] != "b@example.com" {
+		t.Errorf("unexpected emails: %+v", p.Emails)
+	}
+	if p.Secret != "" {
+		t.Errorf("expected field tagged \"-\" to be skipped, got %q", p.Secret)
+	}
+}
+
+func TestWeakDecode(t *testing.T) {
+	var p person
+	if err := WeakDecode(map[string]any{"full_name": 42, "age": "30"}, &p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name != "42" || p.Age != 30 {
+		t.Errorf("unexpected weak decode result: %+v", p)
+	}
+}
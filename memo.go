@@ -0,0 +1,140 @@
+package automapper
+
+import "reflect"
+
+// WithMemoization caches the results of struct mappings within one call,
+// so a source value that appears many times in an object graph, such as
+// the same Customer on thousands of Orders, is only mapped once. Comparable
+// source values are keyed by value and others by address; source values
+// are assumed not to change during the call. At most cacheSize results are
+// kept, evicting the oldest first; a cacheSize of zero or less means no
+// limit. Memoized results overwrite rather than merge into existing
+// destination structs, and each reuse is a deep copy that can be changed
+// independently. Reused results run the AfterMap hooks and finalizers of
+// their own type map again, but not those of their nested members.
+func WithMemoization(cacheSize int) MapOption {
+	return func(c *MappingContext) {
+		c.memo = &memoCache{
+			limit:   cacheSize,
+			entries: make(map[memoKey]reflect.Value),
+		}
+	}
+}
+
+// memoKey identifies a memoized struct mapping: the source by value or by
+// address, together with the source and destination types.
+type memoKey struct {
+	srcType  reflect.Type
+	destType reflect.Type
+	value    any
+	addr     uintptr
+}
+
+// memoCache is a bounded FIFO cache of mapped destination structs.
+type memoCache struct {
+	limit   int
+	entries map[memoKey]reflect.Value
+	order   []memoKey
+}
+
+// memoKeyFor returns the cache key for mapping srcVal into destType, or
+// false if the source can be keyed neither by value nor by address.
+func memoKeyFor(srcVal reflect.Value, destType reflect.Type) (memoKey, bool) {
+	key := memoKey{srcType: srcVal.Type(), destType: destType}
+	switch {
+	case srcVal.Comparable():
+		key.value = srcVal.Interface()
+	case srcVal.CanAddr():
+		key.addr = srcVal.UnsafeAddr()
+	default:
+		return key, false
+	}
+	return key, true
+}
+
+// get returns a deep copy of the cached destination for key, so results
+// reused in several places share no slices, maps or pointers.
+func (c *memoCache) get(key memoKey) (reflect.Value, bool) {
+	v, ok := c.entries[key]
+	if !ok {
+		return v, false
+	}
+	return deepCopyValue(v), true
+}
+
+// put stores a deep copy of destVal under key, evicting the oldest entry if
+// the cache is full.
+func (c *memoCache) put(key memoKey, destVal reflect.Value) {
+	if _, exists := c.entries[key]; exists {
+		return
+	}
+	if c.limit > 0 && len(c.order) >= c.limit {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.entries[key] = deepCopyValue(destVal)
+	c.order = append(c.order, key)
+}
+
+// reuseMemoized sets destVal to a memoized result, which holds the members
+// as mapped before AfterMap hooks ran, then runs the type map's AfterMap
+// hooks and queues its finalizers as a mapping of srcVal would.
+func (m *Mapper) reuseMemoized(ctx *MappingContext, srcVal, destVal, cached reflect.Value) error {
+	destVal.Set(cached)
+
+	m.config.mu.RLock()
+	typeMap := m.config.typeMaps[typeMapKey{srcType: srcVal.Type(), destType: destVal.Type()}]
+	m.config.mu.RUnlock()
+	if typeMap == nil {
+		return nil
+	}
+
+	if m.runsHooks(ctx) && len(typeMap.afterMap) > 0 {
+		if err := m.runHooks(ctx, typeMap, typeMap.afterMap, srcVal.Interface(), destVal.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	if len(typeMap.finalizers) > 0 {
+		ctx.queueFinalizers(typeMap, destVal)
+	}
+	return nil
+}
+
+// mapStructMemoized maps a struct through the context's memoization cache.
+func (m *Mapper) mapStructMemoized(ctx *MappingContext, srcVal, destVal reflect.Value, srcType, destType reflect.Type) error {
+	key, ok := memoKeyFor(srcVal, destType)
+	if !ok {
+		return m.mapStructUncached(ctx, srcVal, destVal, srcType, destType)
+	}
+	if cached, hit := ctx.memo.get(key); hit {
+		return m.reuseMemoized(ctx, srcVal, destVal, cached)
+	}
+	ctx.memoNext = &key
+	err := m.mapStructUncached(ctx, srcVal, destVal, srcType, destType)
+	ctx.memoNext = nil
+	if err != nil {
+		return err
+	}
+	// Custom mappers store their result once it is complete
+	ctx.memo.put(key, destVal)
+	return nil
+}
+
+// takeMemoKey returns the memoization key handed over by mapStructMemoized
+// for the struct about to be mapped, or nil, so nested structs do not see it.
+func (c *MappingContext) takeMemoKey() *memoKey {
+	if c == nil {
+		return nil
+	}
+	key := c.memoNext
+	c.memoNext = nil
+	return key
+}
+
+// memoizeMapped stores destVal under key once its members are mapped and
+// before AfterMap hooks run, so reused results run the hooks afresh.
+func (c *MappingContext) memoizeMapped(key *memoKey, destVal reflect.Value) {
+	if key != nil {
+		c.memo.put(*key, destVal)
+	}
+}
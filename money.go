@@ -0,0 +1,298 @@
+package automapper
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode selects how amounts with more decimal places than their
+// currency allows are rounded to minor units.
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest minor unit, ties to even
+	// (banker's rounding). It is the default.
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds to the nearest minor unit, ties away from zero.
+	RoundHalfUp
+	// RoundDown truncates towards zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+)
+
+// MoneyOption configures a money converter registered with ConvertMoney.
+type MoneyOption func(*moneyConfig)
+
+// moneyConfig holds the settings of a money converter.
+type moneyConfig struct {
+	rounding  RoundingMode
+	exponents map[string]int
+}
+
+// currencyExponents lists ISO 4217 currencies whose minor unit is not a
+// hundredth. Every other currency uses two decimal places.
+var currencyExponents = map[string]int{
+	"BHD": 3, "CLP": 0, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "LYD": 3, "OMR": 3, "PYG": 0, "TND": 3, "UGX": 0, "VND": 0,
+}
+
+// WithRounding sets the rounding mode of a money converter.
+func WithRounding(mode RoundingMode) MoneyOption {
+	return func(c *moneyConfig) {
+		c.rounding = mode
+	}
+}
+
+// WithCurrencyExponent sets the number of decimal places of a currency's
+// minor unit, overriding the ISO 4217 default.
+func WithCurrencyExponent(currency string, digits int) MoneyOption {
+	return func(c *moneyConfig) {
+		c.exponents[currency] = digits
+	}
+}
+
+// ConvertMoney registers a global converter between two money
+// representations, never going through float arithmetic. Each side is one of:
+//
+//   - a struct with an Amount, Units or MinorUnits field and an optional
+//     Currency or CurrencyCode string field; an integer amount holds minor
+//     units (cents), a string amount a decimal ("12.34") and a float amount
+//     major units
+//   - a string holding a decimal amount, optionally followed by a currency
+//     code ("12.34 EUR")
+//   - an integer holding minor units
+//
+// Amounts with more decimal places than the currency allows are rounded
+// with RoundHalfEven unless WithRounding says otherwise. ConvertMoney panics
+// if either type is not a money representation, a programming error in the
+// type arguments that no input can fix. A converter rejected because a type
+// map is sealed is reported as by ConvertUsing.
+func ConvertMoney[TSrc, TDest any](m *Mapper, opts ...MoneyOption) error {
+	cfg := &moneyConfig{exponents: make(map[string]int)}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	srcType := reflect.TypeOf((*TSrc)(nil)).Elem()
+	destType := reflect.TypeOf((*TDest)(nil)).Elem()
+	srcShape, srcOK := moneyShapeOf(srcType)
+	destShape, destOK := moneyShapeOf(destType)
+	if !srcOK || !destOK {
+		panic(&MappingError{
+			Message:  "ConvertMoney requires money representations on both sides",
+			Code:     CodeIncompatibleTypes,
+			SrcType:  srcType,
+			DestType: destType,
+		})
+	}
+
+	return ConvertUsing(m, func(src TSrc) (TDest, error) {
+		var dest TDest
+		minor, currency, err := srcShape.read(reflect.ValueOf(src), cfg)
+		if err != nil {
+			return dest, &MappingError{
+				Message:    "invalid money amount",
+				Code:       CodeConversion,
+				SrcType:    srcType,
+				DestType:   destType,
+				InnerError: err,
+			}
+		}
+		destShape.write(reflect.ValueOf(&dest).Elem(), minor, currency, cfg)
+		return dest, nil
+	})
+}
+
+// moneyAmountKind is how a money representation stores its amount.
+type moneyAmountKind int
+
+const (
+	moneyMinor   moneyAmountKind = iota // integer minor units
+	moneyDecimal                        // decimal string
+	moneyMajor                          // float major units
+)
+
+// moneyShape locates the amount and currency of a money representation.
+// Indices are nil for non-struct representations.
+type moneyShape struct {
+	kind        moneyAmountKind
+	amountIdx   []int
+	currencyIdx []int
+}
+
+// moneyAmountKindOf classifies an amount type.
+func moneyAmountKindOf(t reflect.Type) (moneyAmountKind, bool) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return moneyMinor, true
+	case reflect.String:
+		return moneyDecimal, true
+	case reflect.Float32, reflect.Float64:
+		return moneyMajor, true
+	}
+	return 0, false
+}
+
+// moneyShapeOf reports the money shape of t.
+func moneyShapeOf(t reflect.Type) (moneyShape, bool) {
+	if t.Kind() != reflect.Struct {
+		kind, ok := moneyAmountKindOf(t)
+		if kind == moneyMajor {
+			// Bare floats are not accepted: they are the problem being solved
+			return moneyShape{}, false
+		}
+		return moneyShape{kind: kind}, ok
+	}
+
+	var shape moneyShape
+	for _, name := range []string{"Amount", "Units", "MinorUnits"} {
+		if field, ok := t.FieldByName(name); ok && field.IsExported() {
+			kind, ok := moneyAmountKindOf(field.Type)
+			if !ok {
+				return moneyShape{}, false
+			}
+			shape.kind, shape.amountIdx = kind, field.Index
+			break
+		}
+	}
+	if shape.amountIdx == nil {
+		return moneyShape{}, false
+	}
+	for _, name := range []string{"Currency", "CurrencyCode"} {
+		if field, ok := t.FieldByName(name); ok && field.IsExported() && field.Type.Kind() == reflect.String {
+			shape.currencyIdx = field.Index
+			break
+		}
+	}
+	return shape, true
+}
+
+// exponent returns the minor unit decimal places of a currency.
+func (c *moneyConfig) exponent(currency string) int {
+	if digits, ok := c.exponents[currency]; ok {
+		return digits
+	}
+	if digits, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return digits
+	}
+	return 2
+}
+
+// read extracts the amount in minor units and the currency code.
+func (s moneyShape) read(v reflect.Value, cfg *moneyConfig) (int64, string, error) {
+	amount := v
+	var currency string
+	if s.amountIdx != nil {
+		amount = v.FieldByIndex(s.amountIdx)
+		if s.currencyIdx != nil {
+			currency = v.FieldByIndex(s.currencyIdx).String()
+		}
+	}
+
+	switch s.kind {
+	case moneyMinor:
+		return amount.Int(), currency, nil
+	case moneyMajor:
+		decimal := strconv.FormatFloat(amount.Float(), 'f', -1, amount.Type().Bits())
+		minor, err := toMinorUnits(decimal, cfg.exponent(currency), cfg.rounding)
+		return minor, currency, err
+	}
+
+	decimal := strings.TrimSpace(amount.String())
+	if s.amountIdx == nil {
+		if number, code, ok := strings.Cut(decimal, " "); ok {
+			decimal, currency = number, strings.TrimSpace(code)
+		}
+	}
+	if decimal == "" {
+		return 0, currency, nil
+	}
+	minor, err := toMinorUnits(decimal, cfg.exponent(currency), cfg.rounding)
+	return minor, currency, err
+}
+
+// write stores an amount in minor units and a currency code.
+func (s moneyShape) write(v reflect.Value, minor int64, currency string, cfg *moneyConfig) {
+	amount := v
+	if s.amountIdx != nil {
+		amount = v.FieldByIndex(s.amountIdx)
+		if s.currencyIdx != nil {
+			v.FieldByIndex(s.currencyIdx).SetString(currency)
+		}
+	}
+
+	exp := cfg.exponent(currency)
+	switch s.kind {
+	case moneyMinor:
+		amount.SetInt(minor)
+	case moneyMajor:
+		major, _ := strconv.ParseFloat(formatMinorUnits(minor, exp), 64)
+		amount.SetFloat(major)
+	default:
+		decimal := formatMinorUnits(minor, exp)
+		if s.amountIdx == nil && currency != "" {
+			decimal += " " + currency
+		}
+		amount.SetString(decimal)
+	}
+}
+
+// toMinorUnits parses a decimal amount into minor units with exp decimal
+// places, rounding excess precision with mode.
+func toMinorUnits(decimal string, exp int, mode RoundingMode) (int64, error) {
+	r, ok := new(big.Rat).SetString(decimal)
+	if !ok {
+		return 0, fmt.Errorf("cannot parse %q as a decimal amount", decimal)
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)))
+
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		// Compare twice the remainder with the denominator to find ties
+		half := new(big.Int).Abs(rem)
+		half.Mul(half, big.NewInt(2))
+		cmp := half.Cmp(r.Denom())
+
+		away := false
+		switch mode {
+		case RoundUp:
+			away = true
+		case RoundHalfUp:
+			away = cmp >= 0
+		case RoundHalfEven:
+			away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+		}
+		if away {
+			q.Add(q, big.NewInt(int64(r.Sign())))
+		}
+	}
+
+	if !q.IsInt64() {
+		return 0, fmt.Errorf("amount %q overflows minor units", decimal)
+	}
+	return q.Int64(), nil
+}
+
+// formatMinorUnits writes minor units as a decimal with exp decimal places.
+func formatMinorUnits(minor int64, exp int) string {
+	digits := new(big.Int).Abs(big.NewInt(minor)).String()
+	if exp == 0 {
+		if minor < 0 {
+			return "-" + digits
+		}
+		return digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
@@ -0,0 +1,127 @@
+package automapper
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+)
+
+// Network address and URL types with built-in string conversions.
+var (
+	ipType       = reflect.TypeOf(net.IP(nil))
+	addrType     = reflect.TypeOf(netip.Addr{})
+	prefixType   = reflect.TypeOf(netip.Prefix{})
+	addrPortType = reflect.TypeOf(netip.AddrPort{})
+	urlType      = reflect.TypeOf(url.URL{})
+)
+
+// isNetworkType reports whether t is net.IP, netip.Addr, netip.Prefix,
+// netip.AddrPort or url.URL.
+func isNetworkType(t reflect.Type) bool {
+	switch t {
+	case ipType, addrType, prefixType, addrPortType, urlType:
+		return true
+	}
+	return false
+}
+
+// assignNetwork maps network addresses and URLs to and from their string
+// forms, and net.IP to and from netip.Addr, and reports whether it did.
+// Nil and invalid addresses map to the empty string and the empty string
+// maps to the zero value.
+func assignNetwork(srcVal, destVal reflect.Value) (bool, error) {
+	srcType, destType := srcVal.Type(), destVal.Type()
+
+	switch {
+	case destType.Kind() == reflect.String && isNetworkType(srcType):
+		destVal.SetString(formatNetwork(srcVal))
+		return true, nil
+
+	case srcType.Kind() == reflect.String && isNetworkType(destType):
+		if srcVal.String() == "" {
+			destVal.Set(reflect.Zero(destType))
+			return true, nil
+		}
+		parsed, err := parseNetwork(srcVal.String(), destType)
+		if err != nil {
+			return true, &MappingError{
+				Message:    fmt.Sprintf("cannot parse %q as %v", srcVal.String(), destType),
+				Code:       CodeConversion,
+				SrcType:    srcType,
+				DestType:   destType,
+				InnerError: err,
+			}
+		}
+		destVal.Set(reflect.ValueOf(parsed))
+		return true, nil
+
+	case srcType == ipType && destType == addrType:
+		addr, _ := netip.AddrFromSlice(srcVal.Interface().(net.IP))
+		destVal.Set(reflect.ValueOf(addr.Unmap()))
+		return true, nil
+
+	case srcType == addrType && destType == ipType:
+		var ip net.IP
+		if addr := srcVal.Interface().(netip.Addr); addr.IsValid() {
+			ip = addr.AsSlice()
+		}
+		destVal.Set(reflect.ValueOf(ip))
+		return true, nil
+	}
+	return false, nil
+}
+
+// formatNetwork returns the string form of a network address or URL.
+func formatNetwork(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case net.IP:
+		if len(x) == 0 {
+			return ""
+		}
+		return x.String()
+	case netip.Addr:
+		if !x.IsValid() {
+			return ""
+		}
+		return x.String()
+	case netip.Prefix:
+		if !x.IsValid() {
+			return ""
+		}
+		return x.String()
+	case netip.AddrPort:
+		if !x.IsValid() {
+			return ""
+		}
+		return x.String()
+	case url.URL:
+		return x.String()
+	}
+	return ""
+}
+
+// parseNetwork parses s as a value of the network type t.
+func parseNetwork(s string, t reflect.Type) (any, error) {
+	switch t {
+	case ipType:
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, &net.ParseError{Type: "IP address", Text: s}
+		}
+		return ip, nil
+	case addrType:
+		return netip.ParseAddr(s)
+	case prefixType:
+		return netip.ParsePrefix(s)
+	case addrPortType:
+		return netip.ParseAddrPort(s)
+	default:
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		return *u, nil
+	}
+}
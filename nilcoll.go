@@ -0,0 +1,113 @@
+package automapper
+
+import "reflect"
+
+// NilCollectionPolicy controls what nil source slices and maps produce in
+// slice and map destination members.
+type NilCollectionPolicy int
+
+const (
+	// NilCollectionsDefault defers to the type map's policy, then to
+	// WithAllowNullCollections (the default).
+	NilCollectionsDefault NilCollectionPolicy = iota
+	// NilCollectionsEmpty maps nil to empty slices and maps, which encode
+	// to JSON as [] and {}.
+	NilCollectionsEmpty
+	// NilCollectionsNull maps nil to nil, which encodes to JSON as null.
+	NilCollectionsNull
+)
+
+// WithNilCollections sets what nil source slices and maps produce in the
+// slice and map members of this type map, unless a member sets its own
+// policy with NilCollections.
+func (b *TypeMapBuilder[TSrc, TDest]) WithNilCollections(policy NilCollectionPolicy) *TypeMapBuilder[TSrc, TDest] {
+	b.update(func(tm *TypeMap) {
+		tm.nilColl = policy
+	})
+	return b
+}
+
+// NilCollections sets what a nil source slice or map produces in a slice or
+// map member, overriding the type map and mapper settings.
+func NilCollections(policy NilCollectionPolicy) MemberOption {
+	return func(mm *MemberMap) {
+		mm.nilColl = policy
+	}
+}
+
+// nilCollectionMember returns mm, or a copy carrying the type map's nil
+// collection policy if mm has none of its own.
+func (tm *TypeMap) nilCollectionMember(mm *MemberMap) *MemberMap {
+	if tm.nilColl == NilCollectionsDefault || mm.nilColl != NilCollectionsDefault {
+		return mm
+	}
+	cp := *mm
+	cp.nilColl = tm.nilColl
+	return &cp
+}
+
+// assignNilCollection applies policy when a nil slice or map is mapped to a
+// slice or map, and reports whether it did.
+func assignNilCollection(policy NilCollectionPolicy, srcVal, destVal reflect.Value) bool {
+	if policy == NilCollectionsDefault {
+		return false
+	}
+	if kind := srcVal.Kind(); (kind != reflect.Slice && kind != reflect.Map) || !srcVal.IsNil() {
+		return false
+	}
+
+	destType := destVal.Type()
+	switch {
+	case destType.Kind() != reflect.Slice && destType.Kind() != reflect.Map:
+		return false
+	case policy == NilCollectionsNull:
+		destVal.Set(reflect.Zero(destType))
+	case destType.Kind() == reflect.Slice:
+		destVal.Set(reflect.MakeSlice(destType, 0, 0))
+	default:
+		destVal.Set(reflect.MakeMap(destType))
+	}
+	return true
+}
+
+// EmptyMapPolicy controls what a source map without entries, nil or empty,
+// produces in a map destination member.
+type EmptyMapPolicy int
+
+const (
+	// EmptyMapDefault maps empty maps like any other map: to an empty map,
+	// and nil maps as the nil collection policy says (the default).
+	EmptyMapDefault EmptyMapPolicy = iota
+	// EmptyMapEmpty maps nil and empty maps to an empty map.
+	EmptyMapEmpty
+	// EmptyMapNil maps nil and empty maps to nil.
+	EmptyMapNil
+	// EmptyMapPreserve leaves the destination's current map in place, e.g.
+	// the existing entries of a destination passed to MapTo.
+	EmptyMapPreserve
+)
+
+// EmptyMaps sets what a map member receives when its source map has no
+// entries. It takes precedence over NilCollections for nil source maps.
+func EmptyMaps(policy EmptyMapPolicy) MemberOption {
+	return func(mm *MemberMap) {
+		mm.emptyMap = policy
+	}
+}
+
+// assignEmptyMap applies policy when a map without entries is mapped to a
+// map, and reports whether it did.
+func assignEmptyMap(policy EmptyMapPolicy, srcVal, destVal reflect.Value) bool {
+	if policy == EmptyMapDefault || srcVal.Kind() != reflect.Map || srcVal.Len() > 0 ||
+		destVal.Kind() != reflect.Map {
+		return false
+	}
+
+	switch policy {
+	case EmptyMapEmpty:
+		destVal.Set(reflect.MakeMap(destVal.Type()))
+	case EmptyMapNil:
+		destVal.Set(reflect.Zero(destVal.Type()))
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package automapper
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrNilSource is wrapped by the error of a top-level mapping call given a
+// nil source under NilSourceError.
+var ErrNilSource = errors.New("nil source")
+
+// NilSourcePolicy decides what top-level mapping calls do with a nil
+// source, such as a nil pointer.
+type NilSourcePolicy int
+
+const (
+	// NilSourceZero maps a nil source to the zero destination. This is
+	// the default.
+	NilSourceZero NilSourcePolicy = iota
+	// NilSourceNil makes MapPtr return a nil destination for a nil source,
+	// so callers can tell an absent source from an empty one. Other calls
+	// behave as with NilSourceZero.
+	NilSourceNil
+	// NilSourceError fails the call with an error wrapping ErrNilSource.
+	NilSourceError
+)
+
+// WithNilSourcePolicy sets how top-level mapping calls handle nil sources.
+// Nil members of a source are not affected.
+func WithNilSourcePolicy(policy NilSourcePolicy) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.nilSource = policy
+	}
+}
+
+// MapPtr maps src to a new destination and returns a pointer to it. Under
+// NilSourceNil a nil source yields a nil pointer.
+func MapPtr[TDest any](m *Mapper, src any) (*TDest, error) {
+	if m.config.nilSource == NilSourceNil && isNilSource(src) {
+		return nil, nil
+	}
+	dest, err := Map[TDest](m, src)
+	if err != nil {
+		return nil, err
+	}
+	return &dest, nil
+}
+
+// checkNilSource returns the error for a nil top-level source under
+// NilSourceError.
+func (m *Mapper) checkNilSource(src any, destType reflect.Type) error {
+	if m.config.nilSource != NilSourceError || !isNilSource(src) {
+		return nil
+	}
+	return &MappingError{
+		Message:    "source is nil",
+		Code:       CodeNilSource,
+		SrcType:    reflect.TypeOf(src),
+		DestType:   destType,
+		InnerError: ErrNilSource,
+	}
+}
+
+// isNilSource reports whether src is nil or a nil pointer.
+func isNilSource(src any) bool {
+	return src == nil || !derefValue(reflect.ValueOf(src)).IsValid()
+}
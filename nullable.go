@@ -0,0 +1,80 @@
+package automapper
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// nullableTypes are the database/sql nullable wrappers. Source types
+// convertible to one of them, such as gorm.DeletedAt (a sql.NullTime), are
+// unwrapped when mapped to plain, pointer or bool destinations.
+var nullableTypes = []reflect.Type{
+	reflect.TypeOf(sql.NullTime{}),
+	reflect.TypeOf(sql.NullString{}),
+	reflect.TypeOf(sql.NullInt64{}),
+	reflect.TypeOf(sql.NullInt32{}),
+	reflect.TypeOf(sql.NullInt16{}),
+	reflect.TypeOf(sql.NullByte{}),
+	reflect.TypeOf(sql.NullFloat64{}),
+	reflect.TypeOf(sql.NullBool{}),
+}
+
+// isNullableType reports whether t has the shape of a database/sql nullable
+// wrapper: a value field followed by a Valid bool.
+func isNullableType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return false
+	}
+	for _, nt := range nullableTypes {
+		if t.ConvertibleTo(nt) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignNullable maps a nullable wrapper into destVal and reports whether
+// it did. The wrapped value maps to destinations of its own type, pointers
+// to it (nil when not valid) and, for non-bool values, to bool destinations
+// reporting validity, so gorm.DeletedAt maps to *time.Time or bool.
+func (m *Mapper) assignNullable(srcVal, destVal reflect.Value) bool {
+	if !isNullableType(srcVal.Type()) {
+		return false
+	}
+
+	value := srcVal.Field(0)
+	valid := srcVal.Field(1).Bool()
+	destType := destVal.Type()
+
+	switch {
+	case destType.Kind() == reflect.Ptr && m.nullableConvertible(value.Type(), destType.Elem()):
+		if !valid {
+			destVal.Set(reflect.Zero(destType))
+			return true
+		}
+		ptr := reflect.New(destType.Elem())
+		ptr.Elem().Set(value.Convert(destType.Elem()))
+		destVal.Set(ptr)
+	case destType.Kind() == reflect.Bool && value.Kind() != reflect.Bool:
+		destVal.SetBool(valid)
+	case m.nullableConvertible(value.Type(), destType):
+		if !valid {
+			destVal.Set(reflect.Zero(destType))
+			return true
+		}
+		destVal.Set(value.Convert(destType))
+	default:
+		return false
+	}
+	return true
+}
+
+// nullableConvertible reports whether a wrapped value of type from maps to
+// to. Numbers are not converted to strings, which Go would treat as runes,
+// and other types only when implicit conversion is enabled.
+func (m *Mapper) nullableConvertible(from, to reflect.Type) bool {
+	if to.Kind() == reflect.String && from.Kind() != reflect.String {
+		return false
+	}
+	return from.AssignableTo(to) || m.convertible(from, to)
+}
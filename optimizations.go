@@ -1,7 +1,9 @@
 package automapper
 
 import (
+	"fmt"
 	"reflect"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -53,6 +55,51 @@ type TypeMapOptimized struct {
 	allPrimitive     bool
 	hasCustomLogic   bool
 	compiled         bool
+	standardOnly     bool        // see requiresStandardPath
+	diverged         atomic.Bool // reported by WithVerifyOptimizations
+	fallbacks        []layoutFallback
+}
+
+// layoutFallback records a member excluded from unsafe copying because its
+// source and destination layouts do not match.
+type layoutFallback struct {
+	member string
+	reason string
+}
+
+// layoutMismatch describes why the bytes of src cannot be copied into dest
+// with unsafeCopyField, or returns "" if they can: both fields need the same
+// kind, size and alignment, and offsets aligned for their type.
+func layoutMismatch(src, dest reflect.StructField) string {
+	switch {
+	case src.Type.Kind() != dest.Type.Kind():
+		return fmt.Sprintf("kinds differ (%s, %s)", src.Type.Kind(), dest.Type.Kind())
+	case src.Type.Size() != dest.Type.Size():
+		return fmt.Sprintf("sizes differ (%d, %d bytes)", src.Type.Size(), dest.Type.Size())
+	case src.Type.Align() != dest.Type.Align():
+		return fmt.Sprintf("alignments differ (%d, %d bytes)", src.Type.Align(), dest.Type.Align())
+	case src.Offset%uintptr(src.Type.Align()) != 0 || dest.Offset%uintptr(dest.Type.Align()) != 0:
+		return fmt.Sprintf("offsets %d and %d are not aligned to %d bytes", src.Offset, dest.Offset, src.Type.Align())
+	}
+	return ""
+}
+
+// compileOptimized compiles the optimized version of tm at level, recording
+// whether the configuration keeps it on the standard path.
+func (c *MapperConfiguration) compileOptimized(tm *TypeMap, level OptimizationLevel) *TypeMapOptimized {
+	opt := compileOptimizedTypeMap(tm, level)
+	opt.standardOnly = c.requiresStandardPath(tm)
+	return opt
+}
+
+// requiresStandardPath reports whether tm is always mapped by its plan:
+// member policies are evaluated per call, weak typing, display and duration
+// formatting and type map nil collection policies are compiled into plans,
+// and concurrent resolvers, string interning and usage counting run from
+// the plan.
+func (c *MapperConfiguration) requiresStandardPath(tm *TypeMap) bool {
+	return tm.memberPolicy != nil || tm.weakTypes || c.weakTypes || tm.display || tm.concurrency > 1 ||
+		tm.durations != DurationNative || tm.nilColl != NilCollectionsDefault || c.interning || c.usage != nil
 }
 
 // compileOptimizedTypeMap creates an optimized version of TypeMap.
@@ -81,6 +128,14 @@ func compileOptimizedTypeMap(tm *TypeMap, level OptimizationLevel) *TypeMapOptim
 			optMm.destOffset = destField.Offset
 			optMm.fieldSize = srcField.Type.Size()
 			optMm.directAssign = srcField.Type == destField.Type && optMm.isPrimitive
+			if optMm.directAssign {
+				// Fall back to the safe path if the byte-wise copy would not
+				// match the layout on this architecture
+				if reason := layoutMismatch(srcField, destField); reason != "" {
+					optMm.directAssign = false
+					opt.fallbacks = append(opt.fallbacks, layoutFallback{member: mm.destField, reason: reason})
+				}
+			}
 
 			if !optMm.isPrimitive {
 				opt.allPrimitive = false
@@ -89,8 +144,9 @@ func compileOptimizedTypeMap(tm *TypeMap, level OptimizationLevel) *TypeMapOptim
 			opt.allPrimitive = false
 		}
 
-		// Check for custom logic
-		if mm.resolver != nil || mm.converter != nil || mm.condition != nil {
+		// Check for custom logic; conditions on the source struct run in the
+		// optimized paths
+		if mm.transformsValue() || mm.valueCond != nil {
 			opt.hasCustomLogic = true
 			optMm.isPrimitive = false
 		}
@@ -112,10 +168,22 @@ func compileSpecializedMapper(opt *TypeMapOptimized) SpecializedMapper {
 	members := opt.optimizedMembers
 
 	return func(src, dest reflect.Value) error {
+		var srcAny any
 		for _, mm := range members {
 			if mm.ignore {
 				continue
 			}
+			if mm.condition != nil {
+				if srcAny == nil {
+					srcAny = src.Interface()
+				}
+				if !mm.condition(srcAny) {
+					continue
+				}
+			}
+			if mm.ptrCond != nil && !mm.ptrCond(src) {
+				continue
+			}
 			// Direct field copy using pre-computed indices
 			destField := dest.Field(mm.destFieldIdx[0])
 			srcField := src.Field(mm.srcFieldIdx[0])
@@ -153,13 +221,18 @@ func unsafeCopyField(srcPtr, destPtr unsafe.Pointer, srcOffset, destOffset, size
 }
 
 // mapMemberUnsafe maps a member using unsafe pointer operations for primitives.
-func (m *Mapper) mapMemberUnsafe(srcVal, destVal reflect.Value, mm *MemberMapOptimized) error {
+func (m *Mapper) mapMemberUnsafe(ctx *MappingContext, srcVal, destVal reflect.Value, mm *MemberMapOptimized) error {
 	if mm.ignore {
 		return nil
 	}
+	if !mm.passesCondition(srcVal) {
+		return nil
+	}
 
-	// Fast path for direct primitive assignment (only if both values are addressable)
+	// Fast path for direct primitive assignment (only if both values are addressable
+	// and no member options were configured after compilation)
 	if mm.directAssign && mm.isPrimitive && len(mm.srcFieldIdx) == 1 &&
+		!mm.transformsValue() && mm.valueCond == nil &&
 		srcVal.CanAddr() && destVal.CanAddr() {
 		srcPtr := unsafe.Pointer(srcVal.UnsafeAddr())
 		destPtr := unsafe.Pointer(destVal.UnsafeAddr())
@@ -168,28 +241,27 @@ func (m *Mapper) mapMemberUnsafe(srcVal, destVal reflect.Value, mm *MemberMapOpt
 	}
 
 	// Fallback to standard mapping
-	return m.mapMember(srcVal, destVal, mm.MemberMap)
+	return m.assignMember(ctx, srcVal, destVal, mm.MemberMap)
 }
 
 // mapStructOptimized maps a struct using optimizations based on level.
-func (m *Mapper) mapStructOptimized(srcVal, destVal reflect.Value, typeMap *TypeMapOptimized) error {
+func (m *Mapper) mapStructOptimized(ctx *MappingContext, srcVal, destVal reflect.Value, typeMap *TypeMapOptimized) error {
 	// Always check the original TypeMap for hooks (they may be added after compilation)
 	tm := typeMap.TypeMap
+	hooksEnabled := m.runsHooks(ctx)
+	memoKey := ctx.takeMemoKey()
 
-	// Execute before map functions (requires interface boxing)
-	if len(tm.beforeMap) > 0 {
-		srcIface := srcVal.Interface()
-		destIface := destVal.Addr().Interface()
-		for _, beforeFn := range tm.beforeMap {
-			if err := beforeFn(srcIface, destIface); err != nil {
-				return err
-			}
+	// Execute before map functions on a working copy of the source
+	if hooksEnabled && len(tm.beforeMap) > 0 {
+		srcVal = sourceWorkingCopy(srcVal)
+		if err := m.runHooks(ctx, tm, tm.beforeMap, srcVal.Addr().Interface(), destVal.Addr().Interface()); err != nil {
+			return err
 		}
 	}
 
 	// Use custom mapper if defined
 	if tm.customMapper != nil {
-		return tm.customMapper(srcVal.Interface(), destVal.Addr().Interface())
+		return tm.customMapper(ctx, srcVal.Interface(), destVal.Addr().Interface())
 	}
 
 	// Use specialized mapper if available and no custom logic was added later
@@ -201,29 +273,99 @@ func (m *Mapper) mapStructOptimized(srcVal, destVal reflect.Value, typeMap *Type
 	} else if m.config.useUnsafe {
 		// Map each member with unsafe optimizations
 		for _, mm := range typeMap.optimizedMembers {
-			if err := m.mapMemberUnsafe(srcVal, destVal, mm); err != nil {
+			if err := m.mapMemberUnsafe(ctx, srcVal, destVal, mm); err != nil {
 				return err
 			}
 		}
 	} else {
 		// Standard member mapping
-		for _, mm := range tm.memberMaps {
-			if err := m.mapMember(srcVal, destVal, mm); err != nil {
-				return err
-			}
+		if err := m.executePlan(ctx, srcVal, destVal, m.planFor(tm)); err != nil {
+			return err
 		}
 	}
+	m.wireBackReferences(destVal)
+	ctx.memoizeMapped(memoKey, destVal)
 
 	// Execute after map functions
-	if len(tm.afterMap) > 0 {
-		srcIface := srcVal.Interface()
-		destIface := destVal.Addr().Interface()
-		for _, afterFn := range tm.afterMap {
-			if err := afterFn(srcIface, destIface); err != nil {
-				return err
-			}
+	if hooksEnabled && len(tm.afterMap) > 0 {
+		if err := m.runHooks(ctx, tm, tm.afterMap, srcVal.Interface(), destVal.Addr().Interface()); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
+
+// fastMap maps src directly into the memory at destPtr without boxing the
+// destination into a reflect.Value, which keeps the destination on the
+// caller's stack. It only handles identical primitive types and registered
+// struct pairs whose members are all same-typed primitives, and reports
+// whether the mapping was performed.
+func (m *Mapper) fastMap(src any, destPtr unsafe.Pointer, destType reflect.Type) bool {
+	if src == nil || !m.config.useUnsafe || m.config.verifyOpt {
+		return false
+	}
+
+	srcType := reflect.TypeOf(src)
+	srcPtr := (*[2]unsafe.Pointer)(unsafe.Pointer(&src))[1]
+	if srcType.Kind() == reflect.Ptr {
+		// The interface data word holds the pointer itself
+		srcType = srcType.Elem()
+		if srcPtr == nil || srcType.Kind() == reflect.Ptr || srcType.Kind() == reflect.Interface {
+			return false
+		}
+	}
+
+	key := typeMapKey{srcType: srcType, destType: destType}
+
+	m.config.mu.RLock()
+	defer m.config.mu.RUnlock()
+
+	if _, hasConverter := m.config.converters[key]; hasConverter {
+		return false
+	}
+
+	if srcType == destType && isPrimitiveKind(srcType.Kind()) {
+		unsafeCopyField(srcPtr, destPtr, 0, 0, srcType.Size())
+		return true
+	}
+
+	optMap := m.config.optimizedMaps[key]
+	if srcType.Kind() != reflect.Struct || optMap == nil || optMap.standardOnly || optMap.compileErr != nil ||
+		!optMap.canFastMap() {
+		return false
+	}
+
+	m.markUsed(optMap.TypeMap)
+	for _, mm := range optMap.optimizedMembers {
+		if mm.ignore {
+			continue
+		}
+		unsafeCopyField(srcPtr, destPtr, mm.srcOffset, mm.destOffset, mm.fieldSize)
+	}
+	return true
+}
+
+// canFastMap reports whether every member can be copied with unsafeCopyField.
+// Member options and hooks may be added after compilation, so the live
+// MemberMap configuration is checked on every call.
+func (opt *TypeMapOptimized) canFastMap() bool {
+	tm := opt.TypeMap
+	if tm.customMapper != nil || tm.memberPolicy != nil || len(tm.beforeMap) > 0 || len(tm.afterMap) > 0 ||
+		len(tm.finalizers) > 0 {
+		return false
+	}
+	if len(tm.memberMaps) != len(opt.optimizedMembers) {
+		return false
+	}
+	for _, mm := range opt.optimizedMembers {
+		if mm.ignore {
+			continue
+		}
+		if !mm.directAssign || len(mm.srcFieldIdx) != 1 ||
+			mm.transformsValue() || mm.condition != nil || mm.ptrCond != nil || mm.valueCond != nil {
+			return false
+		}
+	}
+	return true
+}
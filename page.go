@@ -0,0 +1,23 @@
+package automapper
+
+// Page is a page of items from a paginated result set.
+type Page[T any] struct {
+	Items  []T
+	Total  int
+	Offset int
+	Limit  int
+}
+
+// MapPage maps the items of a page, copying its pagination metadata.
+func MapPage[TSrc, TDest any](m *Mapper, page Page[TSrc]) (Page[TDest], error) {
+	items, err := MapSlice[TSrc, TDest](m, page.Items)
+	if err != nil {
+		return Page[TDest]{}, err
+	}
+	return Page[TDest]{
+		Items:  items,
+		Total:  page.Total,
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}, nil
+}
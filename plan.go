@@ -0,0 +1,339 @@
+package automapper
+
+import (
+	"reflect"
+	"sync"
+)
+
+// opCode identifies the operation performed by a plan instruction.
+type opCode uint8
+
+const (
+	// opCopy assigns the source field directly; the types are assignable.
+	opCopy opCode = iota
+	// opConvert converts the source field using reflect's Convert.
+	opConvert
+	// opResolve runs the member's resolver and/or converter.
+	opResolve
+	// opRecurse delegates to assignValue for pointers, nested structs,
+	// collections and registered type converters.
+	opRecurse
+	// opWeak converts between strings, numbers and bools under weak typing.
+	opWeak
+	// opFormat formats a number or time for the call's locale in display
+	// type maps.
+	opFormat
+	// opDuration maps between a time.Duration and a string or integer
+	// milliseconds under WithDurationFormat.
+	opDuration
+)
+
+// instruction is a single member mapping step with its field indices and
+// operation resolved ahead of time.
+type instruction struct {
+	op       opCode
+	member   *MemberMap
+	srcIdx   []int
+	destIdx  []int
+	destType reflect.Type
+}
+
+// typeMapPlan is the flat instruction list compiled from a TypeMap.
+// Plans are tied to the configuration generation they were compiled
+// against and are recompiled once the configuration changes.
+type typeMapPlan struct {
+	generation   uint64
+	instructions []instruction
+	policy       MemberPolicy
+	concurrency  int
+}
+
+// planFor returns the compiled plan for a TypeMap, compiling it if the
+// cached plan is missing or stale.
+func (m *Mapper) planFor(tm *TypeMap) *typeMapPlan {
+	gen := m.config.generation.Load()
+	if p := tm.plan.Load(); p != nil && p.generation == gen {
+		return p
+	}
+
+	p := m.compilePlan(tm, gen)
+	tm.plan.Store(p)
+	return p
+}
+
+// planKey identifies a plan derived from a TypeMap for one per-call option
+// signature.
+type planKey struct {
+	typeMapKey
+	signature string
+}
+
+// derivedPlanIndex caches the plans derived for per-call option signatures
+// at one configuration generation, so plans of earlier generations are
+// dropped together with their index.
+type derivedPlanIndex struct {
+	generation uint64
+	plans      sync.Map // map[planKey]*typeMapPlan
+}
+
+// derivedPlansAt returns the derived plan index of generation gen.
+func (c *MapperConfiguration) derivedPlansAt(gen uint64) *derivedPlanIndex {
+	index := c.derivedPlans.Load()
+	if index == nil || index.generation != gen {
+		index = &derivedPlanIndex{generation: gen}
+		c.derivedPlans.Store(index)
+	}
+	return index
+}
+
+// planForContext returns the plan to execute for a TypeMap within ctx.
+// Calls with per-call ignores share a derived plan cached by option
+// signature, so member filtering only happens once per signature.
+func (m *Mapper) planForContext(ctx *MappingContext, tm *TypeMap) *typeMapPlan {
+	base := m.planFor(tm)
+
+	key := typeMapKey{srcType: tm.srcType, destType: tm.destType}
+	if !ctx.overridesPlan(key) {
+		return base
+	}
+
+	index := m.config.derivedPlansAt(base.generation)
+	pk := planKey{typeMapKey: key, signature: ctx.signature}
+	if cached, ok := index.plans.Load(pk); ok {
+		return cached.(*typeMapPlan)
+	}
+
+	p := &typeMapPlan{
+		generation:   base.generation,
+		instructions: make([]instruction, 0, len(base.instructions)),
+		policy:       base.policy,
+		concurrency:  base.concurrency,
+	}
+	for _, ins := range base.instructions {
+		if !ctx.ignored[ins.member.destField] {
+			p.instructions = append(p.instructions, ins)
+		}
+	}
+	index.plans.Store(pk, p)
+	return p
+}
+
+// compilePlan builds the instruction list for a TypeMap.
+func (m *Mapper) compilePlan(tm *TypeMap, gen uint64) *typeMapPlan {
+	p := &typeMapPlan{
+		generation:   gen,
+		instructions: make([]instruction, 0, len(tm.memberMaps)),
+		policy:       tm.memberPolicy,
+		concurrency:  tm.concurrency,
+	}
+
+	srcInfo := m.config.typeCache.getTypeInfo(tm.srcType)
+
+	m.config.mu.RLock()
+	defer m.config.mu.RUnlock()
+
+	for _, mm := range tm.memberMaps {
+		if mm.ignore {
+			continue
+		}
+
+		ins := instruction{
+			member:   tm.nilCollectionMember(mm),
+			destIdx:  mm.destFieldIdx,
+			destType: tm.destType.FieldByIndex(mm.destFieldIdx).Type,
+		}
+
+		if mm.transformsValue() {
+			ins.op = opResolve
+			p.instructions = append(p.instructions, ins)
+			continue
+		}
+
+		ins.srcIdx = mm.srcFieldIdx
+		if len(ins.srcIdx) == 0 {
+			fi, ok := srcInfo.member(mm.srcField)
+			if !ok {
+				continue
+			}
+			ins.srcIdx = fi.index
+		}
+
+		srcType := fieldTypeByIndex(tm.srcType, ins.srcIdx)
+		ins.op = m.selectOp(srcType, ins.destType)
+		if (m.config.weakTypes || tm.weakTypes) && srcType != nil && weakConvertible(srcType, ins.destType) {
+			ins.op = opWeak
+		}
+		if tm.display && srcType != nil && ins.destType.Kind() == reflect.String && displayFormattable(srcType) {
+			ins.op = opFormat
+		}
+		if tm.durations != DurationNative && srcType != nil && isDurationPair(tm.durations, srcType, ins.destType) {
+			ins.op = opDuration
+		}
+		p.instructions = append(p.instructions, ins)
+	}
+
+	return p
+}
+
+// selectOp picks the cheapest operation able to assign srcType to destType
+// with the same semantics as assignValue. The caller must hold config.mu.
+func (m *Mapper) selectOp(srcType, destType reflect.Type) opCode {
+	if srcType == nil || srcType.Kind() == reflect.Ptr || srcType.Kind() == reflect.Interface ||
+		destType.Kind() == reflect.Ptr {
+		return opRecurse
+	}
+	// Structs assigned to interfaces may dispatch to a registered type map,
+	// and other values may need converting or copying
+	if destType.Kind() == reflect.Interface &&
+		(srcType.Kind() == reflect.Struct || m.config.ifacePolicy != InterfaceAssign || m.config.jsonSafe) {
+		return opRecurse
+	}
+	if m.mapsInterfaceElements(destType) {
+		return opRecurse
+	}
+	key := typeMapKey{srcType: srcType, destType: destType}
+	if _, ok := m.config.converters[key]; ok {
+		return opRecurse
+	}
+	if _, ok := m.config.typeMaps[key]; ok && srcType.Kind() == reflect.Struct {
+		return opRecurse
+	}
+	if srcType.AssignableTo(destType) {
+		return opCopy
+	}
+	// net.IP converts to string as raw bytes; assignValue formats it
+	if isNetworkType(srcType) || isNetworkType(destType) {
+		return opRecurse
+	}
+	if m.convertible(srcType, destType) {
+		return opConvert
+	}
+	return opRecurse
+}
+
+// executePlan runs every instruction of a plan against a struct pair.
+func (m *Mapper) executePlan(ctx *MappingContext, srcVal, destVal reflect.Value, p *typeMapPlan) error {
+	mask := ctx.maskNode()
+	if mask != nil {
+		// Nested members see the mask of the member being mapped
+		defer func() { ctx.mask = mask }()
+	}
+
+	resolved, err := m.resolveConcurrently(ctx, srcVal, destVal, p, mask)
+	if err != nil {
+		return err
+	}
+
+	for i := range p.instructions {
+		ins := &p.instructions[i]
+		mm := ins.member
+
+		if mask != nil {
+			child, selected := mask.children[mm.destField]
+			if !selected {
+				continue
+			}
+			ctx.mask = child
+		}
+
+		if p.policy != nil && !p.policy(ctx, mm.destField) {
+			continue
+		}
+
+		if !mm.passesCondition(srcVal) {
+			continue
+		}
+
+		destField := destVal.FieldByIndex(ins.destIdx)
+		if !destField.CanSet() {
+			if m.config.unsettable {
+				return unsettableError(srcVal.Type(), destVal.Type(), mm.destField)
+			}
+			continue
+		}
+
+		start := ctx.reportLen()
+		if ins.op == opResolve {
+			if r, ok := resolved[mm]; ok {
+				if r.skipped {
+					continue
+				}
+				mm = r.member(mm)
+			}
+			m.countUsage(srcVal.Type(), destVal.Type(), mm)
+			if err := m.resolveMember(ctx, srcVal, destVal, destField, mm); err != nil {
+				return err
+			}
+			m.internString(ctx, destField)
+			m.recordAssignment(ctx, start, ins, destField)
+			continue
+		}
+
+		srcField := getNestedField(srcVal, ins.srcIdx)
+		if !srcField.IsValid() || (mm.valueCond != nil && !mm.valueCond(srcField, destField)) ||
+			(mm.zeroTimeNil && isZeroTime(srcField)) {
+			continue
+		}
+		m.countUsage(srcVal.Type(), destVal.Type(), mm)
+		if assignEmptyMap(mm.emptyMap, srcField, destField) || assignNilCollection(mm.nilColl, srcField, destField) {
+			m.recordAssignment(ctx, start, ins, destField)
+			continue
+		}
+
+		switch ins.op {
+		case opCopy:
+			destField.Set(srcField)
+		case opConvert:
+			destField.Set(srcField.Convert(ins.destType))
+		case opWeak:
+			if err := weakAssign(srcField, destField); err != nil {
+				return &MappingError{
+					Message:    "weak type conversion failed",
+					Code:       CodeConversion,
+					SrcType:    srcVal.Type(),
+					DestType:   destVal.Type(),
+					FieldName:  mm.destField,
+					Path:       mm.destField,
+					InnerError: err,
+				}
+			}
+		case opFormat:
+			destField.SetString(formatDisplay(ctx, srcField))
+		case opDuration:
+			if err := assignDuration(srcField, destField); err != nil {
+				return &MappingError{
+					Message:    "duration conversion failed",
+					Code:       CodeConversion,
+					SrcType:    srcVal.Type(),
+					DestType:   destVal.Type(),
+					FieldName:  mm.destField,
+					Path:       mm.destField,
+					InnerError: err,
+				}
+			}
+		default:
+			if err := m.assignValue(ctx, srcField, destField); err != nil {
+				prependErrorPath(err, mm.destField)
+				return err
+			}
+		}
+		m.internString(ctx, destField)
+		m.recordAssignment(ctx, start, ins, destField)
+	}
+	return nil
+}
+
+// fieldTypeByIndex returns the type of a (possibly nested) field, following
+// pointers along the path the same way getNestedField does.
+func fieldTypeByIndex(t reflect.Type, indices []int) reflect.Type {
+	for _, idx := range indices {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || idx >= t.NumField() {
+			return nil
+		}
+		t = t.Field(idx).Type
+	}
+	return t
+}
@@ -0,0 +1,195 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ProjectFields returns the source field paths required to populate the
+// requested destination members of TDest, using the registered mapping into
+// TDest. Paths use dot notation ("Customer.Name"); nested destination
+// members may be requested the same way ("Address.City"). With no fields,
+// every mapped member is projected. Members computed by resolvers contribute
+// the fields declared with MapFromFields, or nothing.
+func ProjectFields[TDest any](m *Mapper, fields ...string) ([]string, error) {
+	destType := reflect.TypeOf((*TDest)(nil)).Elem()
+	if destType.Kind() == reflect.Ptr {
+		destType = destType.Elem()
+	}
+
+	tm, err := m.typeMapForDest(destType)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool)
+	if len(fields) == 0 {
+		m.projectAll(tm, "", set, map[typeMapKey]bool{})
+	}
+	for _, field := range fields {
+		if err := m.projectPath(tm, strings.Split(field, "."), "", set); err != nil {
+			return nil, err
+		}
+	}
+
+	paths := make([]string, 0, len(set))
+	for path := range set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// typeMapForDest returns the single registered type map producing destType.
+func (m *Mapper) typeMapForDest(destType reflect.Type) (*TypeMap, error) {
+	m.config.mu.RLock()
+	defer m.config.mu.RUnlock()
+
+	var found *TypeMap
+	for key, tm := range m.config.typeMaps {
+		if key.destType != destType {
+			continue
+		}
+		if found != nil {
+			return nil, &MappingError{
+				Message:  fmt.Sprintf("multiple source types map to %v", destType),
+				Code:     CodeNoTypeMap,
+				DestType: destType,
+			}
+		}
+		found = tm
+	}
+	if found == nil {
+		return nil, &MappingError{
+			Message:  "no type map registered for destination",
+			Code:     CodeNoTypeMap,
+			DestType: destType,
+		}
+	}
+	return found, nil
+}
+
+// lookupTypeMap returns the registered type map for a pair, or an
+// unregistered auto-configured one if none exists.
+func (m *Mapper) lookupTypeMap(srcType, destType reflect.Type) *TypeMap {
+	m.config.mu.RLock()
+	tm, ok := m.config.typeMaps[typeMapKey{srcType: srcType, destType: destType}]
+	m.config.mu.RUnlock()
+	if ok {
+		return tm
+	}
+
+	tm = &TypeMap{
+		srcType:      srcType,
+		destType:     destType,
+		memberMaps:   make([]*MemberMap, 0),
+		ignoreFields: make(map[string]bool),
+	}
+	m.config.applyConventions(tm)
+	return tm
+}
+
+// memberSourcePath returns the dotted source path feeding a member.
+func (m *Mapper) memberSourcePath(mm *MemberMap) string {
+	if mm.useFlattening {
+		return strings.Join(mm.flattenPath, ".")
+	}
+	return mm.srcField
+}
+
+// nestedTypeMap returns the type map used for a member whose source and
+// destination are (collections of) distinct structs, or nil.
+func (m *Mapper) nestedTypeMap(tm *TypeMap, mm *MemberMap) *TypeMap {
+	srcFieldType := m.memberSourceType(tm, mm)
+	if srcFieldType == nil {
+		return nil
+	}
+	srcElem := elementType(srcFieldType)
+	destElem := elementType(tm.destType.FieldByIndex(mm.destFieldIdx).Type)
+	if srcElem.Kind() != reflect.Struct || destElem.Kind() != reflect.Struct || srcElem == destElem {
+		return nil
+	}
+	return m.lookupTypeMap(srcElem, destElem)
+}
+
+// projectAll adds the source paths of every mapped member of tm.
+func (m *Mapper) projectAll(tm *TypeMap, prefix string, set map[string]bool, visiting map[typeMapKey]bool) {
+	key := typeMapKey{srcType: tm.srcType, destType: tm.destType}
+	if visiting[key] {
+		return
+	}
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	for _, mm := range tm.memberMaps {
+		if mm.ignore {
+			continue
+		}
+		if mm.resolver != nil || mm.batch != nil {
+			projectSourceFields(mm, prefix, set)
+			continue
+		}
+		path := m.memberSourcePath(mm)
+		if path == "" {
+			continue
+		}
+		if nested := m.nestedTypeMap(tm, mm); nested != nil {
+			m.projectAll(nested, prefix+path+".", set, visiting)
+			continue
+		}
+		set[prefix+path] = true
+	}
+}
+
+// projectPath adds the source paths required by one destination path.
+func (m *Mapper) projectPath(tm *TypeMap, destPath []string, prefix string, set map[string]bool) error {
+	var mm *MemberMap
+	for _, candidate := range tm.memberMaps {
+		if candidate.destField == destPath[0] {
+			mm = candidate
+			break
+		}
+	}
+	if mm == nil {
+		return &MappingError{
+			Message:   "unknown destination member",
+			Code:      CodeInvalidArgument,
+			SrcType:   tm.srcType,
+			DestType:  tm.destType,
+			FieldName: destPath[0],
+		}
+	}
+	if mm.ignore {
+		return nil
+	}
+	if mm.resolver != nil || mm.batch != nil {
+		projectSourceFields(mm, prefix, set)
+		return nil
+	}
+
+	path := m.memberSourcePath(mm)
+	if path == "" {
+		return nil
+	}
+
+	nested := m.nestedTypeMap(tm, mm)
+	switch {
+	case nested == nil && len(destPath) > 1:
+		return &MappingError{
+			Message:   "destination member has no nested members",
+			Code:      CodeInvalidArgument,
+			SrcType:   tm.srcType,
+			DestType:  tm.destType,
+			FieldName: destPath[0],
+		}
+	case nested == nil:
+		set[prefix+path] = true
+	case len(destPath) == 1:
+		m.projectAll(nested, prefix+path+".", set, map[typeMapKey]bool{})
+	default:
+		return m.projectPath(nested, destPath[1:], prefix+path+".", set)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// defaultMaxDepth bounds how deeply structs of recursive types nest before
+// mapping fails, unless WithMaxDepth sets another limit.
+const defaultMaxDepth = 10000
+
+// WithMaxDepth sets how deeply structs of recursive types, such as linked
+// list or tree nodes, may nest before mapping fails with an error instead
+// of overflowing the stack. The default is 10000.
+func WithMaxDepth(depth int) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.maxDepth = depth
+	}
+}
+
+// markRecursive flags a type map whose source type can contain itself, so
+// mappings track their depth and stop at the configured limit.
+func (c *MapperConfiguration) markRecursive(tm *TypeMap) {
+	tm.recursive = isRecursiveType(tm.srcType)
+	if tm.recursive {
+		c.hasRecursive.Store(true)
+	}
+}
+
+// checkDepth fails the mapping of a recursive type map nested deeper than
+// the configured limit, which happens for cyclic source graphs.
+func (m *Mapper) checkDepth(ctx *MappingContext, tm *TypeMap) error {
+	limit := m.config.maxDepth
+	if limit <= 0 {
+		limit = defaultMaxDepth
+	}
+	if !tm.recursive || ctx == nil || ctx.depth <= limit {
+		return nil
+	}
+	return &MappingError{
+		Message: fmt.Sprintf("recursive type nested deeper than %d levels; "+
+			"the source may contain a cycle, which WithPreserveReferences maps", limit),
+		Code:     CodeRecursion,
+		SrcType:  tm.srcType,
+		DestType: tm.destType,
+	}
+}
+
+// isRecursiveType reports whether struct type t can contain a value of its
+// own type through pointers, collections or interfaces of nested members.
+func isRecursiveType(t reflect.Type) bool {
+	return reachesType(t, t, map[reflect.Type]bool{})
+}
+
+// reachesType reports whether target is reachable from the members of t.
+func reachesType(t, target reflect.Type, visited map[reflect.Type]bool) bool {
+	if t.Kind() != reflect.Struct || visited[t] {
+		return false
+	}
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := elementType(t.Field(i).Type)
+		if field == target || reachesType(field, target, visited) {
+			return true
+		}
+	}
+	return false
+}
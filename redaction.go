@@ -0,0 +1,140 @@
+package automapper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// Redactor replaces a sensitive source value before it is assigned to the
+// destination. Returning nil leaves the destination member at its zero value.
+type Redactor func(value any) any
+
+// redactionMask is the replacement MaskValue uses for string values.
+const redactionMask = "***"
+
+// MaskValue is the default Redactor: non-empty strings become "***" and all
+// other values are left at their zero value.
+func MaskValue(value any) any {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.String {
+		return nil
+	}
+	if v.Len() == 0 {
+		return ""
+	}
+	return redactionMask
+}
+
+// HashValue is a Redactor replacing a value with the hex-encoded SHA-256 of
+// its string form, so redacted values can still be correlated. Nil and zero
+// values stay empty.
+func HashValue(value any) any {
+	if value == nil || reflect.ValueOf(value).IsZero() {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprint(value)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Redact configures a destination member to receive a masked value instead
+// of its source value.
+func Redact() MemberOption {
+	return RedactWith(MaskValue)
+}
+
+// RedactWith configures a destination member to receive the value produced
+// by redactor instead of its source value.
+func RedactWith(redactor Redactor) MemberOption {
+	return func(mm *MemberMap) {
+		mm.redactor = redactor
+		mm.redactSet = true
+	}
+}
+
+// WithRedactionTag redacts every member whose source or destination field
+// carries the given struct tag, e.g. WithRedactionTag("pii") with
+// `pii:"true"`. A tag value of "hash" selects HashValue; any other value
+// masks the member with MaskValue. Source tags apply to every member reading
+// the field, including through MapFrom. RedactWith overrides the tag.
+func WithRedactionTag(tag string) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.redactionTag = tag
+	}
+}
+
+// applyRedactionTag sets redactors on the members of tm whose destination
+// field or current source field carries the configured redaction tag. It
+// runs again after every change to tm, so members remapped with MapFrom
+// follow their new source; redactors set with RedactWith are kept.
+func (c *MapperConfiguration) applyRedactionTag(tm *TypeMap) {
+	if c.redactionTag == "" {
+		return
+	}
+	for _, mm := range tm.memberMaps {
+		if mm.redactSet {
+			continue
+		}
+		value, tagged := tm.destType.FieldByIndex(mm.destFieldIdx).Tag.Lookup(c.redactionTag)
+		if !tagged {
+			value, tagged = c.sourceRedactionTag(tm, mm)
+		}
+		switch {
+		case !tagged:
+			mm.redactor = nil
+		case value == "hash":
+			mm.redactor = HashValue
+		default:
+			mm.redactor = MaskValue
+		}
+	}
+}
+
+// sourceRedactionTag looks up the redaction tag on the source fields a
+// member reads: its conventional or MapFrom field, or its source paths.
+func (c *MapperConfiguration) sourceRedactionTag(tm *TypeMap, mm *MemberMap) (string, bool) {
+	switch {
+	case len(mm.srcFieldIdx) > 0:
+		return sourceStructField(tm.srcType, mm.srcFieldIdx).Tag.Lookup(c.redactionTag)
+	case mm.srcField != "":
+		if field, ok := derefType(tm.srcType).FieldByName(mm.srcField); ok {
+			return field.Tag.Lookup(c.redactionTag)
+		}
+	}
+	for _, path := range mm.srcFields {
+		if field, _ := sourcePathField(tm.srcType, path); field.Tag != "" {
+			if value, tagged := field.Tag.Lookup(c.redactionTag); tagged {
+				return value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// sourceStructField returns the source field at index, following pointers
+// to nested structs along the way.
+func sourceStructField(t reflect.Type, index []int) reflect.StructField {
+	var field reflect.StructField
+	for _, i := range index {
+		t = derefType(t)
+		field = t.Field(i)
+		t = field.Type
+	}
+	return field
+}
+
+// redactValue applies the member's redactor to a resolved source value.
+// Results that cannot be assigned to the destination, such as hashes of
+// numbers, leave it at its zero value.
+func redactValue(mm *MemberMap, srcValue reflect.Value, destType reflect.Type) reflect.Value {
+	result := mm.redactor(srcValue.Interface())
+	if result == nil {
+		return reflect.Zero(destType)
+	}
+	v := reflect.ValueOf(result)
+	if target := derefType(destType); !v.Type().AssignableTo(target) && !v.Type().ConvertibleTo(target) {
+		return reflect.Zero(destType)
+	}
+	return v
+}
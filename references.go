@@ -0,0 +1,47 @@
+package automapper
+
+import "reflect"
+
+// WithPreserveReferences preserves the shape of object graphs for this
+// call: a source pointer reached more than once is mapped once and every
+// occurrence receives the same destination pointer. Because a pointer is
+// registered before its target is mapped, cyclic graphs map to cyclic
+// destinations instead of recursing forever.
+func WithPreserveReferences() MapOption {
+	return func(c *MappingContext) {
+		c.refs = make(map[refKey]reflect.Value)
+	}
+}
+
+// refKey identifies the destination produced for one source pointer.
+type refKey struct {
+	ptr      uintptr
+	srcType  reflect.Type
+	destType reflect.Type
+}
+
+// mapSharedReference maps a non-nil source pointer into the pointer
+// destination destVal, reusing the destination already produced for the
+// same source pointer in this call. It reports false, without mapping, if
+// the context does not preserve references or the values are not pointers.
+func (m *Mapper) mapSharedReference(ctx *MappingContext, srcVal, destVal reflect.Value) (bool, error) {
+	if ctx == nil || ctx.refs == nil || srcVal.Kind() != reflect.Ptr || srcVal.IsNil() ||
+		destVal.Kind() != reflect.Ptr {
+		return false, nil
+	}
+
+	key := refKey{ptr: srcVal.Pointer(), srcType: srcVal.Type(), destType: destVal.Type()}
+	if ref, ok := ctx.refs[key]; ok {
+		destVal.Set(ref)
+		return true, nil
+	}
+
+	target := reflect.New(destVal.Type().Elem())
+	if !destVal.IsNil() {
+		// Map into the existing destination, as MapTo does
+		target = reflect.ValueOf(destVal.Interface())
+	}
+	destVal.Set(target)
+	ctx.refs[key] = target
+	return true, m.mapValue(ctx, srcVal.Elem(), target.Elem())
+}
@@ -0,0 +1,24 @@
+package automapper
+
+// MapResult maps the value of a (value, error) pair, passing a non-nil
+// error through unchanged, so service results need no error check before
+// mapping:
+//
+//	user, err := repo.Find(id)
+//	return automapper.MapResult[User, UserDTO](mapper, user, err)
+func MapResult[TSrc, TDest any](m *Mapper, v TSrc, err error) (TDest, error) {
+	if err != nil {
+		var zero TDest
+		return zero, err
+	}
+	return Map[TDest](m, v)
+}
+
+// MapSliceResult maps the slice of a (slice, error) pair, passing a non-nil
+// error through unchanged.
+func MapSliceResult[TSrc, TDest any](m *Mapper, v []TSrc, err error) ([]TDest, error) {
+	if err != nil {
+		return nil, err
+	}
+	return MapSlice[TSrc, TDest](m, v)
+}
@@ -0,0 +1,48 @@
+package automapper
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithRetry retries a member's failing resolver up to n more times, waiting
+// backoff before the first retry and doubling the wait after each one. The
+// waits stop early once the context.Context set with WithContext is done,
+// returning the last resolver error together with the context's error.
+func WithRetry(n int, backoff time.Duration) MemberOption {
+	return func(mm *MemberMap) {
+		mm.retries = n
+		mm.backoff = backoff
+	}
+}
+
+// callResolver runs the member's resolver, retrying it as configured with
+// WithRetry, or looks up the value of its batch resolver.
+func callResolver(ctx *MappingContext, mm *MemberMap, src, dest any) (any, error) {
+	if mm.batch != nil {
+		return resolveBatched(ctx, mm, src, dest)
+	}
+
+	result, err := mm.resolver(src, dest)
+	if err == nil || mm.retries <= 0 {
+		return result, err
+	}
+
+	done := ctx.Context().Done()
+	wait := mm.backoff
+	for attempt := 0; attempt < mm.retries; attempt++ {
+		timer := time.NewTimer(wait)
+		select {
+		case <-done:
+			timer.Stop()
+			return nil, fmt.Errorf("%w (retries stopped: %w)", err, ctx.Context().Err())
+		case <-timer.C:
+		}
+		wait *= 2
+
+		if result, err = mm.resolver(src, dest); err == nil {
+			return result, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,136 @@
+package automapper
+
+import (
+	"reflect"
+	"sort"
+)
+
+// destinationSelector picks the destination type for a source value.
+type destinationSelector func(src reflect.Value) reflect.Type
+
+// discriminatorKey identifies the destination registered for string-keyed
+// maps whose key entry holds value.
+type discriminatorKey struct {
+	key   string
+	value string
+}
+
+// SelectDestination registers a selector choosing the destination type for
+// TSrc values from their content, typically a discriminator such as an event
+// type or document kind. MapDynamic uses it to map TSrc:
+//
+//	automapper.SelectDestination(mapper, func(d Document) reflect.Type {
+//		if d.Kind == "invoice" {
+//			return reflect.TypeOf(InvoiceDTO{})
+//		}
+//		return reflect.TypeOf(ReceiptDTO{})
+//	})
+//
+// Like ConvertUsing, it returns an error once a type map from TSrc is
+// sealed.
+func SelectDestination[TSrc any](m *Mapper, selector func(src TSrc) reflect.Type) error {
+	srcType := reflect.TypeOf((*TSrc)(nil)).Elem()
+
+	m.config.mu.Lock()
+	defer m.config.mu.Unlock()
+	if m.config.rejectSealed(nil, typeMapKey{srcType: srcType}) {
+		return sealedError(typeMapKey{srcType: srcType})
+	}
+	m.config.checkLateConfiguration(nil, typeMapKey{srcType: srcType})
+	m.config.selectors[srcType] = func(src reflect.Value) reflect.Type {
+		return selector(src.Interface().(TSrc))
+	}
+	return nil
+}
+
+// MapDynamic maps src to a new value of the destination type chosen by the
+// selector registered for its type with SelectDestination.
+func MapDynamic(m *Mapper, src any) (any, error) {
+	srcVal := reflect.ValueOf(src)
+	for srcVal.Kind() == reflect.Ptr && !srcVal.IsNil() {
+		srcVal = srcVal.Elem()
+	}
+	if !srcVal.IsValid() || srcVal.Kind() == reflect.Ptr {
+		return nil, &MappingError{Message: "cannot select a destination for a nil source", Code: CodeNilSource}
+	}
+
+	m.config.mu.RLock()
+	selector, ok := m.config.selectors[srcVal.Type()]
+	m.config.mu.RUnlock()
+	if !ok && isStringKeyedMap(srcVal.Type()) {
+		selector, ok = m.discriminatedTarget, true
+	}
+	if !ok {
+		return nil, &MappingError{
+			Message: "no destination selector registered",
+			Code:    CodeNoTypeMap,
+			SrcType: srcVal.Type(),
+		}
+	}
+
+	destType := selector(srcVal)
+	if destType == nil {
+		return nil, &MappingError{
+			Message: "no destination selected for source",
+			Code:    CodeNoTypeMap,
+			SrcType: srcVal.Type(),
+		}
+	}
+
+	dest := reflect.New(destType).Elem()
+	if err := m.mapValue(nil, srcVal, dest); err != nil {
+		return nil, err
+	}
+	return dest.Interface(), nil
+}
+
+// RegisterDiscriminator registers TDest as the destination for string-keyed
+// maps, such as decoded webhook payloads, whose key entry equals value:
+//
+//	automapper.RegisterDiscriminator[CreditCardDTO](mapper, "type", "credit_card")
+//
+// MapDynamic then maps such maps into TDest, as does mapping them into an
+// interface member that TDest, or a pointer to it, implements. Like
+// ConvertUsing, it returns an error once a type map to TDest is sealed.
+func RegisterDiscriminator[TDest any](m *Mapper, key, value string) error {
+	destType := reflect.TypeOf((*TDest)(nil)).Elem()
+
+	m.config.mu.Lock()
+	defer m.config.mu.Unlock()
+	if m.config.rejectSealed(nil, typeMapKey{destType: destType}) {
+		return sealedError(typeMapKey{destType: destType})
+	}
+	m.config.checkLateConfiguration(nil, typeMapKey{destType: destType})
+	m.config.variants[discriminatorKey{key: key, value: value}] = destType
+	return nil
+}
+
+// discriminatedTarget returns the destination registered with
+// RegisterDiscriminator for a string-keyed map, or nil if none matches.
+// Discriminator keys are tried in sorted order.
+func (m *Mapper) discriminatedTarget(srcVal reflect.Value) reflect.Type {
+	m.config.mu.RLock()
+	defer m.config.mu.RUnlock()
+
+	keys := make([]string, 0, len(m.config.variants))
+	seen := make(map[string]bool, len(m.config.variants))
+	for d := range m.config.variants {
+		if !seen[d.key] {
+			seen[d.key] = true
+			keys = append(keys, d.key)
+		}
+	}
+	sort.Strings(keys)
+
+	keyType := srcVal.Type().Key()
+	for _, key := range keys {
+		entry := derefValue(srcVal.MapIndex(reflect.ValueOf(key).Convert(keyType)))
+		if !entry.IsValid() || entry.Kind() != reflect.String {
+			continue
+		}
+		if destType, ok := m.config.variants[discriminatorKey{key: key, value: entry.String()}]; ok {
+			return destType
+		}
+	}
+	return nil
+}
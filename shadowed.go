@@ -0,0 +1,69 @@
+package automapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// memberSource records a member option that set where a member's value
+// comes from, so ValidateConfiguration can report options shadowed by
+// others.
+type memberSource struct {
+	option string
+	// resolver is set for options producing the value with a resolver,
+	// which take precedence over source fields
+	resolver bool
+}
+
+// addSource records a source option applied to mm. Member maps are copied
+// by value when type maps are cloned, so the slice is never appended to in
+// place.
+func (mm *MemberMap) addSource(option string, resolver bool) {
+	sources := make([]memberSource, len(mm.sources), len(mm.sources)+1)
+	copy(sources, mm.sources)
+	mm.sources = append(sources, memberSource{option: option, resolver: resolver})
+}
+
+// shadowedSources describes the source options of mm that have no effect
+// because another option takes precedence: Ignore over everything, the
+// last resolver over other resolvers and source fields, and the last
+// source field over earlier ones.
+func (mm *MemberMap) shadowedSources() []string {
+	if len(mm.sources) == 0 {
+		return nil
+	}
+
+	if mm.ignore {
+		options := make([]string, len(mm.sources))
+		for i, src := range mm.sources {
+			options[i] = src.option
+		}
+		return []string{fmt.Sprintf("Ignore takes precedence over %s; the member is not mapped",
+			strings.Join(options, ", "))}
+	}
+
+	effective := -1
+	for i, src := range mm.sources {
+		if src.resolver || effective < 0 || !mm.sources[effective].resolver {
+			effective = i
+		}
+	}
+	winner := mm.sources[effective]
+
+	var shadowed []string
+	for i, src := range mm.sources {
+		switch {
+		case i == effective:
+		case winner.resolver && !src.resolver:
+			shadowed = append(shadowed, fmt.Sprintf("%s is shadowed by %s; resolvers take precedence over source fields",
+				src.option, winner.option))
+		case winner.resolver:
+			shadowed = append(shadowed, fmt.Sprintf("%s is overridden by %s; the last resolver applies",
+				src.option, winner.option))
+		default:
+			shadowed = append(shadowed, fmt.Sprintf("%s is overridden by %s; the last source field applies",
+				src.option, winner.option))
+		}
+	}
+	return shadowed
+}
@@ -0,0 +1,69 @@
+package automapper
+
+import "reflect"
+
+// Snapshot is a saved copy of a mapper's registered type maps, type
+// converters, versioned maps and destination selectors, created by Mapper.Snapshot.
+type Snapshot struct {
+	mapper        *Mapper
+	typeMaps      map[typeMapKey]*TypeMap
+	converters    map[typeMapKey]TypeConverter
+	versions      map[versionKey]reflect.Type
+	selectors     map[reflect.Type]destinationSelector
+	variants      map[discriminatorKey]reflect.Type
+	jsonConv      map[reflect.Type]jsonConverter
+	optimizedMaps map[typeMapKey]*TypeMapOptimized
+}
+
+// Snapshot saves the mapper's current type maps and converters so they can
+// be reinstated with Restore, letting tests add configuration to a shared
+// mapper temporarily. Type maps are copy-on-write, so taking a snapshot only
+// copies the registries.
+func (m *Mapper) Snapshot() *Snapshot {
+	m.config.mu.RLock()
+	defer m.config.mu.RUnlock()
+
+	return &Snapshot{
+		mapper:        m,
+		typeMaps:      copyRegistry(m.config.typeMaps),
+		converters:    copyRegistry(m.config.converters),
+		versions:      copyRegistry(m.config.versions),
+		selectors:     copyRegistry(m.config.selectors),
+		variants:      copyRegistry(m.config.variants),
+		jsonConv:      copyRegistry(m.config.jsonConv),
+		optimizedMaps: copyRegistry(m.config.optimizedMaps),
+	}
+}
+
+// Restore reinstates the type maps and converters saved by Snapshot,
+// discarding any configured since. Sealing a map that existed at snapshot
+// time is not undone. Restore panics if snap was taken from another mapper,
+// a programming error that restoring could only hide.
+func (m *Mapper) Restore(snap *Snapshot) {
+	if snap.mapper != m {
+		panic(&MappingError{Message: "snapshot restored into a different mapper", Code: CodeInvalidArgument})
+	}
+
+	m.config.mu.Lock()
+	defer m.config.mu.Unlock()
+
+	// The snapshot stays reusable, so the registries are copied again
+	m.config.typeMaps = copyRegistry(snap.typeMaps)
+	m.config.indexTypeMaps()
+	m.config.converters = copyRegistry(snap.converters)
+	m.config.versions = copyRegistry(snap.versions)
+	m.config.selectors = copyRegistry(snap.selectors)
+	m.config.variants = copyRegistry(snap.variants)
+	m.config.jsonConv = copyRegistry(snap.jsonConv)
+	m.config.optimizedMaps = copyRegistry(snap.optimizedMaps)
+	m.config.invalidatePlans()
+}
+
+// copyRegistry returns a shallow copy of a configuration registry.
+func copyRegistry[K comparable, V any](src map[K]V) map[K]V {
+	dst := make(map[K]V, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
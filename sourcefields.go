@@ -0,0 +1,178 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MapFromFields computes a destination member from several source fields.
+// The values of fields, given as dotted paths such as "Address.City", are
+// passed to fn in order; a path through a nil pointer yields nil. Declaring
+// the fields lets ValidateConfiguration report ones that do not exist and
+// ProjectFields include them.
+//
+//	ForMemberByName("FullName", automapper.MapFromFields([]string{"FirstName", "LastName"},
+//	    func(vals ...any) (any, error) {
+//	        return vals[0].(string) + " " + vals[1].(string), nil
+//	    }))
+func MapFromFields(fields []string, fn func(vals ...any) (any, error)) MemberOption {
+	return mapFromFields("MapFromFields", fields, fn)
+}
+
+// mapFromFields implements MapFromFields for the member option named option.
+func mapFromFields(option string, fields []string, fn func(vals ...any) (any, error)) MemberOption {
+	fields = append([]string(nil), fields...)
+	return func(mm *MemberMap) {
+		mm.srcFields = fields
+		mm.resolver = func(src, _ any) (any, error) {
+			vals := make([]any, len(fields))
+			for i, field := range fields {
+				vals[i] = sourceFieldValue(reflect.ValueOf(src), field)
+			}
+			return fn(vals...)
+		}
+		mm.batch = nil
+		mm.addSource(option, true)
+	}
+}
+
+// mapFromPath maps a member from a source path, declaring the path as its
+// source field.
+func mapFromPath(path string) MemberOption {
+	return func(mm *MemberMap) {
+		mm.srcFields = []string{path}
+		mm.resolver = func(src, _ any) (any, error) {
+			return sourceFieldValue(reflect.ValueOf(src), path), nil
+		}
+		mm.batch = nil
+		mm.addSource(fmt.Sprintf("MapFrom(%q)", path), true)
+	}
+}
+
+// First maps a member from a field of the first element of a source
+// collection, or from the element itself when field is empty. It is
+// shorthand for MapFrom("Collection[0].Field").
+func First(collection, field string) MemberOption {
+	path := collection + "[0]"
+	if field != "" {
+		path += "." + field
+	}
+	return MapFrom(path)
+}
+
+// sourceFieldValue returns the value at a source path of v, such as
+// "Address.City" or "Addresses[0].City", or nil if the path is missing,
+// indexes past the end of a collection or crosses a nil pointer.
+func sourceFieldValue(v reflect.Value, path string) any {
+	for _, segment := range strings.Split(path, ".") {
+		name, indices, ok := parsePathSegment(segment)
+		if !ok {
+			return nil
+		}
+		v = derefValue(v)
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+		v = v.FieldByName(name)
+		for _, i := range indices {
+			v = derefValue(v)
+			if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || i >= v.Len() {
+				return nil
+			}
+			v = v.Index(i)
+		}
+		if !v.IsValid() {
+			return nil
+		}
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// sourceFieldType returns the type at a source path of t, or nil if the
+// path does not exist.
+func sourceFieldType(t reflect.Type, path string) reflect.Type {
+	_, t = sourcePathField(t, path)
+	return t
+}
+
+// sourcePathField returns the last struct field along a source path and
+// the type of the value the path reaches, or a nil type if the path does
+// not exist.
+func sourcePathField(t reflect.Type, path string) (reflect.StructField, reflect.Type) {
+	var field reflect.StructField
+	for _, segment := range strings.Split(path, ".") {
+		name, indices, ok := parsePathSegment(segment)
+		if !ok {
+			return reflect.StructField{}, nil
+		}
+		t = derefType(t)
+		if t.Kind() != reflect.Struct {
+			return reflect.StructField{}, nil
+		}
+		field, ok = t.FieldByName(name)
+		if !ok || !field.IsExported() {
+			return reflect.StructField{}, nil
+		}
+		t = field.Type
+		for range indices {
+			t = derefType(t)
+			if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+				return reflect.StructField{}, nil
+			}
+			t = t.Elem()
+		}
+	}
+	return field, t
+}
+
+// parsePathSegment splits a path segment such as "Addresses[0]" into the
+// field name and element indices.
+func parsePathSegment(segment string) (string, []int, bool) {
+	name, rest, _ := strings.Cut(segment, "[")
+	if name == "" {
+		return "", nil, false
+	}
+	var indices []int
+	for rest != "" {
+		digits, after, found := strings.Cut(rest, "]")
+		i, err := strconv.Atoi(digits)
+		if !found || err != nil || i < 0 {
+			return "", nil, false
+		}
+		indices = append(indices, i)
+		if after == "" {
+			break
+		}
+		if after[0] != '[' {
+			return "", nil, false
+		}
+		rest = after[1:]
+	}
+	return name, indices, true
+}
+
+// projectSourceFields adds the source fields declared by a member to a
+// projection.
+func projectSourceFields(mm *MemberMap, prefix string, set map[string]bool) {
+	for _, field := range mm.srcFields {
+		set[prefix+field] = true
+	}
+}
+
+// Coalesce maps a member from the first of fields whose value is not the
+// zero value, leaving the member unchanged when all of them are zero.
+func Coalesce(fields ...string) MemberOption {
+	return mapFromFields("Coalesce", fields, func(vals ...any) (any, error) {
+		for _, v := range vals {
+			if v != nil && !reflect.ValueOf(v).IsZero() {
+				return v, nil
+			}
+		}
+		return nil, nil
+	})
+}
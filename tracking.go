@@ -0,0 +1,109 @@
+package automapper
+
+import "reflect"
+
+// MapToTracked maps src into an existing destination like MapTo and returns
+// the dotted paths of the destination members whose values actually changed
+// ("Name", "Address.City"), for dirty-field persistence and audit logs.
+// Members set to the value they already held are not reported.
+func MapToTracked[TDest any](m *Mapper, src any, dest *TDest) ([]string, error) {
+	destVal := reflect.ValueOf(dest).Elem()
+	if err := m.checkNilSource(src, destVal.Type()); err != nil {
+		return nil, err
+	}
+	before := deepCopyValue(destVal)
+
+	if err := m.mapValue(nil, reflect.ValueOf(src), destVal); err != nil {
+		return nil, err
+	}
+	return changedPaths(before, destVal, goFieldName), nil
+}
+
+// goFieldName names a member by its Go field name.
+func goFieldName(field reflect.StructField) string {
+	return field.Name
+}
+
+// deepCopyValue returns a copy of v that shares no pointers, slices or maps
+// reachable through exported fields with the original, so later in-place
+// mapping cannot alter it. Values reached several times, as in graphs with
+// back-references, are copied once, which also keeps cycles finite.
+func deepCopyValue(v reflect.Value) reflect.Value {
+	return deepCopyVisited(v, make(map[copyKey]reflect.Value))
+}
+
+// copyKey identifies a pointer, slice or map already copied by
+// deepCopyValue.
+type copyKey struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+// deepCopyVisited copies v, reusing the copies recorded in copies.
+func deepCopyVisited(v reflect.Value, copies map[copyKey]reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			break
+		}
+		key := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if cp, ok := copies[key]; ok {
+			out.Set(cp)
+			break
+		}
+		cp := reflect.New(v.Type().Elem())
+		copies[key] = cp
+		cp.Elem().Set(deepCopyVisited(v.Elem(), copies))
+		out.Set(cp)
+	case reflect.Interface:
+		if !v.IsNil() {
+			out.Set(deepCopyVisited(v.Elem(), copies))
+		}
+	case reflect.Struct:
+		// Unexported fields are copied by value along with the struct
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				out.Field(i).Set(deepCopyVisited(v.Field(i), copies))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		key := copyKey{ptr: v.Pointer(), len: v.Len(), typ: v.Type()}
+		if cp, ok := copies[key]; ok {
+			out.Set(cp)
+			break
+		}
+		out.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		copies[key] = out
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopyVisited(v.Index(i), copies))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopyVisited(v.Index(i), copies))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			break
+		}
+		key := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if cp, ok := copies[key]; ok {
+			out.Set(cp)
+			break
+		}
+		out.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		copies[key] = out
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), deepCopyVisited(iter.Value(), copies))
+		}
+	default:
+		out.Set(v)
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package automapper
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// valueTransform rewrites a member's source value before it is assigned to
+// a destination of type destType.
+type valueTransform func(v reflect.Value, destType reflect.Type) (reflect.Value, error)
+
+// withTransform returns a MemberOption appending t to a member's value
+// transforms, which run in the order they were configured. The list is
+// copied so earlier versions of a copy-on-write type map keep their own.
+func withTransform(t valueTransform) MemberOption {
+	return func(mm *MemberMap) {
+		mm.transforms = append(mm.transforms[:len(mm.transforms):len(mm.transforms)], t)
+	}
+}
+
+// Scale multiplies a numeric member by factor, for unit conversions such as
+// bytes to megabytes (Scale(1.0/(1<<20))) or dollars to cents (Scale(100)).
+// Results mapped to integer members are rounded to the nearest integer.
+func Scale(factor float64) MemberOption {
+	return withTransform(func(v reflect.Value, destType reflect.Type) (reflect.Value, error) {
+		v = derefValue(v)
+		if !v.IsValid() {
+			return v, nil
+		}
+
+		var f float64
+		switch weakKind(v.Kind()) {
+		case reflect.Int:
+			f = float64(v.Int())
+		case reflect.Uint:
+			f = float64(v.Uint())
+		case reflect.Float64:
+			f = v.Float()
+		default:
+			return v, fmt.Errorf("cannot scale non-numeric value of type %v", v.Type())
+		}
+		// Dividing by a whole reciprocal avoids errors like 1999*0.01 = 19.990000000000002
+		if inverse := 1 / factor; inverse > 1 && inverse == math.Trunc(inverse) {
+			f /= inverse
+		} else {
+			f *= factor
+		}
+
+		switch weakKind(derefType(destType).Kind()) {
+		case reflect.Int:
+			return reflect.ValueOf(int64(math.Round(f))), nil
+		case reflect.Uint:
+			return reflect.ValueOf(uint64(math.Round(f))), nil
+		}
+		return reflect.ValueOf(f), nil
+	})
+}
+
+// applyTransforms runs a member's value transforms in order.
+func applyTransforms(mm *MemberMap, v reflect.Value, destType reflect.Type) (reflect.Value, error) {
+	for _, t := range mm.transforms {
+		var err error
+		if v, err = t(v, destType); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+// stringTransform returns a MemberOption applying fn to string member
+// values. Values of other kinds are left unchanged.
+func stringTransform(fn func(string) string) MemberOption {
+	return withTransform(func(v reflect.Value, _ reflect.Type) (reflect.Value, error) {
+		s := derefValue(v)
+		if !s.IsValid() || s.Kind() != reflect.String {
+			return v, nil
+		}
+		return reflect.ValueOf(fn(s.String())).Convert(s.Type()), nil
+	})
+}
+
+// Trim removes leading and trailing white space from a string member.
+func Trim() MemberOption {
+	return stringTransform(strings.TrimSpace)
+}
+
+// Lower converts a string member to lower case.
+func Lower() MemberOption {
+	return stringTransform(strings.ToLower)
+}
+
+// Upper converts a string member to upper case.
+func Upper() MemberOption {
+	return stringTransform(strings.ToUpper)
+}
+
+// Truncate shortens a string member to at most n characters (runes). A
+// negative n is treated as zero.
+func Truncate(n int) MemberOption {
+	n = max(n, 0)
+	return stringTransform(func(s string) string {
+		if utf8.RuneCountInString(s) <= n {
+			return s
+		}
+		return string([]rune(s)[:n])
+	})
+}
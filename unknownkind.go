@@ -0,0 +1,106 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// UnknownKindPolicy controls members of the kinds rarely found in data
+// models: complex64, complex128, uintptr and unsafe.Pointer.
+type UnknownKindPolicy int
+
+const (
+	// UnknownKindConvert copies such members between identical types and
+	// converts them otherwise (the default): between complex kinds, between
+	// uintptr and other integer kinds, and to strings, which hold the
+	// formatted number. Other combinations fail with CodeIncompatibleTypes.
+	UnknownKindConvert UnknownKindPolicy = iota
+	// UnknownKindSkip leaves such members unmapped.
+	UnknownKindSkip
+	// UnknownKindError fails the mapping when such a member would be mapped
+	// from a source field. Members configured with Ignore or MapFromFunc
+	// are unaffected.
+	UnknownKindError
+)
+
+// WithUnknownKindPolicy sets how type maps treat members whose source or
+// destination is a complex number, uintptr or unsafe.Pointer.
+func WithUnknownKindPolicy(policy UnknownKindPolicy) ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.unknownKind = policy
+	}
+}
+
+// isUnknownKind reports whether t is of a kind governed by UnknownKindPolicy.
+func isUnknownKind(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Complex64, reflect.Complex128, reflect.Uintptr, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// applyUnknownKindPolicy configures the members of a new type map whose
+// source or destination field is of an unknown kind.
+func (c *MapperConfiguration) applyUnknownKindPolicy(tm *TypeMap) {
+	for _, mm := range tm.memberMaps {
+		if mm.ignore || mm.transformsValue() {
+			continue
+		}
+		destType := tm.destType.FieldByIndex(mm.destFieldIdx).Type
+		srcType := fieldTypeByIndex(tm.srcType, mm.srcFieldIdx)
+		if srcType == nil || (!isUnknownKind(derefType(srcType)) && !isUnknownKind(destType)) {
+			continue
+		}
+
+		switch c.unknownKind {
+		case UnknownKindSkip:
+			mm.ignore = true
+		case UnknownKindError:
+			field := mm.destField
+			mm.resolver = func(any, any) (any, error) {
+				return nil, &MappingError{
+					Message:   fmt.Sprintf("cannot map %v member to %v", srcType, destType),
+					Code:      CodeUnsupported,
+					SrcType:   tm.srcType,
+					DestType:  tm.destType,
+					FieldName: field,
+				}
+			}
+		default:
+			// Other kinds into an unknown kind keep the engine's conversions
+			if srcType != destType && isUnknownKind(derefType(srcType)) {
+				mm.converter = c.convertUnknownKind
+			}
+		}
+	}
+}
+
+// convertUnknownKind converts a complex, uintptr or unsafe.Pointer value,
+// or a pointer to one, under UnknownKindConvert. A nil pointer is returned
+// as is, leaving the destination unchanged.
+func (c *MapperConfiguration) convertUnknownKind(src any, destType reflect.Type) (any, error) {
+	v := derefValue(reflect.ValueOf(src))
+	if !v.IsValid() {
+		return src, nil
+	}
+	if destType.Kind() == reflect.String {
+		switch v.Kind() {
+		case reflect.Complex64:
+			return reflect.ValueOf(strconv.FormatComplex(v.Complex(), 'g', -1, 64)).Convert(destType).Interface(), nil
+		case reflect.Complex128:
+			return reflect.ValueOf(strconv.FormatComplex(v.Complex(), 'g', -1, 128)).Convert(destType).Interface(), nil
+		case reflect.Uintptr:
+			return reflect.ValueOf(strconv.FormatUint(v.Uint(), 10)).Convert(destType).Interface(), nil
+		}
+	} else if v.Kind() != reflect.String && v.Kind() != reflect.UnsafePointer && c.convertible(v.Type(), destType) {
+		return v.Convert(destType).Interface(), nil
+	}
+	return nil, &MappingError{
+		Message:  fmt.Sprintf("cannot convert %v to %v", v.Type(), destType),
+		Code:     CodeIncompatibleTypes,
+		SrcType:  v.Type(),
+		DestType: destType,
+	}
+}
@@ -0,0 +1,81 @@
+package automapper
+
+import (
+	"reflect"
+	"strings"
+)
+
+// WithErrorOnUnsettableDest makes mapping fail instead of silently skipping
+// destination fields that cannot be set, such as unexported fields whose
+// name matches a source field. Without it that source data is dropped,
+// which is easy to miss in persistence mappings. Fields matched by
+// IgnoreFieldsMatching are not reported.
+func WithErrorOnUnsettableDest() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.unsettable = true
+	}
+}
+
+// markUnsettable records the unexported destination fields of tm that
+// would receive an exported source field of the same name if they were
+// exported. Unexported fields on both sides, such as locks, hold no data to
+// drop.
+func (c *MapperConfiguration) markUnsettable(tm *TypeMap) {
+	tm.unsettable = nil
+	if tm.srcType.Kind() != reflect.Struct || tm.destType.Kind() != reflect.Struct {
+		return
+	}
+
+	srcNames := make(map[string]bool)
+	for i := 0; i < tm.srcType.NumField(); i++ {
+		if field := tm.srcType.Field(i); field.IsExported() {
+			srcNames[strings.ToLower(field.Name)] = true
+		}
+	}
+	for _, field := range c.typeCache.getTypeInfo(tm.srcType).fields {
+		srcNames[strings.ToLower(field.name)] = true
+	}
+
+	for i := 0; i < tm.destType.NumField(); i++ {
+		field := tm.destType.Field(i)
+		if field.IsExported() || field.Anonymous || !srcNames[strings.ToLower(field.Name)] {
+			continue
+		}
+		if c.ignoresField(field) {
+			continue
+		}
+		tm.unsettable = append(tm.unsettable, field.Name)
+	}
+}
+
+// ignoresField reports whether a global IgnoreFieldsMatching predicate
+// matches field.
+func (c *MapperConfiguration) ignoresField(field reflect.StructField) bool {
+	for _, match := range c.ignoreMatch {
+		if match(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUnsettable fails the mapping of a type map with unsettable
+// destination fields when WithErrorOnUnsettableDest is set.
+func (m *Mapper) checkUnsettable(tm *TypeMap) error {
+	if !m.config.unsettable || len(tm.unsettable) == 0 {
+		return nil
+	}
+	return unsettableError(tm.srcType, tm.destType, tm.unsettable[0])
+}
+
+// unsettableError reports a destination field that cannot be set.
+func unsettableError(srcType, destType reflect.Type, field string) error {
+	return &MappingError{
+		Message:   "destination field cannot be set; its source data would be dropped",
+		Code:      CodeUnsettable,
+		SrcType:   srcType,
+		DestType:  destType,
+		FieldName: field,
+		Path:      field,
+	}
+}
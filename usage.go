@@ -0,0 +1,110 @@
+package automapper
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// WithUsageStats counts how often each destination member is mapped, so
+// configuration that is never exercised, such as resolvers that never run,
+// can be found with UsageStats and UnusedConfiguration and removed.
+// Counting keeps mappings off the optimized paths; enable it in tests,
+// staging or sampled instances rather than hot production paths.
+func WithUsageStats() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.usage = &sync.Map{}
+	}
+}
+
+// MemberUsage reports how often a destination member was mapped and which
+// configured mechanisms it used.
+type MemberUsage struct {
+	SrcType  reflect.Type
+	DestType reflect.Type
+	Member   string
+	// Resolver, Converter and Flattened report whether the member is mapped
+	// by a resolver or batch resolver, through a converter or element
+	// converter, or from a flattened source path
+	Resolver  bool
+	Converter bool
+	Flattened bool
+	// Count is the number of times the member was mapped
+	Count uint64
+}
+
+// usageKey identifies a destination member of a type map.
+type usageKey struct {
+	typeMapKey
+	member string
+}
+
+// UsageStats returns the usage of every member of every registered type
+// map, ordered by type pair, since WithUsageStats was set or
+// ResetUsageStats was last called. It returns nil without WithUsageStats.
+func (m *Mapper) UsageStats() []MemberUsage {
+	if m.config.usage == nil {
+		return nil
+	}
+
+	var stats []MemberUsage
+	for _, tm := range m.sortedTypeMaps() {
+		key := typeMapKey{srcType: tm.srcType, destType: tm.destType}
+		for _, mm := range tm.memberMaps {
+			if mm.ignore {
+				continue
+			}
+			usage := MemberUsage{
+				SrcType:   tm.srcType,
+				DestType:  tm.destType,
+				Member:    mm.destField,
+				Resolver:  mm.resolver != nil || mm.batch != nil,
+				Converter: mm.converter != nil || mm.elemConverter != nil,
+				Flattened: mm.useFlattening,
+			}
+			if count, ok := m.config.usage.Load(usageKey{typeMapKey: key, member: mm.destField}); ok {
+				usage.Count = count.(*atomic.Uint64).Load()
+			}
+			stats = append(stats, usage)
+		}
+	}
+	return stats
+}
+
+// UnusedConfiguration returns the members with a resolver, converter or
+// flattened source path that have not been mapped, according to
+// UsageStats.
+func (m *Mapper) UnusedConfiguration() []MemberUsage {
+	var unused []MemberUsage
+	for _, usage := range m.UsageStats() {
+		if usage.Count == 0 && (usage.Resolver || usage.Converter || usage.Flattened) {
+			unused = append(unused, usage)
+		}
+	}
+	return unused
+}
+
+// ResetUsageStats clears the counts collected under WithUsageStats.
+func (m *Mapper) ResetUsageStats() {
+	if m.config.usage == nil {
+		return
+	}
+	m.config.usage.Range(func(key, _ any) bool {
+		m.config.usage.Delete(key)
+		return true
+	})
+}
+
+// countUsage counts a mapping of mm between the struct types srcType and
+// destType under WithUsageStats.
+func (m *Mapper) countUsage(srcType, destType reflect.Type, mm *MemberMap) {
+	if m.config.usage == nil {
+		return
+	}
+	key := usageKey{typeMapKey: typeMapKey{srcType: srcType, destType: destType}, member: mm.destField}
+	count, ok := m.config.usage.Load(key)
+	if !ok {
+		count, _ = m.config.usage.LoadOrStore(key, new(atomic.Uint64))
+	}
+	count.(*atomic.Uint64).Add(1)
+}
@@ -0,0 +1,236 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ValidationSeverity classifies a ValidationIssue.
+type ValidationSeverity int
+
+const (
+	// SeverityWarning marks configuration that works but is likely unintended.
+	SeverityWarning ValidationSeverity = iota
+	// SeverityError marks configuration that will lose or corrupt data.
+	SeverityError
+)
+
+// String returns the severity name.
+func (s ValidationSeverity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// ValidationIssue describes a configuration problem found by ValidateConfiguration.
+type ValidationIssue struct {
+	Severity ValidationSeverity
+	SrcType  reflect.Type
+	DestType reflect.Type
+	Member   string
+	Message  string
+}
+
+func (i ValidationIssue) String() string {
+	if i.Member != "" {
+		return fmt.Sprintf("%s: %v -> %v: member '%s': %s", i.Severity, i.SrcType, i.DestType, i.Member, i.Message)
+	}
+	return fmt.Sprintf("%s: %v -> %v: %s", i.Severity, i.SrcType, i.DestType, i.Message)
+}
+
+// ValidateConfiguration inspects every registered type map and reports
+// configuration problems. Issues are ordered by type pair. Type maps
+// auto-created by earlier mappings are not registered and are skipped, so
+// the result does not depend on what has been mapped.
+func (m *Mapper) ValidateConfiguration() []ValidationIssue {
+	var issues []ValidationIssue
+	for _, tm := range m.sortedTypeMaps() {
+		if !tm.autoCreated {
+			issues = append(issues, m.validateTypeMap(tm)...)
+		}
+	}
+
+	m.config.mu.RLock()
+	issues = append(issues, m.config.violations...)
+	m.config.mu.RUnlock()
+
+	return issues
+}
+
+// sortedTypeMaps returns the registered type maps in a deterministic order.
+func (m *Mapper) sortedTypeMaps() []*TypeMap {
+	m.config.mu.RLock()
+	maps := make([]*TypeMap, 0, len(m.config.typeMaps))
+	for _, tm := range m.config.typeMaps {
+		maps = append(maps, tm)
+	}
+	m.config.mu.RUnlock()
+
+	sort.Slice(maps, func(i, j int) bool {
+		if a, b := maps[i].srcType.String(), maps[j].srcType.String(); a != b {
+			return a < b
+		}
+		return maps[i].destType.String() < maps[j].destType.String()
+	})
+	return maps
+}
+
+// validateTypeMap reports the issues of a single type map.
+func (m *Mapper) validateTypeMap(tm *TypeMap) []ValidationIssue {
+	var issues []ValidationIssue
+
+	issue := func(severity ValidationSeverity, member, format string, args ...any) {
+		issues = append(issues, ValidationIssue{
+			Severity: severity,
+			SrcType:  tm.srcType,
+			DestType: tm.destType,
+			Member:   member,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if tm.compileErr != nil {
+		issue(SeverityError, "", "%v", tm.compileErr)
+	}
+
+	for _, name := range m.config.typeCache.getTypeInfo(tm.srcType).ambiguous {
+		issue(SeverityWarning, name,
+			"source field is promoted from several embedded structs at the same depth and is not mapped")
+	}
+	for _, name := range m.config.typeCache.getTypeInfo(tm.destType).ambiguous {
+		issue(SeverityWarning, name,
+			"destination field is promoted from several embedded structs at the same depth and is not mapped")
+	}
+
+	unsettable := SeverityWarning
+	if m.config.unsettable {
+		unsettable = SeverityError
+	}
+	for _, name := range tm.unsettable {
+		issue(unsettable, name,
+			"destination field is unexported and cannot be set; the source field of the same name is dropped")
+	}
+
+	m.config.mu.RLock()
+	opt := m.config.optimizedMaps[typeMapKey{srcType: tm.srcType, destType: tm.destType}]
+	m.config.mu.RUnlock()
+	if opt != nil {
+		for _, fallback := range opt.fallbacks {
+			issue(SeverityWarning, fallback.member,
+				"mapped without unsafe optimizations: %s", fallback.reason)
+		}
+	}
+
+	position := make(map[string]int, len(tm.memberMaps))
+	for i, mm := range tm.memberMaps {
+		position[mm.destField] = i
+	}
+	for i, mm := range tm.memberMaps {
+		for _, dep := range mm.after {
+			if pos, ok := position[dep]; !ok {
+				issue(SeverityError, mm.destField, "depends on member '%s', which is not mapped", dep)
+			} else if pos > i {
+				issue(SeverityError, mm.destField, "is in a dependency cycle with member '%s'", dep)
+			}
+		}
+	}
+
+	for _, mm := range tm.memberMaps {
+		for _, shadowed := range mm.shadowedSources() {
+			issue(SeverityWarning, mm.destField, "%s", shadowed)
+		}
+	}
+
+	for _, mm := range tm.memberMaps {
+		if mm.condSrc != nil && mm.condSrc != tm.srcType &&
+			(mm.condition == nil || mm.condSrc != reflect.PointerTo(tm.srcType)) {
+			issue(SeverityError, mm.destField,
+				"condition expects source type %v, but the map source is %v; the member is never mapped", mm.condSrc, tm.srcType)
+		}
+	}
+
+	for _, mm := range tm.memberMaps {
+		destFieldType := fieldTypeByIndex(tm.destType, mm.destFieldIdx)
+		for _, elemType := range mm.collElems {
+			if destFieldType != nil && (destFieldType.Kind() != reflect.Slice || destFieldType.Elem() != elemType) {
+				issue(SeverityError, mm.destField,
+					"collection option expects a slice of %v, but the member is %v; it has no effect", elemType, destFieldType)
+			}
+		}
+	}
+
+	for _, mm := range tm.memberMaps {
+		for _, field := range mm.srcFields {
+			if sourceFieldType(tm.srcType, field) == nil {
+				issue(SeverityError, mm.destField, "source field '%s' does not exist", field)
+			}
+		}
+	}
+
+	for _, mm := range tm.memberMaps {
+		if mm.ignore || mm.resolver != nil || mm.batch != nil || mm.converter != nil {
+			continue
+		}
+		srcFieldType := m.memberSourceType(tm, mm)
+		if srcFieldType == nil {
+			continue
+		}
+		destFieldType := tm.destType.FieldByIndex(mm.destFieldIdx).Type
+		if pair, missing := m.missingNestedMap(srcFieldType, destFieldType); missing {
+			issue(SeverityError, mm.destField,
+				"no type map registered for nested pair %v -> %v; it would be auto-created", pair.srcType, pair.destType)
+		}
+	}
+
+	return issues
+}
+
+// memberSourceType returns the type of the source field feeding a member,
+// or nil if the member has no source field.
+func (m *Mapper) memberSourceType(tm *TypeMap, mm *MemberMap) reflect.Type {
+	if len(mm.srcFieldIdx) > 0 {
+		return fieldTypeByIndex(tm.srcType, mm.srcFieldIdx)
+	}
+	if fi, ok := m.config.typeCache.getTypeInfo(tm.srcType).member(mm.srcField); ok {
+		return fi.fieldType
+	}
+	return nil
+}
+
+// missingNestedMap unwraps pointers and collections of a member's source and
+// destination types and reports the struct pair if mapping it would rely on
+// an auto-created type map, whether or not one was already auto-created.
+func (m *Mapper) missingNestedMap(srcType, destType reflect.Type) (typeMapKey, bool) {
+	srcType = elementType(srcType)
+	destType = elementType(destType)
+	key := typeMapKey{srcType: srcType, destType: destType}
+
+	if srcType.Kind() != reflect.Struct || destType.Kind() != reflect.Struct || srcType == destType {
+		return key, false
+	}
+
+	m.config.mu.RLock()
+	defer m.config.mu.RUnlock()
+	if tm, ok := m.config.typeMaps[key]; ok && !tm.autoCreated {
+		return key, false
+	}
+	if _, ok := m.config.converters[key]; ok {
+		return key, false
+	}
+	return key, true
+}
+
+// elementType strips pointers, slices, arrays and maps down to the
+// innermost element type.
+func elementType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithVerifyOptimizations is a debug mode that maps every struct taking an
+// optimized path a second time through the standard path and compares the
+// results. On divergence the standard result is kept and the type pair is
+// reported once by ValidateConfiguration as a SeverityError issue, so
+// unsafe and specialized mappers can be trusted before rolling them out.
+// Resolvers and hooks of verified type maps run twice, and Map skips its
+// allocation-free fast path. Resolvers that return a different value on
+// each call, such as clocks or generated IDs, are reported as divergences
+// and their second value is kept.
+func WithVerifyOptimizations() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.verifyOpt = true
+	}
+}
+
+// mapStructVerified maps through the optimized path and checks the result
+// against the standard path.
+func (m *Mapper) mapStructVerified(ctx *MappingContext, srcVal, destVal reflect.Value, opt *TypeMapOptimized) error {
+	expected := deepCopyValue(destVal)
+	if err := m.mapStructOptimized(ctx, srcVal, destVal, opt); err != nil {
+		return err
+	}
+
+	// The reference run maps nested structs through the standard path too
+	if err := m.mapStructStandard(ctx.referenceContext(m), srcVal, expected, opt.TypeMap); err != nil {
+		m.config.recordDivergence(opt, "", fmt.Sprintf("standard path failed: %v", err))
+		return nil
+	}
+
+	if !reflect.DeepEqual(expected.Interface(), destVal.Interface()) {
+		member := divergentMember(expected, destVal)
+		m.config.recordDivergence(opt, member, "optimized result differs from the standard path, which was used")
+		destVal.Set(expected)
+	}
+	return nil
+}
+
+// referenceContext returns a fresh context for the reference run of
+// mapStructVerified. It shares the shared references and the member mask,
+// and reads the same call options, but does not notify observers, record
+// reports, reuse memoized results or queue finalizers and hook errors of
+// the call a second time.
+func (c *MappingContext) referenceContext(m *Mapper) *MappingContext {
+	ref := &MappingContext{mapper: m, verifying: true}
+	if c == nil {
+		return ref
+	}
+	ref.refs, ref.mask = c.refs, c.mask
+	ref.items, ref.rootKey, ref.ignored, ref.signature = c.items, c.rootKey, c.ignored, c.signature
+	ref.locale, ref.goCtx, ref.batch, ref.depth = c.locale, c.goCtx, c.batch, c.depth
+	return ref
+}
+
+// divergentMember returns the first exported member whose values differ.
+func divergentMember(expected, got reflect.Value) string {
+	for i := 0; i < expected.NumField(); i++ {
+		field := expected.Type().Field(i)
+		if field.IsExported() && !reflect.DeepEqual(expected.Field(i).Interface(), got.Field(i).Interface()) {
+			return field.Name
+		}
+	}
+	return ""
+}
+
+// recordDivergence reports the first divergence of an optimized type map.
+func (c *MapperConfiguration) recordDivergence(opt *TypeMapOptimized, member, message string) {
+	if opt.diverged.Swap(true) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.violations = append(c.violations, ValidationIssue{
+		Severity: SeverityError,
+		SrcType:  opt.srcType,
+		DestType: opt.destType,
+		Member:   member,
+		Message:  message,
+	})
+}
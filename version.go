@@ -0,0 +1,74 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// versionKey identifies the destination registered for a source type under
+// a version tag.
+type versionKey struct {
+	srcType reflect.Type
+	version string
+}
+
+// CreateMapVersion creates a mapping from TSrc to TDest like CreateMap and
+// registers TDest as the destination for TSrc under version, so several API
+// versions of a DTO can coexist and be selected with MapVersion.
+func CreateMapVersion[TSrc, TDest any](m *Mapper, version string) *TypeMapBuilder[TSrc, TDest] {
+	b := CreateMap[TSrc, TDest](m)
+	if b.err != nil {
+		return b
+	}
+
+	m.config.mu.Lock()
+	m.config.versions[versionKey{srcType: b.typeMap.srcType, version: version}] = b.typeMap.destType
+	m.config.mu.Unlock()
+	return b
+}
+
+// MapVersion maps src to the destination type registered for its type under
+// version. TDest is typically an interface, or any, satisfied by every
+// version:
+//
+//	dto, err := automapper.MapVersion[any](mapper, r.Header.Get("API-Version"), user)
+func MapVersion[TDest any](m *Mapper, version string, src any) (TDest, error) {
+	var dest TDest
+	if err := m.checkNilSource(src, reflect.TypeOf((*TDest)(nil)).Elem()); err != nil {
+		return dest, err
+	}
+
+	srcVal := reflect.ValueOf(src)
+	srcType := reflect.TypeOf(src)
+	for srcType != nil && srcType.Kind() == reflect.Ptr {
+		srcType = srcType.Elem()
+	}
+
+	m.config.mu.RLock()
+	destType, ok := m.config.versions[versionKey{srcType: srcType, version: version}]
+	m.config.mu.RUnlock()
+	if !ok {
+		return dest, &MappingError{
+			Message: fmt.Sprintf("no mapping registered for version %q", version),
+			Code:    CodeNoTypeMap,
+			SrcType: srcType,
+		}
+	}
+
+	resultType := reflect.TypeOf((*TDest)(nil)).Elem()
+	if !destType.AssignableTo(resultType) {
+		return dest, &MappingError{
+			Message:  fmt.Sprintf("version %q destination is not assignable to %v", version, resultType),
+			Code:     CodeIncompatibleTypes,
+			SrcType:  srcType,
+			DestType: destType,
+		}
+	}
+
+	result := reflect.New(destType).Elem()
+	if err := m.mapValue(nil, srcVal, result); err != nil {
+		return dest, err
+	}
+	reflect.ValueOf(&dest).Elem().Set(result)
+	return dest, nil
+}
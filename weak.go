@@ -0,0 +1,142 @@
+package automapper
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// WithWeakTypeConversion enables mapstructure-style weak typing for every
+// type map: strings, numbers and bools convert into each other, parsing
+// "42" into 42 or mapping 1 to true. Use the builder method of the same name
+// to enable it for a single type map.
+func WithWeakTypeConversion() ConfigOption {
+	return func(c *MapperConfiguration) {
+		c.weakTypes = true
+	}
+}
+
+// WithWeakTypeConversion enables weak typing, as for the config option of
+// the same name, for members of this type map.
+func (b *TypeMapBuilder[TSrc, TDest]) WithWeakTypeConversion() *TypeMapBuilder[TSrc, TDest] {
+	b.update(func(tm *TypeMap) {
+		tm.weakTypes = true
+	})
+	return b
+}
+
+// weakKind groups kinds into the categories weak typing converts between.
+func weakKind(k reflect.Kind) reflect.Kind {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return reflect.Uint
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	case reflect.String, reflect.Bool:
+		return k
+	}
+	return reflect.Invalid
+}
+
+// weakConvertible reports whether weak typing converts src to dest: a
+// string, bool or number into a value of another of those categories.
+// Conversions between numbers are left to ordinary conversion.
+func weakConvertible(src, dest reflect.Type) bool {
+	s, d := weakKind(src.Kind()), weakKind(dest.Kind())
+	if s == reflect.Invalid || d == reflect.Invalid || s == d {
+		return false
+	}
+	return s == reflect.String || d == reflect.String || s == reflect.Bool || d == reflect.Bool
+}
+
+// weakAssign converts src into dest under weak typing rules. Empty strings
+// convert to zero values.
+func weakAssign(src, dest reflect.Value) error {
+	switch weakKind(dest.Kind()) {
+	case reflect.String:
+		dest.SetString(weakString(src))
+		return nil
+	case reflect.Bool:
+		switch weakKind(src.Kind()) {
+		case reflect.String:
+			if src.String() == "" {
+				dest.SetBool(false)
+				return nil
+			}
+			b, err := strconv.ParseBool(src.String())
+			if err != nil {
+				return weakError(src, dest, err)
+			}
+			dest.SetBool(b)
+		default:
+			dest.SetBool(!src.IsZero())
+		}
+		return nil
+	}
+
+	// Numeric destinations
+	if src.Kind() == reflect.Bool {
+		n := 0
+		if src.Bool() {
+			n = 1
+		}
+		src = reflect.ValueOf(n)
+		dest.Set(src.Convert(dest.Type()))
+		return nil
+	}
+
+	s := src.String()
+	if s == "" {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	}
+	var err error
+	switch weakKind(dest.Kind()) {
+	case reflect.Int:
+		var n int64
+		if n, err = strconv.ParseInt(s, 0, dest.Type().Bits()); err == nil {
+			dest.SetInt(n)
+		}
+	case reflect.Uint:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 0, dest.Type().Bits()); err == nil {
+			dest.SetUint(n)
+		}
+	case reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, dest.Type().Bits()); err == nil {
+			dest.SetFloat(f)
+		}
+	}
+	if err != nil {
+		return weakError(src, dest, err)
+	}
+	return nil
+}
+
+// weakString formats a bool or number as a string.
+func weakString(v reflect.Value) string {
+	switch weakKind(v.Kind()) {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	}
+	return v.String()
+}
+
+// weakError reports a failed weak conversion.
+func weakError(src, dest reflect.Value, err error) error {
+	return &MappingError{
+		Message:    "weak type conversion failed",
+		Code:       CodeConversion,
+		SrcType:    src.Type(),
+		DestType:   dest.Type(),
+		InnerError: err,
+	}
+}
@@ -0,0 +1,48 @@
+package automapper
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// CreateWrapperMap configures the mapping between two instantiations of
+// generic envelope types, such as Envelope[User] to Envelope[UserDTO],
+// together with the mapping of their payloads, TSrc to TDest, unless that
+// is already registered. Every instantiation is a distinct type, so each
+// pair of envelopes has its own type map. It panics if the destination
+// envelope has no member holding TDest, directly or as a pointer, slice,
+// array or map value, since such a pair is not an envelope of TDest; like a
+// type error, this depends only on the type arguments.
+//
+//	automapper.CreateWrapperMap[Envelope[User], Envelope[UserDTO], User, UserDTO](mapper)
+func CreateWrapperMap[TWrapSrc, TWrapDest, TSrc, TDest any](m *Mapper) *TypeMapBuilder[TWrapSrc, TWrapDest] {
+	wrapDest := derefType(reflect.TypeOf((*TWrapDest)(nil)).Elem())
+	payload := derefType(reflect.TypeOf((*TDest)(nil)).Elem())
+	if !holdsPayload(wrapDest, payload) {
+		panic(&MappingError{
+			Message:  fmt.Sprintf("CreateWrapperMap requires a destination member of type %v", payload),
+			Code:     CodeIncompatibleTypes,
+			SrcType:  derefType(reflect.TypeOf((*TWrapSrc)(nil)).Elem()),
+			DestType: wrapDest,
+		})
+	}
+
+	if _, ok := GetMap[TSrc, TDest](m); !ok {
+		CreateMap[TSrc, TDest](m)
+	}
+	return CreateMap[TWrapSrc, TWrapDest](m)
+}
+
+// holdsPayload reports whether a member of struct type t holds payload,
+// directly or as the element of a pointer or collection.
+func holdsPayload(t, payload reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if elementType(t.Field(i).Type) == payload {
+			return true
+		}
+	}
+	return false
+}